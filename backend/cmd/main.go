@@ -1,11 +1,15 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	"github.com/financial-tracker/backend/config"
+	"github.com/financial-tracker/backend/internal/alerts"
+	"github.com/financial-tracker/backend/internal/goldprice"
 	"github.com/financial-tracker/backend/internal/handlers"
 	"github.com/financial-tracker/backend/internal/middleware"
 	"github.com/financial-tracker/backend/internal/repository"
@@ -33,15 +37,51 @@ func main() {
 	transactionRepo := repository.NewTransactionRepository(db)
 	budgetRepo := repository.NewBudgetRepository(db)
 	creditCardRepo := repository.NewCreditCardRepository(db)
+	paylaterRepo := repository.NewPaylaterRepository(db)
 	goldRepo := repository.NewGoldRepository(db)
+	notificationRepo := repository.NewNotificationRepository(db)
+	apiConfigRepo := repository.NewAPIConfigRepository(db)
+	zakatRepo := repository.NewZakatRepository(db)
+
+	// Initialize alerting
+	budgetAlerter := alerts.NewBudgetAlerter(budgetRepo, notificationRepo)
+	goldAlerter := alerts.NewGoldPriceAlerter(goldRepo, notificationRepo)
+	goldRepo.OnPriceSet(goldAlerter.HandlePrice)
+	go goldAlerter.Run(context.Background())
+
+	// Initialize gold price fetching (providers come from api_configurations).
+	// Manual fetches from the admin API try each provider once so the
+	// request does not wait out the scheduler's retries.
+	goldProviders := goldprice.ConfiguredProviders(apiConfigRepo.GetAll)
+	goldFetcher := goldprice.NewFetcher(goldProviders, goldRepo)
+	manualGoldFetcher := goldprice.NewFetcher(goldProviders, goldRepo)
+	manualGoldFetcher.Attempts = 1
+	manualGoldFetcher.Backoff = 0
+	goldPriceCron := os.Getenv("GOLD_PRICE_CRON")
+	if goldPriceCron == "" {
+		goldPriceCron = "0 9 * * *"
+	}
+	if goldPriceCron != "off" {
+		schedule, err := goldprice.ParseSchedule(goldPriceCron)
+		if err != nil {
+			log.Fatal("Invalid GOLD_PRICE_CRON:", err)
+		}
+		go goldFetcher.Run(context.Background(), schedule)
+	}
 
 	// Initialize handlers
 	authHandler := handlers.NewAuthHandler(userRepo)
 	accountHandler := handlers.NewAccountHandler(accountRepo)
-	transactionHandler := handlers.NewTransactionHandler(transactionRepo, accountRepo)
-	budgetHandler := handlers.NewBudgetHandler(budgetRepo)
-	creditCardHandler := handlers.NewCreditCardHandler(creditCardRepo)
-	goldHandler := handlers.NewGoldHandler(goldRepo)
+	transactionHandler := handlers.NewTransactionHandler(transactionRepo, accountRepo, budgetAlerter)
+	budgetHandler := handlers.NewBudgetHandler(budgetRepo, accountRepo)
+	creditCardHandler := handlers.NewCreditCardHandler(creditCardRepo, accountRepo, budgetAlerter)
+	paylaterHandler := handlers.NewPaylaterHandler(paylaterRepo, accountRepo, creditCardRepo, budgetAlerter)
+	goldHandler := handlers.NewGoldHandler(goldRepo, accountRepo, manualGoldFetcher)
+	notificationHandler := handlers.NewNotificationHandler(notificationRepo)
+	zakatHandler := handlers.NewZakatHandler(zakatRepo, goldRepo, accountRepo, budgetRepo, paylaterRepo, creditCardRepo, budgetAlerter)
+
+	// Book credit card installments as their statement periods open
+	go postDueInstallments(creditCardRepo, time.Hour)
 
 	// Setup Gin router
 	router := gin.Default()
@@ -73,11 +113,15 @@ func main() {
 			auth.POST("/register", authHandler.Register)
 			auth.POST("/login", authHandler.Login)
 			auth.GET("/me", middleware.AuthMiddleware(), authHandler.GetMe)
+			auth.PUT("/me/settings", middleware.AuthMiddleware(), authHandler.UpdateSettings)
 		}
 
 		// Public gold price endpoint
 		api.GET("/gold/price", goldHandler.GetLatestPrice)
 		api.GET("/gold/price/history", goldHandler.GetPriceHistory)
+		api.GET("/gold/price/ohlc", goldHandler.GetPriceOHLC)
+		api.GET("/gold/price/moving-averages", goldHandler.GetPriceMovingAverages)
+		api.GET("/gold/price/stats", goldHandler.GetPriceStats)
 
 		// Protected routes
 		protected := api.Group("/")
@@ -88,10 +132,20 @@ func main() {
 			{
 				accounts.POST("", accountHandler.Create)
 				accounts.GET("", accountHandler.GetAll)
+				accounts.GET("/balance-sheet", paylaterHandler.GetBalanceSheet)
 				accounts.GET("/:id", accountHandler.GetByID)
+				accounts.GET("/:id/paylater/purchases", paylaterHandler.GetPurchases)
+				accounts.POST("/:id/paylater/purchases", paylaterHandler.CreatePurchase)
 				accounts.DELETE("/:id", accountHandler.Delete)
 			}
 
+			// Paylater routes (installments due across all paylater accounts)
+			paylater := protected.Group("/paylater")
+			{
+				paylater.GET("/upcoming", paylaterHandler.GetUpcoming)
+				paylater.POST("/installments/:id/pay", paylaterHandler.PayInstallment)
+			}
+
 			// Transactions routes
 			transactions := protected.Group("/transactions")
 			{
@@ -99,25 +153,57 @@ func main() {
 				transactions.GET("", transactionHandler.GetAll)
 				transactions.GET("/summary", transactionHandler.GetSummary)
 				transactions.GET("/:id", transactionHandler.GetByID)
+				transactions.PUT("/:id", transactionHandler.Update)
 				transactions.DELETE("/:id", transactionHandler.Delete)
 			}
 
-			// Budgets routes (with month/year picker and copy feature)
+			// Budgets routes (weekly/monthly/yearly periods and copy feature)
 			budgets := protected.Group("/budgets")
 			{
 				budgets.POST("", budgetHandler.Create)
 				budgets.GET("", budgetHandler.GetAll)
 				budgets.POST("/copy", budgetHandler.CopyFromMonth)
+				budgets.PUT("/bulk", budgetHandler.BulkUpsert)
+				budgets.GET("/progress", budgetHandler.GetProgress)
+				budgets.GET("/zero-based", budgetHandler.GetZeroBased)
+				budgets.POST("/assign", budgetHandler.Assign)
+				budgets.GET("/suggestions", budgetHandler.GetSuggestions)
+				budgets.POST("/suggestions/apply", budgetHandler.ApplySuggestions)
 				budgets.GET("/:id", budgetHandler.GetByID)
+				budgets.PUT("/:id", budgetHandler.Update)
+				budgets.PUT("/:id/alerts", budgetHandler.UpdateAlerts)
 				budgets.DELETE("/:id", budgetHandler.Delete)
 			}
 
+			// Notifications routes
+			notifications := protected.Group("/notifications")
+			{
+				notifications.GET("", notificationHandler.GetAll)
+				notifications.PUT("/read-all", notificationHandler.MarkAllRead)
+				notifications.PUT("/:id/read", notificationHandler.MarkRead)
+			}
+
 			// Credit cards routes
 			creditCards := protected.Group("/credit-cards")
 			{
 				creditCards.POST("", creditCardHandler.Create)
 				creditCards.GET("", creditCardHandler.GetAll)
+				creditCards.GET("/analysis", creditCardHandler.GetAnalysis)
+				creditCards.GET("/rewards/best", creditCardHandler.GetBestCard)
 				creditCards.GET("/:id", creditCardHandler.GetByID)
+				creditCards.PUT("/:id", creditCardHandler.Update)
+				creditCards.GET("/:id/transactions", creditCardHandler.GetTransactions)
+				creditCards.POST("/:id/transactions", creditCardHandler.CreateTransaction)
+				creditCards.POST("/:id/payments", creditCardHandler.Pay)
+				creditCards.GET("/:id/statements", creditCardHandler.GetStatements)
+				creditCards.GET("/:id/installments", creditCardHandler.GetInstallments)
+				creditCards.POST("/:id/installments", creditCardHandler.CreateInstallment)
+				creditCards.GET("/:id/analysis", creditCardHandler.GetCardAnalysis)
+				creditCards.GET("/:id/rewards", creditCardHandler.GetRewards)
+				creditCards.GET("/:id/rewards/rules", creditCardHandler.GetRewardRules)
+				creditCards.POST("/:id/rewards/rules", creditCardHandler.CreateRewardRule)
+				creditCards.DELETE("/:id/rewards/rules/:ruleId", creditCardHandler.DeleteRewardRule)
+				creditCards.POST("/:id/rewards/redemptions", creditCardHandler.Redeem)
 				creditCards.DELETE("/:id", creditCardHandler.Delete)
 			}
 
@@ -127,10 +213,43 @@ func main() {
 				gold.POST("/assets", goldHandler.CreateAsset)
 				gold.GET("/assets", goldHandler.GetAllAssets)
 				gold.GET("/assets/:id", goldHandler.GetAssetByID)
+				gold.PUT("/assets/:id", goldHandler.UpdateAsset)
 				gold.DELETE("/assets/:id", goldHandler.DeleteAsset)
+				gold.GET("/assets/:id/pieces", goldHandler.GetPieces)
+				gold.POST("/assets/:id/pieces", goldHandler.AddPieces)
+				gold.PUT("/pieces/:id", goldHandler.UpdatePiece)
+				gold.DELETE("/pieces/:id", goldHandler.DeletePiece)
+				gold.GET("/inventory", goldHandler.GetInventory)
+				gold.POST("/sales", goldHandler.Sell)
+				gold.GET("/sales", goldHandler.GetSales)
+				gold.GET("/valuation", goldHandler.GetValuation)
+				gold.GET("/valuation/series", goldHandler.GetValuationSeries)
 				gold.GET("/summary", goldHandler.GetSummary)
-				// Admin: update today's price
-				gold.POST("/price", goldHandler.UpdateTodayPrice)
+				gold.GET("/holdings", goldHandler.GetHoldings)
+				gold.POST("/alerts", goldHandler.CreateAlert)
+				gold.GET("/alerts", goldHandler.GetAlerts)
+				gold.PUT("/alerts/:id", goldHandler.UpdateAlert)
+				gold.DELETE("/alerts/:id", goldHandler.DeleteAlert)
+			}
+
+			// Zakat routes (mal on wealth, penghasilan on income)
+			zakat := protected.Group("/zakat")
+			{
+				zakat.GET("/mal", zakatHandler.GetMal)
+				zakat.GET("/penghasilan", zakatHandler.GetPenghasilan)
+				zakat.POST("/payments", zakatHandler.CreatePayment)
+				zakat.GET("/payments", zakatHandler.GetPayments)
+			}
+
+			// Gold price management (admin only, every write is audited)
+			goldPrices := protected.Group("/gold/price")
+			goldPrices.Use(middleware.AdminMiddleware())
+			{
+				goldPrices.POST("", goldHandler.SetPrice)
+				goldPrices.DELETE("/:date/:gold_type", goldHandler.DeletePrice)
+				goldPrices.POST("/import", goldHandler.ImportPrices)
+				goldPrices.POST("/fetch", goldHandler.FetchPrices)
+				goldPrices.GET("/changes", goldHandler.GetPriceChanges)
 			}
 		}
 	}
@@ -146,17 +265,64 @@ func main() {
 	fmt.Println("   POST   /api/auth/register")
 	fmt.Println("   POST   /api/auth/login")
 	fmt.Println("   GET    /api/auth/me")
+	fmt.Println("   PUT    /api/auth/me/settings (month start day, budgeting mode)")
 	fmt.Println("   CRUD   /api/accounts (with sub-accounts)")
+	fmt.Println("   GET    /api/accounts/balance-sheet (assets, liabilities, net worth)")
+	fmt.Println("   POST   /api/accounts/:id/paylater/purchases (split into installments)")
+	fmt.Println("   GET    /api/paylater/upcoming?days= (installments due)")
+	fmt.Println("   POST   /api/paylater/installments/:id/pay")
 	fmt.Println("   CRUD   /api/transactions")
-	fmt.Println("   CRUD   /api/budgets (month/year based)")
+	fmt.Println("   CRUD   /api/budgets (weekly, monthly or yearly)")
 	fmt.Println("   POST   /api/budgets/copy (copy from previous month)")
+	fmt.Println("   PUT    /api/budgets/bulk (save a month's plan)")
+	fmt.Println("   GET    /api/budgets/progress (budget vs. actual, ?period=)")
+	fmt.Println("   GET    /api/budgets/suggestions (from past spending)")
+	fmt.Println("   GET    /api/budgets/zero-based (income to be assigned)")
+	fmt.Println("   POST   /api/budgets/assign (fund an envelope)")
+	fmt.Println("   GET    /api/notifications")
 	fmt.Println("   CRUD   /api/credit-cards")
+	fmt.Println("   POST   /api/credit-cards/:id/transactions (purchase, refund, fee)")
+	fmt.Println("   POST   /api/credit-cards/:id/payments (pay from an account)")
+	fmt.Println("   GET    /api/credit-cards/:id/statements (billing cycles)")
+	fmt.Println("   POST   /api/credit-cards/:id/installments (cicilan plans)")
+	fmt.Println("   GET    /api/credit-cards/analysis (utilization, interest, late fees)")
+	fmt.Println("   GET    /api/credit-cards/:id/rewards (earned, redeemed, balance)")
+	fmt.Println("   GET    /api/credit-cards/rewards/best?category= (best card to use)")
 	fmt.Println("   CRUD   /api/gold/assets")
-	fmt.Println("   GET    /api/gold/summary")
-	fmt.Println("   GET    /api/gold/price")
+	fmt.Println("   CRUD   /api/gold/assets/:id/pieces (bars and coins with certificate numbers)")
+	fmt.Println("   GET    /api/gold/inventory (every piece by storage location)")
+	fmt.Println("   GET    /api/gold/holdings (per brand, average cost, break-even)")
+	fmt.Println("   POST   /api/gold/sales (FIFO, specific lots or pieces)")
+	fmt.Println("   GET    /api/gold/summary (unrealized and realized P/L)")
+	fmt.Println("   GET    /api/gold/valuation?date= (position on a past day)")
+	fmt.Println("   GET    /api/gold/valuation/series?interval=daily|monthly")
+	fmt.Println("   CRUD   /api/gold/alerts (price level, daily move, position P/L)")
+	fmt.Println("   GET    /api/gold/price (antam, ?gold_type= or ?all=true)")
+	fmt.Println("   GET    /api/gold/price/ohlc?interval=weekly|monthly")
+	fmt.Println("   GET    /api/gold/price/moving-averages (7, 30 and 90 days)")
+	fmt.Println("   GET    /api/gold/price/stats (changes, 52-week high/low)")
+	fmt.Println("   POST   /api/gold/price (admin: set or correct any date)")
+	fmt.Println("   DELETE /api/gold/price/:date/:gold_type (admin)")
+	fmt.Println("   POST   /api/gold/price/import (admin: CSV backfill)")
+	fmt.Println("   POST   /api/gold/price/fetch (admin: pull from configured providers)")
+	fmt.Println("   GET    /api/gold/price/changes (admin: audit trail)")
+	fmt.Println("   GET    /api/zakat/mal?date= (nisab, haul, obligation)")
+	fmt.Println("   GET    /api/zakat/penghasilan?month=&year= (zakat on income)")
+	fmt.Println("   POST   /api/zakat/payments")
 	fmt.Println()
 
 	if err := router.Run(":" + port); err != nil {
 		log.Fatal("Failed to start server:", err)
 	}
 }
+
+// postDueInstallments books due credit card installments at startup and then
+// once per interval
+func postDueInstallments(creditCardRepo *repository.CreditCardRepository, interval time.Duration) {
+	for {
+		if err := creditCardRepo.PostDueInstallments(time.Now()); err != nil {
+			log.Printf("Failed to post credit card installments: %v", err)
+		}
+		time.Sleep(interval)
+	}
+}
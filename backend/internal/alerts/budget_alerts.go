@@ -0,0 +1,109 @@
+package alerts
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/financial-tracker/backend/internal/models"
+	"github.com/financial-tracker/backend/internal/repository"
+	"github.com/google/uuid"
+)
+
+// BudgetAlerter fires budget threshold notifications when spending changes
+type BudgetAlerter struct {
+	budgetRepo       *repository.BudgetRepository
+	notificationRepo *repository.NotificationRepository
+}
+
+func NewBudgetAlerter(budgetRepo *repository.BudgetRepository, notificationRepo *repository.NotificationRepository) *BudgetAlerter {
+	return &BudgetAlerter{
+		budgetRepo:       budgetRepo,
+		notificationRepo: notificationRepo,
+	}
+}
+
+// Evaluate checks the weekly, monthly and yearly budgets covering category on
+// date and notifies the user about every threshold that has been reached. Each
+// threshold fires at most once per budget period thanks to the notification
+// dedupe key. Expenses created or edited through the API are evaluated;
+// bulk imports are out of scope until the tree has a transaction import path.
+func (a *BudgetAlerter) Evaluate(userID uuid.UUID, category string, date time.Time) error {
+	month, year, err := a.budgetRepo.MonthOf(userID, date)
+	if err != nil {
+		return err
+	}
+	weekYear, week := date.ISOWeek()
+
+	periods := []struct {
+		period            models.BudgetPeriod
+		year, month, week int
+		label             string
+	}{
+		{models.BudgetPeriodWeekly, weekYear, 0, week, fmt.Sprintf("week %d of %d", week, weekYear)},
+		{models.BudgetPeriodMonthly, year, month, 0, fmt.Sprintf("%02d/%d", month, year)},
+		{models.BudgetPeriodYearly, year, 0, 0, fmt.Sprintf("%d", year)},
+	}
+
+	for _, p := range periods {
+		report, err := a.budgetRepo.GetProgress(userID, p.period, p.year, p.month, p.week, time.Now())
+		if err != nil {
+			return err
+		}
+
+		for _, progress := range report.Budgets {
+			if progress.Category != category {
+				continue
+			}
+
+			budget, err := a.budgetRepo.GetByID(progress.BudgetID)
+			if err != nil {
+				return err
+			}
+
+			thresholds := append([]int64{}, budget.AlertThresholds...)
+			sort.Slice(thresholds, func(i, j int) bool { return thresholds[i] < thresholds[j] })
+
+			for _, threshold := range thresholds {
+				if progress.PercentUsed < float64(threshold) {
+					break
+				}
+				if err := a.notify(userID, progress, threshold, report, p.label); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (a *BudgetAlerter) notify(userID uuid.UUID, progress models.BudgetProgress, threshold int64, report *models.BudgetProgressReport, label string) error {
+	dedupeKey := fmt.Sprintf("budget:%s:%d", progress.BudgetID, threshold)
+
+	title := fmt.Sprintf("%s budget at %d%%", progress.Category, threshold)
+	if threshold >= 100 {
+		title = fmt.Sprintf("%s budget exceeded", progress.Category)
+	}
+
+	_, err := a.notificationRepo.Create(&models.Notification{
+		UserID:  userID,
+		Type:    models.NotificationBudgetThreshold,
+		Title:   title,
+		Message: fmt.Sprintf("You have spent %.0f of %.0f (%.0f%%) in %s for %s.", progress.Spent, progress.Available, progress.PercentUsed, progress.Category, label),
+		Data: map[string]interface{}{
+			"budget_id":    progress.BudgetID,
+			"category":     progress.Category,
+			"threshold":    threshold,
+			"spent":        progress.Spent,
+			"available":    progress.Available,
+			"percent_used": progress.PercentUsed,
+			"period":       report.Period,
+			"month":        report.Month,
+			"year":         report.Year,
+			"week":         report.Week,
+		},
+		DedupeKey: &dedupeKey,
+	})
+	return err
+}
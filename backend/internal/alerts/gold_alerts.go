@@ -0,0 +1,229 @@
+package alerts
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/financial-tracker/backend/internal/models"
+	"github.com/financial-tracker/backend/internal/repository"
+	"github.com/google/uuid"
+)
+
+// GoldPriceAlerter fires gold price alerts when a new quote is stored. Quotes
+// are queued and evaluated in batches by Run, so price writes never wait on
+// alert evaluation.
+type GoldPriceAlerter struct {
+	goldRepo         *repository.GoldRepository
+	notificationRepo *repository.NotificationRepository
+
+	mu      sync.Mutex
+	pending []models.GoldPrice
+	wake    chan struct{}
+}
+
+func NewGoldPriceAlerter(goldRepo *repository.GoldRepository, notificationRepo *repository.NotificationRepository) *GoldPriceAlerter {
+	return &GoldPriceAlerter{
+		goldRepo:         goldRepo,
+		notificationRepo: notificationRepo,
+		wake:             make(chan struct{}, 1),
+	}
+}
+
+// HandlePrice queues price for evaluation and returns right away; it is
+// meant to be registered with GoldRepository.OnPriceSet
+func (a *GoldPriceAlerter) HandlePrice(price models.GoldPrice) {
+	a.mu.Lock()
+	a.pending = append(a.pending, price)
+	a.mu.Unlock()
+
+	select {
+	case a.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run evaluates queued quotes until ctx is done. Everything queued since the
+// last batch (e.g. a whole import) is evaluated together; failures are logged.
+func (a *GoldPriceAlerter) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-a.wake:
+		}
+
+		a.mu.Lock()
+		batch := a.pending
+		a.pending = nil
+		a.mu.Unlock()
+
+		if err := a.EvaluateBatch(batch); err != nil {
+			log.Printf("Failed to evaluate gold price alerts: %v", err)
+		}
+	}
+}
+
+// EvaluateBatch checks the alerts affected by a batch of new quotes. Each
+// brand's price alerts are checked against its newest quote in the batch,
+// and position alerts once, against the newest quote overall. Quotes older
+// than the brand's latest (backfills and corrections of past days) are
+// ignored. An alert fires when its condition starts to hold and re-arms once
+// it stops holding; daily_change alerts fire for every quote that moves
+// enough. The notification dedupe key keeps each alert to one notification
+// per day.
+func (a *GoldPriceAlerter) EvaluateBatch(prices []models.GoldPrice) error {
+	newest := make(map[models.GoldType]models.GoldPrice)
+	order := []models.GoldType{}
+	for _, price := range prices {
+		current, ok := newest[price.GoldType]
+		if !ok {
+			order = append(order, price.GoldType)
+		}
+		// Later writes of the same day replace earlier ones
+		if !ok || !price.PriceDate.Before(current.PriceDate) {
+			newest[price.GoldType] = price
+		}
+	}
+
+	var positionPrice *models.GoldPrice
+	for _, goldType := range order {
+		price := newest[goldType]
+		latest, err := a.goldRepo.GetLatestPrice(goldType)
+		if err != nil {
+			return err
+		}
+		if price.PriceDate.Before(latest.PriceDate) {
+			continue
+		}
+		if err := a.evaluatePriceAlerts(price); err != nil {
+			return err
+		}
+		if positionPrice == nil || price.PriceDate.After(positionPrice.PriceDate) {
+			positionPrice = &price
+		}
+	}
+	if positionPrice == nil {
+		return nil
+	}
+	return a.evaluatePositionAlerts(*positionPrice)
+}
+
+// evaluatePriceAlerts checks the price level and daily move alerts of the
+// quote's brand
+func (a *GoldPriceAlerter) evaluatePriceAlerts(price models.GoldPrice) error {
+	priceAlerts, err := a.goldRepo.GetActivePriceAlerts(price.GoldType)
+	if err != nil {
+		return err
+	}
+	var previous *models.GoldPrice
+	previousLoaded := false
+	for _, alert := range priceAlerts {
+		value := alert.PriceKind.Price(price)
+		if alert.AlertType == models.GoldAlertDailyChange {
+			if !previousLoaded {
+				if previous, err = a.goldRepo.GetPriceBefore(price.GoldType, price.PriceDate); err != nil {
+					return err
+				}
+				previousLoaded = true
+			}
+			if previous == nil {
+				continue
+			}
+			value = models.PercentChange(alert.PriceKind.Price(*previous), value)
+		}
+		if err := a.apply(alert, value, price); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// evaluatePositionAlerts re-checks the position alerts of every brand, see
+// GetActivePositionAlerts; price dates the notifications
+func (a *GoldPriceAlerter) evaluatePositionAlerts(price models.GoldPrice) error {
+	positionAlerts, err := a.goldRepo.GetActivePositionAlerts()
+	if err != nil {
+		return err
+	}
+	var assets []models.GoldAsset
+	var assetsOf *uuid.UUID
+	for _, alert := range positionAlerts {
+		if assetsOf == nil || *assetsOf != alert.UserID {
+			if assets, err = a.goldRepo.GetAssetsByUserID(alert.UserID, false); err != nil {
+				return err
+			}
+			userID := alert.UserID
+			assetsOf = &userID
+		}
+		_, _, percent, ok := models.PositionPL(assets, alert.GoldType)
+		if !ok {
+			continue
+		}
+		if err := a.apply(alert, percent, price); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// apply moves the alert to the state value puts it in, notifying the user
+// when it fires
+func (a *GoldPriceAlerter) apply(alert models.GoldPriceAlert, value float64, price models.GoldPrice) error {
+	holds := alert.Holds(value)
+	fires := holds && (!alert.IsTriggered || alert.AlertType == models.GoldAlertDailyChange)
+	if fires {
+		if err := a.notify(alert, value, price); err != nil {
+			return err
+		}
+	}
+	if fires || holds != alert.IsTriggered {
+		return a.goldRepo.SetAlertTriggered(alert.ID, holds)
+	}
+	return nil
+}
+
+func (a *GoldPriceAlerter) notify(alert models.GoldPriceAlert, value float64, price models.GoldPrice) error {
+	dedupeKey := fmt.Sprintf("gold_alert:%s:%s", alert.ID, price.PriceDate.Format("2006-01-02"))
+
+	brand := "Gold"
+	if alert.GoldType != nil {
+		brand = fmt.Sprintf("Gold (%s)", *alert.GoldType)
+	}
+
+	var title, message string
+	switch alert.AlertType {
+	case models.GoldAlertPriceAbove:
+		title = fmt.Sprintf("%s %s price above %.0f", brand, alert.PriceKind, alert.Threshold)
+		message = fmt.Sprintf("The %s price is %.0f per gram on %s.", alert.PriceKind, value, price.PriceDate.Format("2006-01-02"))
+	case models.GoldAlertPriceBelow:
+		title = fmt.Sprintf("%s %s price below %.0f", brand, alert.PriceKind, alert.Threshold)
+		message = fmt.Sprintf("The %s price is %.0f per gram on %s.", alert.PriceKind, value, price.PriceDate.Format("2006-01-02"))
+	case models.GoldAlertDailyChange:
+		title = fmt.Sprintf("%s %s price moved %+.2f%%", brand, alert.PriceKind, value)
+		message = fmt.Sprintf("The %s price moved %+.2f%% to %.0f per gram on %s.", alert.PriceKind, value, alert.PriceKind.Price(price), price.PriceDate.Format("2006-01-02"))
+	case models.GoldAlertPositionPL:
+		title = fmt.Sprintf("%s position at %+.2f%%", brand, value)
+		message = fmt.Sprintf("Your unrealized profit/loss reached %+.2f%% (alert at %+.2f%%) with prices of %s.", value, alert.Threshold, price.PriceDate.Format("2006-01-02"))
+	}
+
+	_, err := a.notificationRepo.Create(&models.Notification{
+		UserID:  alert.UserID,
+		Type:    models.NotificationGoldPriceAlert,
+		Title:   title,
+		Message: message,
+		Data: map[string]interface{}{
+			"alert_id":   alert.ID,
+			"alert_type": alert.AlertType,
+			"gold_type":  alert.GoldType,
+			"price_kind": alert.PriceKind,
+			"threshold":  alert.Threshold,
+			"value":      value,
+			"price_date": price.PriceDate.Format("2006-01-02"),
+		},
+		DedupeKey: &dedupeKey,
+	})
+	return err
+}
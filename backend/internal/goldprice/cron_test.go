@@ -0,0 +1,75 @@
+package goldprice
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseScheduleErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"0 9 * *",
+		"0 9 * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"*/x * * * *",
+		"a * * * *",
+		"1-b * * * *",
+	}
+	for _, expr := range tests {
+		t.Run(expr, func(t *testing.T) {
+			if _, err := ParseSchedule(expr); err == nil {
+				t.Errorf("ParseSchedule(%q) succeeded, want an error", expr)
+			}
+		})
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	at := func(year int, month time.Month, day, hour, minute int) time.Time {
+		return time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	// 2025-01-01 is a Wednesday
+	tests := []struct {
+		name string
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{"daily later today", "0 9 * * *", at(2025, 1, 1, 8, 30), at(2025, 1, 1, 9, 0)},
+		{"strictly after", "0 9 * * *", at(2025, 1, 1, 9, 0), at(2025, 1, 2, 9, 0)},
+		{"seconds are dropped", "0 9 * * *", time.Date(2025, 1, 1, 8, 59, 30, 0, time.UTC), at(2025, 1, 1, 9, 0)},
+		{"minute step", "*/15 * * * *", at(2025, 1, 1, 10, 7), at(2025, 1, 1, 10, 15)},
+		{"range with step", "30 8-12/2 * * *", at(2025, 1, 1, 8, 31), at(2025, 1, 1, 10, 30)},
+		{"value with step runs to the max", "0 20/2 * * *", at(2025, 1, 1, 21, 0), at(2025, 1, 1, 22, 0)},
+		{"list", "0 6,18 * * *", at(2025, 1, 1, 7, 0), at(2025, 1, 1, 18, 0)},
+		{"weekdays skip the weekend", "0 9 * * 1-5", at(2025, 1, 3, 10, 0), at(2025, 1, 6, 9, 0)},
+		{"0 is sunday", "0 0 * * 0", at(2025, 1, 1, 0, 0), at(2025, 1, 5, 0, 0)},
+		{"7 is sunday", "0 0 * * 7", at(2025, 1, 1, 0, 0), at(2025, 1, 5, 0, 0)},
+		{"month step", "0 0 1 */6 *", at(2025, 2, 1, 0, 0), at(2025, 7, 1, 0, 0)},
+		{"year rollover", "0 0 1 1 *", at(2025, 6, 1, 0, 0), at(2026, 1, 1, 0, 0)},
+		{"day of month only", "0 0 13 * *", at(2025, 1, 1, 0, 0), at(2025, 1, 13, 0, 0)},
+		{"dom or dow matches the friday", "0 0 13 * 5", at(2025, 1, 1, 0, 0), at(2025, 1, 3, 0, 0)},
+		{"dom or dow matches the 13th", "0 0 13 * 5", at(2025, 1, 11, 0, 0), at(2025, 1, 13, 0, 0)},
+		{"dom with any dow must match dom", "0 0 13 * *", at(2025, 1, 3, 0, 0), at(2025, 1, 13, 0, 0)},
+		{"leap day", "0 0 29 2 *", at(2025, 1, 1, 0, 0), at(2028, 2, 29, 0, 0)},
+		{"never matches", "0 0 31 2 *", at(2025, 1, 1, 0, 0), time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := ParseSchedule(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseSchedule(%q) error = %v", tt.expr, err)
+			}
+			if got := s.Next(tt.from); !got.Equal(tt.want) {
+				t.Errorf("Next(%s) = %s, want %s", tt.from.Format(time.RFC3339), got.Format(time.RFC3339), tt.want.Format(time.RFC3339))
+			}
+		})
+	}
+}
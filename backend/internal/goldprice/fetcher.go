@@ -0,0 +1,142 @@
+package goldprice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/financial-tracker/backend/internal/models"
+)
+
+// PriceStore saves a day's quote of one brand
+type PriceStore interface {
+	UpsertPrice(priceDate time.Time, goldType models.GoldType, buyPricePerGram, buybackPricePerGram float64, source string) error
+}
+
+// ProviderSource lists the providers to try, in order. It is called on every
+// fetch so configuration changes apply without a restart.
+type ProviderSource func() ([]GoldPriceProvider, error)
+
+// FetchResult - the outcome of a successful fetch
+type FetchResult struct {
+	Date     time.Time `json:"date"`
+	Source   string    `json:"source"`
+	Quotes   []Quote   `json:"quotes"`
+	Attempts int       `json:"attempts"`
+}
+
+// Fetcher pulls quotes from the first provider that answers, retrying each
+// with exponential backoff before falling back to the next, and stores them
+// with the provider's name as source.
+type Fetcher struct {
+	providers ProviderSource
+	store     PriceStore
+	// Attempts per provider and the delay before the first retry; the delay
+	// doubles on every further retry
+	Attempts int
+	Backoff  time.Duration
+}
+
+func NewFetcher(providers ProviderSource, store PriceStore) *Fetcher {
+	return &Fetcher{
+		providers: providers,
+		store:     store,
+		Attempts:  3,
+		Backoff:   30 * time.Second,
+	}
+}
+
+// Fetch fetches and stores the quotes of date
+func (f *Fetcher) Fetch(ctx context.Context, date time.Time) (*FetchResult, error) {
+	providers, err := f.providers()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load gold price providers: %w", err)
+	}
+	if len(providers) == 0 {
+		return nil, errors.New("no gold price provider is configured")
+	}
+
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	attempts := 0
+	var errs []error
+
+	for _, provider := range providers {
+		quotes, n, err := f.fetchWithRetry(ctx, provider, day)
+		attempts += n
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", provider.Name(), err))
+			if ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+
+		source := "api:" + provider.Name()
+		for _, q := range quotes {
+			if err := f.store.UpsertPrice(day, q.GoldType, q.BuyPricePerGram, q.BuybackPricePerGram, source); err != nil {
+				return nil, fmt.Errorf("failed to store %s price: %w", q.GoldType, err)
+			}
+		}
+		return &FetchResult{Date: day, Source: source, Quotes: quotes, Attempts: attempts}, nil
+	}
+
+	return nil, fmt.Errorf("all gold price providers failed: %w", errors.Join(errs...))
+}
+
+func (f *Fetcher) fetchWithRetry(ctx context.Context, provider GoldPriceProvider, day time.Time) ([]Quote, int, error) {
+	attempts := f.Attempts
+	if attempts < 1 {
+		attempts = 1
+	}
+	delay := f.Backoff
+
+	var lastErr error
+	for i := 1; i <= attempts; i++ {
+		quotes, err := provider.Fetch(ctx, day)
+		if err == nil {
+			return quotes, i, nil
+		}
+		lastErr = err
+		log.Printf("Gold price fetch from %s failed (attempt %d/%d): %v", provider.Name(), i, attempts, err)
+
+		if i == attempts {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return nil, i, ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+	return nil, attempts, lastErr
+}
+
+// Run fetches today's quotes at every time matched by schedule until ctx is
+// done. Schedule times are evaluated in the server's local time.
+func (f *Fetcher) Run(ctx context.Context, schedule *Schedule) {
+	for {
+		next := schedule.Next(time.Now())
+		if next.IsZero() {
+			log.Println("Gold price schedule never fires; scheduler stopped")
+			return
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		result, err := f.Fetch(ctx, next)
+		if err != nil {
+			log.Printf("Scheduled gold price fetch failed: %v", err)
+			continue
+		}
+		log.Printf("Fetched %d gold prices for %s from %s", len(result.Quotes), result.Date.Format("2006-01-02"), result.Source)
+	}
+}
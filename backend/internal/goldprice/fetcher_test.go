@@ -0,0 +1,152 @@
+package goldprice
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/financial-tracker/backend/internal/models"
+)
+
+type storedPrice struct {
+	date     time.Time
+	goldType models.GoldType
+	buy      float64
+	buyback  float64
+	source   string
+}
+
+type stubStore struct {
+	prices []storedPrice
+}
+
+func (s *stubStore) UpsertPrice(priceDate time.Time, goldType models.GoldType, buy, buyback float64, source string) error {
+	s.prices = append(s.prices, storedPrice{priceDate, goldType, buy, buyback, source})
+	return nil
+}
+
+// stubServer answers with a server error for the first failures requests,
+// then with antamPrices
+func stubServer(t *testing.T, failures int) (*httptest.Server, *int32) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if int(atomic.AddInt32(&calls, 1)) <= failures {
+			http.Error(w, "try again", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(antamPrices))
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func newTestFetcher(store PriceStore, providers ...GoldPriceProvider) *Fetcher {
+	f := NewFetcher(func() ([]GoldPriceProvider, error) { return providers, nil }, store)
+	f.Backoff = time.Millisecond
+	return f
+}
+
+func TestFetcherRetriesProvider(t *testing.T) {
+	server, calls := stubServer(t, 2)
+	store := &stubStore{}
+	f := newTestFetcher(store, NewHTTPProvider("primary", server.URL, "", "", nil))
+
+	result, err := f.Fetch(context.Background(), time.Date(2025, 3, 7, 15, 30, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if *calls != 3 || result.Attempts != 3 {
+		t.Errorf("calls = %d, attempts = %d, want 3 each", *calls, result.Attempts)
+	}
+	if !result.Date.Equal(time.Date(2025, 3, 7, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("date = %s, want the day without time", result.Date)
+	}
+}
+
+func TestFetcherFallsBackToNextProvider(t *testing.T) {
+	down, downCalls := stubServer(t, 100)
+	up, upCalls := stubServer(t, 0)
+	store := &stubStore{}
+	f := newTestFetcher(store,
+		NewHTTPProvider("primary", down.URL, "", "", nil),
+		NewHTTPProvider("backup", up.URL, "", "", nil))
+
+	result, err := f.Fetch(context.Background(), time.Date(2025, 3, 7, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if *downCalls != 3 || *upCalls != 1 {
+		t.Errorf("calls = %d primary, %d backup, want 3 and 1", *downCalls, *upCalls)
+	}
+	if result.Attempts != 4 {
+		t.Errorf("attempts = %d, want 4", result.Attempts)
+	}
+	if result.Source != "api:backup" {
+		t.Errorf("source = %q, want api:backup", result.Source)
+	}
+}
+
+func TestFetcherRecordsSource(t *testing.T) {
+	server, _ := stubServer(t, 0)
+	store := &stubStore{}
+	f := newTestFetcher(store, NewHTTPProvider("logam-mulia", server.URL, "", "", nil))
+
+	day := time.Date(2025, 3, 7, 0, 0, 0, 0, time.UTC)
+	if _, err := f.Fetch(context.Background(), day); err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	want := storedPrice{day, models.GoldTypeAntam, 1500000, 1350000, "api:logam-mulia"}
+	if len(store.prices) != 1 || store.prices[0] != want {
+		t.Errorf("stored = %+v, want [%+v]", store.prices, want)
+	}
+}
+
+func TestFetcherSingleAttempt(t *testing.T) {
+	server, calls := stubServer(t, 100)
+	store := &stubStore{}
+	f := newTestFetcher(store, NewHTTPProvider("primary", server.URL, "", "", nil))
+	f.Attempts = 1
+
+	if _, err := f.Fetch(context.Background(), time.Now()); err == nil {
+		t.Fatal("Fetch() succeeded, want an error")
+	}
+	if *calls != 1 {
+		t.Errorf("calls = %d, want 1", *calls)
+	}
+}
+
+func TestFetcherErrors(t *testing.T) {
+	down, _ := stubServer(t, 100)
+
+	tests := []struct {
+		name      string
+		providers ProviderSource
+		wantErr   string
+	}{
+		{"no providers", func() ([]GoldPriceProvider, error) { return nil, nil }, "no gold price provider is configured"},
+		{"providers fail to load", func() ([]GoldPriceProvider, error) { return nil, errors.New("db down") }, "failed to load gold price providers"},
+		{"all providers fail", func() ([]GoldPriceProvider, error) {
+			return []GoldPriceProvider{NewHTTPProvider("primary", down.URL, "", "", nil)}, nil
+		}, "all gold price providers failed: primary: unexpected status 503"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &stubStore{}
+			f := NewFetcher(tt.providers, store)
+			f.Backoff = time.Millisecond
+
+			_, err := f.Fetch(context.Background(), time.Now())
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Fetch() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+			if len(store.prices) != 0 {
+				t.Errorf("stored %d prices on failure", len(store.prices))
+			}
+		})
+	}
+}
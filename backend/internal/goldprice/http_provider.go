@@ -0,0 +1,175 @@
+package goldprice
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/financial-tracker/backend/internal/models"
+)
+
+const defaultHTTPTimeout = 15 * time.Second
+
+// HTTPProvider fetches quotes from a JSON endpoint. The endpoint must answer
+//
+//	{"prices": [{"gold_type": "antam", "buy_price_per_gram": 1500000, "buyback_price_per_gram": 1350000}]}
+//
+// and may contain a {date} placeholder, replaced by the requested day as
+// YYYY-MM-DD.
+type HTTPProvider struct {
+	name         string
+	endpoint     string
+	apiKey       string
+	apiKeyHeader string
+	client       *http.Client
+}
+
+func NewHTTPProvider(name, endpoint, apiKey, apiKeyHeader string, client *http.Client) *HTTPProvider {
+	if apiKeyHeader == "" {
+		apiKeyHeader = "Authorization"
+	}
+	if client == nil {
+		client = &http.Client{Timeout: defaultHTTPTimeout}
+	}
+	return &HTTPProvider{
+		name:         name,
+		endpoint:     endpoint,
+		apiKey:       apiKey,
+		apiKeyHeader: apiKeyHeader,
+		client:       client,
+	}
+}
+
+// NewHTTPProviderFromConfig builds a provider from an api_configurations row.
+// Config keys: endpoint (required), api_key, api_key_header (default
+// Authorization, sent as "Bearer <api_key>") and timeout_seconds.
+func NewHTTPProviderFromConfig(cfg models.APIConfiguration) (*HTTPProvider, error) {
+	endpoint := configString(cfg.Config, "endpoint")
+	if endpoint == "" {
+		return nil, fmt.Errorf("%s: endpoint is not configured", cfg.APIName)
+	}
+
+	timeout := defaultHTTPTimeout
+	if seconds := configNumber(cfg.Config, "timeout_seconds"); seconds > 0 {
+		timeout = time.Duration(seconds * float64(time.Second))
+	}
+
+	return NewHTTPProvider(cfg.APIName, endpoint, configString(cfg.Config, "api_key"),
+		configString(cfg.Config, "api_key_header"), &http.Client{Timeout: timeout}), nil
+}
+
+func (p *HTTPProvider) Name() string {
+	return p.name
+}
+
+func (p *HTTPProvider) Fetch(ctx context.Context, date time.Time) ([]Quote, error) {
+	url := strings.ReplaceAll(p.endpoint, "{date}", date.Format("2006-01-02"))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if p.apiKey != "" {
+		if p.apiKeyHeader == "Authorization" {
+			req.Header.Set("Authorization", "Bearer "+p.apiKey)
+		} else {
+			req.Header.Set(p.apiKeyHeader, p.apiKey)
+		}
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch prices: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var payload struct {
+		Prices []Quote `json:"prices"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return nil, fmt.Errorf("failed to decode prices: %w", err)
+	}
+	if len(payload.Prices) == 0 {
+		return nil, fmt.Errorf("no prices in response")
+	}
+	for _, q := range payload.Prices {
+		if err := q.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return payload.Prices, nil
+}
+
+// ProvidersFromConfigs builds an HTTPProvider for every active gold price
+// configuration, ordered by the optional "priority" config key (lowest
+// first) and then by name. Misconfigured entries are skipped and reported.
+func ProvidersFromConfigs(configs []models.APIConfiguration) ([]GoldPriceProvider, []error) {
+	active := []models.APIConfiguration{}
+	for _, cfg := range configs {
+		if cfg.APIType == APIType && cfg.IsActive {
+			active = append(active, cfg)
+		}
+	}
+	sort.SliceStable(active, func(i, j int) bool {
+		pi, pj := configNumber(active[i].Config, "priority"), configNumber(active[j].Config, "priority")
+		if pi != pj {
+			return pi < pj
+		}
+		return active[i].APIName < active[j].APIName
+	})
+
+	providers := []GoldPriceProvider{}
+	var errs []error
+	for _, cfg := range active {
+		provider, err := NewHTTPProviderFromConfig(cfg)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		providers = append(providers, provider)
+	}
+	return providers, errs
+}
+
+// ConfiguredProviders returns a ProviderSource that builds the providers
+// from the api_configurations rows returned by load
+func ConfiguredProviders(load func() ([]models.APIConfiguration, error)) ProviderSource {
+	return func() ([]GoldPriceProvider, error) {
+		configs, err := load()
+		if err != nil {
+			return nil, err
+		}
+		providers, errs := ProvidersFromConfigs(configs)
+		for _, err := range errs {
+			log.Printf("Skipping gold price provider: %v", err)
+		}
+		return providers, nil
+	}
+}
+
+func configString(config map[string]interface{}, key string) string {
+	value, _ := config[key].(string)
+	return strings.TrimSpace(value)
+}
+
+func configNumber(config map[string]interface{}, key string) float64 {
+	switch value := config[key].(type) {
+	case float64:
+		return value
+	case int:
+		return float64(value)
+	}
+	return 0
+}
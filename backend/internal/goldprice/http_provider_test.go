@@ -0,0 +1,123 @@
+package goldprice
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/financial-tracker/backend/internal/models"
+)
+
+const antamPrices = `{"prices": [{"gold_type": "antam", "buy_price_per_gram": 1500000, "buyback_price_per_gram": 1350000}]}`
+
+func TestHTTPProviderFetch(t *testing.T) {
+	var gotPath string
+	var gotHeader http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotHeader = r.Header
+		w.Write([]byte(antamPrices))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		header     string
+		wantHeader string
+		wantValue  string
+	}{
+		{"bearer token by default", "", "Authorization", "Bearer secret"},
+		{"custom header gets the raw key", "X-API-Key", "X-API-Key", "secret"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := NewHTTPProvider("stub", server.URL+"/prices/{date}", "secret", tt.header, nil)
+			quotes, err := provider.Fetch(context.Background(), time.Date(2025, 3, 7, 0, 0, 0, 0, time.UTC))
+			if err != nil {
+				t.Fatalf("Fetch() error = %v", err)
+			}
+			if gotPath != "/prices/2025-03-07" {
+				t.Errorf("path = %q, want the date substituted", gotPath)
+			}
+			if got := gotHeader.Get(tt.wantHeader); got != tt.wantValue {
+				t.Errorf("%s header = %q, want %q", tt.wantHeader, got, tt.wantValue)
+			}
+			want := Quote{GoldType: models.GoldTypeAntam, BuyPricePerGram: 1500000, BuybackPricePerGram: 1350000}
+			if len(quotes) != 1 || quotes[0] != want {
+				t.Errorf("quotes = %+v, want [%+v]", quotes, want)
+			}
+		})
+	}
+}
+
+func TestHTTPProviderFetchWithoutKey(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			t.Errorf("Authorization header sent without an API key")
+		}
+		w.Write([]byte(antamPrices))
+	}))
+	defer server.Close()
+
+	provider := NewHTTPProvider("stub", server.URL, "", "", nil)
+	if _, err := provider.Fetch(context.Background(), time.Now()); err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+}
+
+func TestHTTPProviderFetchErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr string
+	}{
+		{"server error", http.StatusInternalServerError, "upstream down", "unexpected status 500: upstream down"},
+		{"not found", http.StatusNotFound, "", "unexpected status 404"},
+		{"invalid json", http.StatusOK, "{not json", "failed to decode prices"},
+		{"no prices", http.StatusOK, `{"prices": []}`, "no prices in response"},
+		{"unknown gold type", http.StatusOK, `{"prices": [{"gold_type": "bogus", "buy_price_per_gram": 1, "buyback_price_per_gram": 1}]}`, `unknown gold type "bogus"`},
+		{"buyback above buy", http.StatusOK, `{"prices": [{"gold_type": "ubs", "buy_price_per_gram": 100, "buyback_price_per_gram": 200}]}`, "buyback price above buy price"},
+		{"non-positive price", http.StatusOK, `{"prices": [{"gold_type": "ubs", "buy_price_per_gram": 0, "buyback_price_per_gram": 0}]}`, "prices must be positive"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			provider := NewHTTPProvider("stub", server.URL, "", "", nil)
+			_, err := provider.Fetch(context.Background(), time.Now())
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Fetch() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestProvidersFromConfigs(t *testing.T) {
+	configs := []models.APIConfiguration{
+		{APIName: "second", APIType: APIType, IsActive: true, Config: map[string]interface{}{"endpoint": "http://b", "priority": float64(2)}},
+		{APIName: "first", APIType: APIType, IsActive: true, Config: map[string]interface{}{"endpoint": "http://a", "priority": float64(1)}},
+		{APIName: "inactive", APIType: APIType, IsActive: false, Config: map[string]interface{}{"endpoint": "http://c"}},
+		{APIName: "other api", APIType: "exchange_rate", IsActive: true, Config: map[string]interface{}{"endpoint": "http://d"}},
+		{APIName: "no endpoint", APIType: APIType, IsActive: true, Config: map[string]interface{}{}},
+	}
+
+	providers, errs := ProvidersFromConfigs(configs)
+	if len(errs) != 1 {
+		t.Errorf("errs = %v, want one for the missing endpoint", errs)
+	}
+	names := []string{}
+	for _, p := range providers {
+		names = append(names, p.Name())
+	}
+	if strings.Join(names, ",") != "first,second" {
+		t.Errorf("providers = %v, want [first second]", names)
+	}
+}
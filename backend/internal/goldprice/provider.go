@@ -0,0 +1,40 @@
+package goldprice
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/financial-tracker/backend/internal/models"
+)
+
+// APIType is the api_configurations.api_type of gold price providers
+const APIType = "gold_price"
+
+// Quote - one brand's price per gram as reported by a provider
+type Quote struct {
+	GoldType            models.GoldType `json:"gold_type"`
+	BuyPricePerGram     float64         `json:"buy_price_per_gram"`
+	BuybackPricePerGram float64         `json:"buyback_price_per_gram"`
+}
+
+// Validate rejects quotes that cannot be stored
+func (q Quote) Validate() error {
+	if !models.ValidGoldType(q.GoldType) {
+		return fmt.Errorf("unknown gold type %q", q.GoldType)
+	}
+	if q.BuyPricePerGram <= 0 || q.BuybackPricePerGram <= 0 {
+		return fmt.Errorf("%s: prices must be positive", q.GoldType)
+	}
+	if q.BuybackPricePerGram > q.BuyPricePerGram {
+		return fmt.Errorf("%s: buyback price above buy price", q.GoldType)
+	}
+	return nil
+}
+
+// GoldPriceProvider fetches the quotes of one day from a price source
+type GoldPriceProvider interface {
+	// Name identifies the provider; it is recorded as the price source
+	Name() string
+	Fetch(ctx context.Context, date time.Time) ([]Quote, error)
+}
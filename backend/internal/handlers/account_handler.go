@@ -24,6 +24,22 @@ func (h *AccountHandler) Create(c *gin.Context) {
 		return
 	}
 
+	// Paylater accounts are debt: they need a limit and a due day, and
+	// nothing else carries them
+	if req.Type == models.AccountTypePaylater {
+		if req.CreditLimit == nil || req.DueDay == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "credit_limit and due_day are required for paylater accounts"})
+			return
+		}
+		if req.ParentAccountID != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Paylater accounts cannot be sub-accounts"})
+			return
+		}
+	} else if req.CreditLimit != nil || req.DueDay != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "credit_limit and due_day only apply to paylater accounts"})
+		return
+	}
+
 	userID, _ := c.Get("user_id")
 	account := &models.Account{
 		UserID:          userID.(uuid.UUID),
@@ -31,6 +47,8 @@ func (h *AccountHandler) Create(c *gin.Context) {
 		Type:            req.Type,
 		Currency:        req.Currency,
 		ParentAccountID: req.ParentAccountID,
+		CreditLimit:     req.CreditLimit,
+		DueDay:          req.DueDay,
 	}
 
 	if account.Currency == "" {
@@ -41,6 +59,7 @@ func (h *AccountHandler) Create(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create account"})
 		return
 	}
+	account.SetAvailableCredit()
 
 	c.JSON(http.StatusCreated, account)
 }
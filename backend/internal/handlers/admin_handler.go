@@ -178,11 +178,15 @@ func (h *AdminHandler) GetDashboardStats(c *gin.Context) {
 		}
 	}
 
-	// Get gold price
-	goldPrice, _ := h.goldRepo.GetLatestPrice()
+	// Get the latest gold price of each brand; the headline price is the
+	// antam buy price
+	goldPrices, _ := h.goldRepo.GetLatestPrices()
+	if goldPrices == nil {
+		goldPrices = []models.GoldPrice{}
+	}
 	var currentGoldPrice float64
-	if goldPrice != nil {
-		currentGoldPrice = goldPrice.PricePerGram
+	if reference := models.NewGoldQuotes(goldPrices).For(models.GoldTypeAntam); reference != nil {
+		currentGoldPrice = reference.BuyPricePerGram
 	}
 
 	// Get API configs status
@@ -197,10 +201,11 @@ func (h *AdminHandler) GetDashboardStats(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"total_users":        userCount,
-		"admin_users":        adminCount,
-		"current_gold_price": currentGoldPrice,
-		"active_apis":        activeAPIs,
-		"total_apis":         len(configs),
+		"total_users":         userCount,
+		"admin_users":         adminCount,
+		"current_gold_price":  currentGoldPrice,
+		"current_gold_prices": goldPrices,
+		"active_apis":         activeAPIs,
+		"total_apis":          len(configs),
 	})
 }
@@ -3,6 +3,7 @@ package handlers
 import (
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/financial-tracker/backend/internal/models"
 	"github.com/financial-tracker/backend/internal/repository"
@@ -11,25 +12,86 @@ import (
 )
 
 type BudgetHandler struct {
-	budgetRepo *repository.BudgetRepository
+	budgetRepo  *repository.BudgetRepository
+	accountRepo *repository.AccountRepository
 }
 
-func NewBudgetHandler(budgetRepo *repository.BudgetRepository) *BudgetHandler {
-	return &BudgetHandler{budgetRepo: budgetRepo}
+func NewBudgetHandler(budgetRepo *repository.BudgetRepository, accountRepo *repository.AccountRepository) *BudgetHandler {
+	return &BudgetHandler{
+		budgetRepo:  budgetRepo,
+		accountRepo: accountRepo,
+	}
 }
 
+// CreateBudgetRequest - monthly budgets need budget_month, weekly budgets an
+// ISO budget_week of budget_year, and yearly budgets neither
 type CreateBudgetRequest struct {
-	Category    string  `json:"category" binding:"required"`
-	Amount      float64 `json:"amount" binding:"required,gt=0"`
-	BudgetMonth int     `json:"budget_month" binding:"required,min=1,max=12"`
-	BudgetYear  int     `json:"budget_year" binding:"required,min=2020"`
+	Category        string   `json:"category" binding:"required"`
+	Amount          float64  `json:"amount" binding:"required,gt=0"`
+	Period          string   `json:"period" binding:"omitempty,oneof=weekly monthly yearly"`
+	BudgetMonth     int      `json:"budget_month" binding:"omitempty,min=1,max=12"`
+	BudgetYear      int      `json:"budget_year" binding:"required,min=2020"`
+	BudgetWeek      int      `json:"budget_week" binding:"omitempty,min=1,max=53"`
+	RolloverMode    string   `json:"rollover_mode" binding:"omitempty,oneof=none surplus surplus_and_deficit"`
+	RolloverCap     *float64 `json:"rollover_cap" binding:"omitempty,gte=0"`
+	AlertThresholds []int64  `json:"alert_thresholds" binding:"omitempty,dive,gt=0,lte=1000"`
+	// Pocket (sub-account) holding this budget's money in zero-based mode
+	EnvelopeAccountID *uuid.UUID `json:"envelope_account_id"`
+}
+
+// AssignBudgetRequest - give part of a month's income a job. A negative
+// amount takes money back out of the budget. With move_funds the money is
+// also moved from the envelope pocket's parent account into the pocket.
+type AssignBudgetRequest struct {
+	Category          string     `json:"category" binding:"required"`
+	Amount            float64    `json:"amount" binding:"required"`
+	BudgetMonth       int        `json:"budget_month" binding:"omitempty,min=1,max=12"`
+	BudgetYear        int        `json:"budget_year" binding:"omitempty,min=2020"`
+	EnvelopeAccountID *uuid.UUID `json:"envelope_account_id"`
+	MoveFunds         bool       `json:"move_funds"`
+}
+
+// BulkBudgetItem - one category of a month's budget plan
+type BulkBudgetItem struct {
+	Category        string   `json:"category" binding:"required"`
+	Amount          float64  `json:"amount" binding:"required,gt=0"`
+	RolloverMode    string   `json:"rollover_mode" binding:"omitempty,oneof=none surplus surplus_and_deficit"`
+	RolloverCap     *float64 `json:"rollover_cap" binding:"omitempty,gte=0"`
+	AlertThresholds []int64  `json:"alert_thresholds" binding:"omitempty,dive,gt=0,lte=1000"`
+	// Pocket holding this budget's money; omitted keeps the current envelope
+	EnvelopeAccountID *uuid.UUID `json:"envelope_account_id"`
+}
+
+type BulkUpsertBudgetRequest struct {
+	BudgetMonth   int              `json:"budget_month" binding:"required,min=1,max=12"`
+	BudgetYear    int              `json:"budget_year" binding:"required,min=2020"`
+	Budgets       []BulkBudgetItem `json:"budgets" binding:"required,min=1,dive"`
+	RemoveMissing bool             `json:"remove_missing"`
+}
+
+type UpdateBudgetAlertsRequest struct {
+	Thresholds []int64 `json:"thresholds" binding:"required,dive,gt=0,lte=1000"`
 }
 
+type ApplySuggestionsRequest struct {
+	BudgetMonth int      `json:"budget_month" binding:"required,min=1,max=12"`
+	BudgetYear  int      `json:"budget_year" binding:"required,min=2020"`
+	Months      int      `json:"months" binding:"omitempty,min=1,max=24"`
+	Method      string   `json:"method" binding:"omitempty,oneof=average median percentile"`
+	Percentile  float64  `json:"percentile" binding:"omitempty,gt=0,lte=100"`
+	Categories  []string `json:"categories"`
+	Overwrite   bool     `json:"overwrite"`
+}
+
+// CopyBudgetRequest - omitted target month defaults to the current financial
+// month, omitted source month to the one before the target
 type CopyBudgetRequest struct {
-	FromMonth int `json:"from_month" binding:"required,min=1,max=12"`
-	FromYear  int `json:"from_year" binding:"required,min=2020"`
-	ToMonth   int `json:"to_month" binding:"required,min=1,max=12"`
-	ToYear    int `json:"to_year" binding:"required,min=2020"`
+	FromMonth    int     `json:"from_month" binding:"omitempty,min=1,max=12"`
+	FromYear     int     `json:"from_year" binding:"omitempty,min=2020"`
+	ToMonth      int     `json:"to_month" binding:"omitempty,min=1,max=12"`
+	ToYear       int     `json:"to_year" binding:"omitempty,min=2020"`
+	Overwrite    bool    `json:"overwrite"`
+	ScalePercent float64 `json:"scale_percent" binding:"gte=-100"`
 }
 
 func (h *BudgetHandler) Create(c *gin.Context) {
@@ -39,17 +101,39 @@ func (h *BudgetHandler) Create(c *gin.Context) {
 		return
 	}
 
+	period, month, week, msg := normalizePeriod(req)
+	if msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
 	userID, _ := c.Get("user_id")
+	if msg := h.checkEnvelope(userID.(uuid.UUID), req.EnvelopeAccountID); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
 
 	budget := &models.Budget{
-		UserID:      userID.(uuid.UUID),
-		Category:    req.Category,
-		Amount:      req.Amount,
-		BudgetMonth: req.BudgetMonth,
-		BudgetYear:  req.BudgetYear,
+		UserID:            userID.(uuid.UUID),
+		Category:          req.Category,
+		Amount:            req.Amount,
+		Period:            period,
+		BudgetMonth:       month,
+		BudgetYear:        req.BudgetYear,
+		BudgetWeek:        week,
+		RolloverMode:      models.RolloverMode(req.RolloverMode),
+		RolloverCap:       req.RolloverCap,
+		EnvelopeAccountID: req.EnvelopeAccountID,
+	}
+	if req.AlertThresholds != nil {
+		budget.AlertThresholds = req.AlertThresholds
 	}
 
 	if err := h.budgetRepo.Create(budget); err != nil {
+		if repository.IsDuplicateError(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": "A budget for this category already exists in that period"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create budget. Category might already exist for this month."})
 		return
 	}
@@ -57,9 +141,69 @@ func (h *BudgetHandler) Create(c *gin.Context) {
 	c.JSON(http.StatusCreated, budget)
 }
 
+// checkEnvelope verifies that an envelope account is one of the user's
+// pockets and returns an error message otherwise
+func (h *BudgetHandler) checkEnvelope(userID uuid.UUID, accountID *uuid.UUID) string {
+	if accountID == nil {
+		return ""
+	}
+	account, err := h.accountRepo.GetByID(*accountID)
+	if err != nil || account.UserID != userID {
+		return "Envelope account not found"
+	}
+	if account.ParentAccountID == nil {
+		return "Envelope account must be a pocket (sub-account)"
+	}
+	return ""
+}
+
+// normalizePeriod validates the period fields of req and returns the period
+// with the month and week it uses, or an error message
+func normalizePeriod(req CreateBudgetRequest) (models.BudgetPeriod, int, int, string) {
+	period := models.BudgetPeriod(req.Period)
+	if period == "" {
+		period = models.BudgetPeriodMonthly
+	}
+
+	switch period {
+	case models.BudgetPeriodWeekly:
+		if !models.ValidISOWeek(req.BudgetYear, req.BudgetWeek) {
+			return "", 0, 0, "budget_week must be a valid ISO week of budget_year"
+		}
+		return period, 0, req.BudgetWeek, ""
+	case models.BudgetPeriodYearly:
+		return period, 0, 0, ""
+	default:
+		if req.BudgetMonth == 0 {
+			return "", 0, 0, "budget_month is required for monthly budgets"
+		}
+		return period, req.BudgetMonth, 0, ""
+	}
+}
+
 func (h *BudgetHandler) GetAll(c *gin.Context) {
 	userID, _ := c.Get("user_id")
 
+	if p := c.Query("period"); p != "" {
+		period := models.BudgetPeriod(p)
+		if period != models.BudgetPeriodWeekly && period != models.BudgetPeriodMonthly && period != models.BudgetPeriodYearly {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid period"})
+			return
+		}
+		year, _ := strconv.Atoi(c.Query("year"))
+		month, _ := strconv.Atoi(c.Query("month"))
+		week, _ := strconv.Atoi(c.Query("week"))
+		if year != 0 {
+			budgets, err := h.budgetRepo.GetByPeriod(userID.(uuid.UUID), period, year, month, week)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get budgets"})
+				return
+			}
+			c.JSON(http.StatusOK, budgets)
+			return
+		}
+	}
+
 	// Check for month/year filters
 	monthStr := c.Query("month")
 	yearStr := c.Query("year")
@@ -109,6 +253,122 @@ func (h *BudgetHandler) GetByID(c *gin.Context) {
 	c.JSON(http.StatusOK, budget)
 }
 
+// Update replaces a budget's category, amount, month and settings.
+// Alert thresholds are kept when not provided.
+func (h *BudgetHandler) Update(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid budget ID"})
+		return
+	}
+
+	budget, err := h.budgetRepo.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Budget not found"})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if budget.UserID != userID.(uuid.UUID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return
+	}
+
+	var req CreateBudgetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	period, month, week, msg := normalizePeriod(req)
+	if msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	if msg := h.checkEnvelope(userID.(uuid.UUID), req.EnvelopeAccountID); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	budget.Category = req.Category
+	budget.Amount = req.Amount
+	budget.Period = period
+	budget.BudgetMonth = month
+	budget.BudgetYear = req.BudgetYear
+	budget.BudgetWeek = week
+	budget.RolloverMode = models.RolloverNone
+	if req.RolloverMode != "" {
+		budget.RolloverMode = models.RolloverMode(req.RolloverMode)
+	}
+	budget.RolloverCap = req.RolloverCap
+	budget.EnvelopeAccountID = req.EnvelopeAccountID
+	if req.AlertThresholds != nil {
+		budget.AlertThresholds = req.AlertThresholds
+	}
+
+	if err := h.budgetRepo.Update(budget); err != nil {
+		if repository.IsDuplicateError(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": "A budget for this category already exists in that period"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update budget"})
+		return
+	}
+
+	c.JSON(http.StatusOK, budget)
+}
+
+// BulkUpsert saves a whole month's budget plan in one call
+func (h *BudgetHandler) BulkUpsert(c *gin.Context) {
+	var req BulkUpsertBudgetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	seen := make(map[string]bool)
+	budgets := make([]models.Budget, 0, len(req.Budgets))
+	for _, item := range req.Budgets {
+		if seen[item.Category] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Duplicate category in plan: " + item.Category})
+			return
+		}
+		seen[item.Category] = true
+
+		if msg := h.checkEnvelope(userID.(uuid.UUID), item.EnvelopeAccountID); msg != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+			return
+		}
+
+		budget := models.Budget{
+			Category:          item.Category,
+			Amount:            item.Amount,
+			RolloverMode:      models.RolloverMode(item.RolloverMode),
+			RolloverCap:       item.RolloverCap,
+			EnvelopeAccountID: item.EnvelopeAccountID,
+		}
+		if item.AlertThresholds != nil {
+			budget.AlertThresholds = item.AlertThresholds
+		}
+		budgets = append(budgets, budget)
+	}
+
+	if err := h.budgetRepo.UpsertMonth(userID.(uuid.UUID), req.BudgetMonth, req.BudgetYear, budgets, req.RemoveMissing); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save budgets"})
+		return
+	}
+
+	saved, err := h.budgetRepo.GetByMonthYear(userID.(uuid.UUID), req.BudgetMonth, req.BudgetYear)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get budgets"})
+		return
+	}
+
+	c.JSON(http.StatusOK, saved)
+}
+
 func (h *BudgetHandler) Delete(c *gin.Context) {
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
@@ -136,6 +396,41 @@ func (h *BudgetHandler) Delete(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Budget deleted successfully"})
 }
 
+// UpdateAlerts replaces the notification thresholds of a budget
+func (h *BudgetHandler) UpdateAlerts(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid budget ID"})
+		return
+	}
+
+	budget, err := h.budgetRepo.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Budget not found"})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if budget.UserID != userID.(uuid.UUID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return
+	}
+
+	var req UpdateBudgetAlertsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.budgetRepo.UpdateAlertThresholds(id, req.Thresholds); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update budget alerts"})
+		return
+	}
+
+	budget.AlertThresholds = req.Thresholds
+	c.JSON(http.StatusOK, budget)
+}
+
 // CopyFromMonth copies budgets from one month to another
 func (h *BudgetHandler) CopyFromMonth(c *gin.Context) {
 	var req CopyBudgetRequest
@@ -146,14 +441,324 @@ func (h *BudgetHandler) CopyFromMonth(c *gin.Context) {
 
 	userID, _ := c.Get("user_id")
 
-	count, err := h.budgetRepo.CopyFromMonth(userID.(uuid.UUID), req.FromMonth, req.FromYear, req.ToMonth, req.ToYear)
+	if req.ToMonth == 0 || req.ToYear == 0 {
+		month, year, err := h.budgetRepo.MonthOf(userID.(uuid.UUID), time.Now())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to copy budgets"})
+			return
+		}
+		req.ToMonth, req.ToYear = month, year
+	}
+	if req.FromMonth == 0 || req.FromYear == 0 {
+		prev := time.Date(req.ToYear, time.Month(req.ToMonth)-1, 1, 0, 0, 0, 0, time.UTC)
+		req.FromMonth, req.FromYear = int(prev.Month()), prev.Year()
+	}
+	if req.FromMonth == req.ToMonth && req.FromYear == req.ToYear {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Source and target month must differ"})
+		return
+	}
+
+	opts := models.BudgetCopyOptions{
+		Overwrite:    req.Overwrite,
+		ScalePercent: req.ScalePercent,
+	}
+	results, err := h.budgetRepo.CopyFromMonth(userID.(uuid.UUID), req.FromMonth, req.FromYear, req.ToMonth, req.ToYear, opts)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to copy budgets"})
 		return
 	}
 
+	counts := make(map[models.BudgetCopyStatus]int)
+	for _, r := range results {
+		counts[r.Status]++
+	}
+
+	message := "Budgets copied successfully"
+	if counts[models.BudgetCopyFailed] > 0 {
+		message = "Some budgets could not be copied"
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Budgets copied successfully",
-		"copied":  count,
+		"message":     message,
+		"copied":      counts[models.BudgetCopyCopied],
+		"overwritten": counts[models.BudgetCopyOverwritten],
+		"skipped":     counts[models.BudgetCopySkipped],
+		"failed":      counts[models.BudgetCopyFailed],
+		"results":     results,
 	})
 }
+
+// GetProgress compares budgets of a period with actual spending. The period
+// defaults to the current financial month; ?period=weekly|yearly switches to
+// the current ISO week or financial year.
+func (h *BudgetHandler) GetProgress(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	now := time.Now()
+	month, year, err := h.budgetRepo.MonthOf(userID.(uuid.UUID), now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get budget progress"})
+		return
+	}
+	week := 0
+
+	period := models.BudgetPeriod(c.DefaultQuery("period", string(models.BudgetPeriodMonthly)))
+	switch period {
+	case models.BudgetPeriodWeekly:
+		year, week = now.ISOWeek()
+	case models.BudgetPeriodMonthly, models.BudgetPeriodYearly:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid period"})
+		return
+	}
+
+	if m := c.Query("month"); m != "" && period == models.BudgetPeriodMonthly {
+		parsed, err := strconv.Atoi(m)
+		if err != nil || parsed < 1 || parsed > 12 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid month"})
+			return
+		}
+		month = parsed
+	}
+	if y := c.Query("year"); y != "" {
+		parsed, err := strconv.Atoi(y)
+		if err != nil || parsed < 2020 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid year"})
+			return
+		}
+		year = parsed
+	}
+	if w := c.Query("week"); w != "" && period == models.BudgetPeriodWeekly {
+		parsed, err := strconv.Atoi(w)
+		if err != nil || !models.ValidISOWeek(year, parsed) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid week"})
+			return
+		}
+		week = parsed
+	}
+
+	report, err := h.budgetRepo.GetProgress(userID.(uuid.UUID), period, year, month, week, now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get budget progress"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetSuggestions proposes budget amounts for a month from past spending
+func (h *BudgetHandler) GetSuggestions(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	// Default to planning the month after the current financial month
+	month, year, err := h.budgetRepo.MonthOf(userID.(uuid.UUID), time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get budget suggestions"})
+		return
+	}
+	next := time.Date(year, time.Month(month)+1, 1, 0, 0, 0, 0, time.UTC)
+	month, year = int(next.Month()), next.Year()
+	months := 6
+	percentile := 75.0
+
+	if m := c.Query("month"); m != "" {
+		parsed, err := strconv.Atoi(m)
+		if err != nil || parsed < 1 || parsed > 12 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid month"})
+			return
+		}
+		month = parsed
+	}
+	if y := c.Query("year"); y != "" {
+		parsed, err := strconv.Atoi(y)
+		if err != nil || parsed < 2020 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid year"})
+			return
+		}
+		year = parsed
+	}
+	if n := c.Query("months"); n != "" {
+		parsed, err := strconv.Atoi(n)
+		if err != nil || parsed < 1 || parsed > 24 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "months must be between 1 and 24"})
+			return
+		}
+		months = parsed
+	}
+	method := models.SuggestionMethod(c.DefaultQuery("method", string(models.SuggestionAverage)))
+	if method != models.SuggestionAverage && method != models.SuggestionMedian && method != models.SuggestionPercentile {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "method must be average, median or percentile"})
+		return
+	}
+	if p := c.Query("percentile"); p != "" {
+		parsed, err := strconv.ParseFloat(p, 64)
+		if err != nil || parsed <= 0 || parsed > 100 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "percentile must be between 0 and 100"})
+			return
+		}
+		percentile = parsed
+	}
+
+	report, err := h.budgetRepo.GetSuggestions(userID.(uuid.UUID), month, year, months, method, percentile)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get budget suggestions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// ApplySuggestions saves suggested amounts as budgets of the target month
+func (h *BudgetHandler) ApplySuggestions(c *gin.Context) {
+	var req ApplySuggestionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	if req.Months == 0 {
+		req.Months = 6
+	}
+	if req.Method == "" {
+		req.Method = string(models.SuggestionAverage)
+	}
+	if req.Percentile == 0 {
+		req.Percentile = 75
+	}
+
+	report, err := h.budgetRepo.GetSuggestions(userID.(uuid.UUID), req.BudgetMonth, req.BudgetYear, req.Months, models.SuggestionMethod(req.Method), req.Percentile)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get budget suggestions"})
+		return
+	}
+
+	wanted := make(map[string]bool)
+	for _, category := range req.Categories {
+		wanted[category] = true
+	}
+
+	amounts := make(map[string]float64)
+	for _, s := range report.Suggestions {
+		if len(wanted) == 0 || wanted[s.Category] {
+			amounts[s.Category] = s.SuggestedAmount
+		}
+	}
+
+	results, err := h.budgetRepo.ApplyAmounts(userID.(uuid.UUID), req.BudgetMonth, req.BudgetYear, amounts, req.Overwrite)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to apply budget suggestions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Budget suggestions applied",
+		"results": results,
+	})
+}
+
+// GetZeroBased reports a financial month's income, how much of it is still
+// to be assigned and which envelopes are overspent
+func (h *BudgetHandler) GetZeroBased(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	if !h.requireZeroBased(c, userID.(uuid.UUID)) {
+		return
+	}
+
+	now := time.Now()
+	month, year, err := h.budgetRepo.MonthOf(userID.(uuid.UUID), now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get zero-based budget"})
+		return
+	}
+	if m := c.Query("month"); m != "" {
+		parsed, err := strconv.Atoi(m)
+		if err != nil || parsed < 1 || parsed > 12 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid month"})
+			return
+		}
+		month = parsed
+	}
+	if y := c.Query("year"); y != "" {
+		parsed, err := strconv.Atoi(y)
+		if err != nil || parsed < 2020 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid year"})
+			return
+		}
+		year = parsed
+	}
+
+	report, err := h.budgetRepo.GetZeroBased(userID.(uuid.UUID), month, year, now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get zero-based budget"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// Assign moves part of a month's unassigned income into a budget (envelope)
+func (h *BudgetHandler) Assign(c *gin.Context) {
+	var req AssignBudgetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	if !h.requireZeroBased(c, userID.(uuid.UUID)) {
+		return
+	}
+	if msg := h.checkEnvelope(userID.(uuid.UUID), req.EnvelopeAccountID); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	if req.BudgetMonth == 0 || req.BudgetYear == 0 {
+		month, year, err := h.budgetRepo.MonthOf(userID.(uuid.UUID), time.Now())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to assign budget"})
+			return
+		}
+		req.BudgetMonth, req.BudgetYear = month, year
+	}
+
+	budget, err := h.budgetRepo.Assign(userID.(uuid.UUID), req.BudgetMonth, req.BudgetYear, req.Category, req.Amount, req.EnvelopeAccountID, req.MoveFunds)
+	if err != nil {
+		if err == repository.ErrNegativeBudget {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot take back more than the budget holds"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to assign budget"})
+		return
+	}
+
+	report, err := h.budgetRepo.GetZeroBased(userID.(uuid.UUID), req.BudgetMonth, req.BudgetYear, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get zero-based budget"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"budget":         budget,
+		"to_be_assigned": report.ToBeAssigned,
+	})
+}
+
+// requireZeroBased answers the request with an error unless the user budgets
+// in zero-based mode
+func (h *BudgetHandler) requireZeroBased(c *gin.Context, userID uuid.UUID) bool {
+	mode, err := h.budgetRepo.BudgetingMode(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get budgeting mode"})
+		return false
+	}
+	if mode != models.BudgetingZeroBased {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Zero-based budgeting is not enabled. Set budgeting_mode to zero_based in your settings."})
+		return false
+	}
+	return true
+}
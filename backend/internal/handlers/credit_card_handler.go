@@ -1,8 +1,12 @@
 package handlers
 
 import (
+	"log"
 	"net/http"
+	"strconv"
+	"time"
 
+	"github.com/financial-tracker/backend/internal/alerts"
 	"github.com/financial-tracker/backend/internal/models"
 	"github.com/financial-tracker/backend/internal/repository"
 	"github.com/gin-gonic/gin"
@@ -10,11 +14,17 @@ import (
 )
 
 type CreditCardHandler struct {
-	cardRepo *repository.CreditCardRepository
+	cardRepo      *repository.CreditCardRepository
+	accountRepo   *repository.AccountRepository
+	budgetAlerter *alerts.BudgetAlerter
 }
 
-func NewCreditCardHandler(cardRepo *repository.CreditCardRepository) *CreditCardHandler {
-	return &CreditCardHandler{cardRepo: cardRepo}
+func NewCreditCardHandler(cardRepo *repository.CreditCardRepository, accountRepo *repository.AccountRepository, budgetAlerter *alerts.BudgetAlerter) *CreditCardHandler {
+	return &CreditCardHandler{
+		cardRepo:      cardRepo,
+		accountRepo:   accountRepo,
+		budgetAlerter: budgetAlerter,
+	}
 }
 
 type CreateCreditCardRequest struct {
@@ -24,6 +34,42 @@ type CreateCreditCardRequest struct {
 	CurrentBalance  float64 `json:"current_balance"`
 	BillingDate     int     `json:"billing_date" binding:"required,gte=1,lte=31"`
 	PaymentDueDate  int     `json:"payment_due_date" binding:"required,gte=1,lte=31"`
+	// Minimum payment settings; default to 5% with a floor of 50,000
+	MinPaymentPercent *float64 `json:"min_payment_percent" binding:"omitempty,gte=0,lte=100"`
+	MinPaymentFloor   *float64 `json:"min_payment_floor" binding:"omitempty,gte=0"`
+	// Rates for cost projections; default to 1.75% monthly interest and a 1% late fee capped at 100,000
+	InterestRateMonthly *float64 `json:"interest_rate_monthly" binding:"omitempty,gte=0"`
+	LateFeePercent      *float64 `json:"late_fee_percent" binding:"omitempty,gte=0"`
+	LateFeeCap          *float64 `json:"late_fee_cap" binding:"omitempty,gte=0"`
+}
+
+// UpdateCreditCardRequest - card details; the balance changes only through
+// card transactions. Omitted payment and rate settings are kept.
+type UpdateCreditCardRequest struct {
+	CardName            string   `json:"card_name" binding:"required"`
+	CreditLimit         float64  `json:"credit_limit" binding:"required,gt=0"`
+	BillingDate         int      `json:"billing_date" binding:"required,gte=1,lte=31"`
+	PaymentDueDate      int      `json:"payment_due_date" binding:"required,gte=1,lte=31"`
+	MinPaymentPercent   *float64 `json:"min_payment_percent" binding:"omitempty,gte=0,lte=100"`
+	MinPaymentFloor     *float64 `json:"min_payment_floor" binding:"omitempty,gte=0"`
+	InterestRateMonthly *float64 `json:"interest_rate_monthly" binding:"omitempty,gte=0"`
+	LateFeePercent      *float64 `json:"late_fee_percent" binding:"omitempty,gte=0"`
+	LateFeeCap          *float64 `json:"late_fee_cap" binding:"omitempty,gte=0"`
+}
+
+type CreateCardTransactionRequest struct {
+	Type            string  `json:"type" binding:"required,oneof=purchase refund fee"`
+	Category        string  `json:"category"`
+	Amount          float64 `json:"amount" binding:"required,gt=0"`
+	Description     string  `json:"description"`
+	TransactionDate string  `json:"transaction_date"`
+}
+
+type PayCreditCardRequest struct {
+	AccountID       string  `json:"account_id" binding:"required"`
+	Amount          float64 `json:"amount" binding:"required,gt=0"`
+	Description     string  `json:"description"`
+	TransactionDate string  `json:"transaction_date"`
 }
 
 func (h *CreditCardHandler) Create(c *gin.Context) {
@@ -36,14 +82,20 @@ func (h *CreditCardHandler) Create(c *gin.Context) {
 	userID, _ := c.Get("user_id")
 
 	card := &models.CreditCard{
-		UserID:         userID.(uuid.UUID),
-		CardName:       req.CardName,
-		LastFourDigits: req.LastFourDigits,
-		CreditLimit:    req.CreditLimit,
-		CurrentBalance: req.CurrentBalance,
-		BillingDate:    req.BillingDate,
-		PaymentDueDate: req.PaymentDueDate,
+		UserID:              userID.(uuid.UUID),
+		CardName:            req.CardName,
+		LastFourDigits:      req.LastFourDigits,
+		CreditLimit:         req.CreditLimit,
+		CurrentBalance:      req.CurrentBalance,
+		BillingDate:         req.BillingDate,
+		PaymentDueDate:      req.PaymentDueDate,
+		MinPaymentPercent:   models.DefaultMinPaymentPercent,
+		MinPaymentFloor:     models.DefaultMinPaymentFloor,
+		InterestRateMonthly: models.DefaultInterestRateMonthly,
+		LateFeePercent:      models.DefaultLateFeePercent,
+		LateFeeCap:          models.DefaultLateFeeCap,
 	}
+	applyCardSettings(card, req.MinPaymentPercent, req.MinPaymentFloor, req.InterestRateMonthly, req.LateFeePercent, req.LateFeeCap)
 
 	if err := h.cardRepo.Create(card); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create credit card"})
@@ -55,6 +107,7 @@ func (h *CreditCardHandler) Create(c *gin.Context) {
 
 func (h *CreditCardHandler) GetAll(c *gin.Context) {
 	userID, _ := c.Get("user_id")
+
 	cards, err := h.cardRepo.GetByUserID(userID.(uuid.UUID))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get credit cards"})
@@ -65,6 +118,15 @@ func (h *CreditCardHandler) GetAll(c *gin.Context) {
 }
 
 func (h *CreditCardHandler) GetByID(c *gin.Context) {
+	card, ok := h.getOwnedCard(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, card)
+}
+
+func (h *CreditCardHandler) Delete(c *gin.Context) {
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid credit card ID"})
@@ -83,32 +145,566 @@ func (h *CreditCardHandler) GetByID(c *gin.Context) {
 		return
 	}
 
+	if err := h.cardRepo.Delete(id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete credit card"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Credit card deleted successfully"})
+}
+
+func (h *CreditCardHandler) Update(c *gin.Context) {
+	card, ok := h.getOwnedCard(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateCreditCardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	card.CardName = req.CardName
+	card.CreditLimit = req.CreditLimit
+	card.BillingDate = req.BillingDate
+	card.PaymentDueDate = req.PaymentDueDate
+	applyCardSettings(card, req.MinPaymentPercent, req.MinPaymentFloor, req.InterestRateMonthly, req.LateFeePercent, req.LateFeeCap)
+
+	if err := h.cardRepo.Update(card); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update credit card"})
+		return
+	}
+
 	c.JSON(http.StatusOK, card)
 }
 
-func (h *CreditCardHandler) Delete(c *gin.Context) {
+// applyCardSettings overwrites the card's payment and rate settings that were given
+func applyCardSettings(card *models.CreditCard, minPaymentPercent, minPaymentFloor, interestRate, lateFeePercent, lateFeeCap *float64) {
+	if minPaymentPercent != nil {
+		card.MinPaymentPercent = *minPaymentPercent
+	}
+	if minPaymentFloor != nil {
+		card.MinPaymentFloor = *minPaymentFloor
+	}
+	if interestRate != nil {
+		card.InterestRateMonthly = *interestRate
+	}
+	if lateFeePercent != nil {
+		card.LateFeePercent = *lateFeePercent
+	}
+	if lateFeeCap != nil {
+		card.LateFeeCap = *lateFeeCap
+	}
+}
+
+// CreateTransaction books a purchase, refund or fee on a card
+func (h *CreditCardHandler) CreateTransaction(c *gin.Context) {
+	card, ok := h.getOwnedCard(c)
+	if !ok {
+		return
+	}
+
+	var req CreateCardTransactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	transactionDate, ok := parseTransactionDate(c, req.TransactionDate)
+	if !ok {
+		return
+	}
+
+	txType := models.CreditCardTransactionType(req.Type)
+	category := req.Category
+	if category == "" {
+		if txType != models.CreditCardFee {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Category is required"})
+			return
+		}
+		category = "Credit Card Fees"
+	}
+
+	if txType == models.CreditCardPurchase && req.Amount > card.AvailableCredit {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Purchase exceeds available credit"})
+		return
+	}
+
+	transaction := &models.CreditCardTransaction{
+		UserID:          card.UserID,
+		CreditCardID:    card.ID,
+		Type:            txType,
+		Category:        category,
+		Amount:          req.Amount,
+		Description:     req.Description,
+		TransactionDate: transactionDate,
+	}
+
+	if err := h.cardRepo.CreateTransaction(transaction); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create credit card transaction"})
+		return
+	}
+
+	if txType != models.CreditCardRefund {
+		if err := h.budgetAlerter.Evaluate(card.UserID, category, transactionDate); err != nil {
+			log.Printf("Failed to evaluate budget alerts: %v", err)
+		}
+	}
+
+	c.JSON(http.StatusCreated, transaction)
+}
+
+// GetTransactions lists a card's transactions
+func (h *CreditCardHandler) GetTransactions(c *gin.Context) {
+	card, ok := h.getOwnedCard(c)
+	if !ok {
+		return
+	}
+
+	limit := 50
+	offset := 0
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil {
+			limit = parsed
+		}
+	}
+	if o := c.Query("offset"); o != "" {
+		if parsed, err := strconv.Atoi(o); err == nil {
+			offset = parsed
+		}
+	}
+
+	transactions, err := h.cardRepo.GetTransactions(card.ID, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get credit card transactions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, transactions)
+}
+
+// Pay pays off part of a card's balance from one of the user's accounts
+func (h *CreditCardHandler) Pay(c *gin.Context) {
+	card, ok := h.getOwnedCard(c)
+	if !ok {
+		return
+	}
+
+	var req PayCreditCardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	accountID, err := uuid.Parse(req.AccountID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid account ID"})
+		return
+	}
+	account, err := h.accountRepo.GetByID(accountID)
+	if err != nil || account.UserID != card.UserID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+		return
+	}
+	if account.IsLiability() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Credit cards cannot be paid from a paylater account"})
+		return
+	}
+
+	transactionDate, ok := parseTransactionDate(c, req.TransactionDate)
+	if !ok {
+		return
+	}
+
+	payment := &models.CreditCardTransaction{
+		UserID:          card.UserID,
+		CreditCardID:    card.ID,
+		Category:        "Credit Card Payment",
+		Amount:          req.Amount,
+		Description:     req.Description,
+		TransactionDate: transactionDate,
+	}
+
+	transfer, err := h.cardRepo.Pay(payment, account.ID, card.CardName)
+	if err != nil {
+		if err == repository.ErrCardOverpayment {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Payment cannot exceed the card balance"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to pay credit card"})
+		return
+	}
+
+	card, err = h.cardRepo.GetByID(card.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get credit card"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"payment":     payment,
+		"transfer":    transfer,
+		"credit_card": card,
+	})
+}
+
+// GetStatements lists the card's statements, newest first, starting with the
+// cycle that is still open. ?months sets how many (default 6, max 24).
+func (h *CreditCardHandler) GetStatements(c *gin.Context) {
+	card, ok := h.getOwnedCard(c)
+	if !ok {
+		return
+	}
+
+	months := 6
+	if m := c.Query("months"); m != "" {
+		parsed, err := strconv.Atoi(m)
+		if err != nil || parsed < 1 || parsed > 24 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "months must be between 1 and 24"})
+			return
+		}
+		months = parsed
+	}
+
+	statements, err := h.cardRepo.GetStatements(card, months, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get credit card statements"})
+		return
+	}
+
+	c.JSON(http.StatusOK, statements)
+}
+
+// CreateInstallmentRequest - convert a purchase into monthly installments.
+// The first installment defaults to the statement that is currently open.
+type CreateInstallmentRequest struct {
+	Description         string  `json:"description" binding:"required"`
+	Category            string  `json:"category" binding:"required"`
+	Principal           float64 `json:"principal" binding:"required,gt=0"`
+	TenorMonths         int     `json:"tenor_months" binding:"required,min=1,max=60"`
+	InterestRateMonthly float64 `json:"interest_rate_monthly" binding:"gte=0"`
+	AdminFee            float64 `json:"admin_fee" binding:"gte=0"`
+	StartMonth          int     `json:"start_month" binding:"omitempty,min=1,max=12"`
+	StartYear           int     `json:"start_year" binding:"omitempty,min=2020"`
+}
+
+// CreateInstallment adds an installment plan (cicilan) to a card
+func (h *CreditCardHandler) CreateInstallment(c *gin.Context) {
+	card, ok := h.getOwnedCard(c)
+	if !ok {
+		return
+	}
+
+	var req CreateInstallmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Principal > card.AvailableCredit {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Principal exceeds available credit"})
+		return
+	}
+
+	if req.StartMonth == 0 || req.StartYear == 0 {
+		req.StartMonth, req.StartYear = models.StatementMonthOf(time.Now(), card.BillingDate)
+	}
+
+	plan := &models.CreditCardInstallment{
+		UserID:              card.UserID,
+		CreditCardID:        card.ID,
+		Description:         req.Description,
+		Category:            req.Category,
+		Principal:           req.Principal,
+		TenorMonths:         req.TenorMonths,
+		InterestRateMonthly: req.InterestRateMonthly,
+		AdminFee:            req.AdminFee,
+		StartMonth:          req.StartMonth,
+		StartYear:           req.StartYear,
+	}
+
+	if err := h.cardRepo.CreateInstallment(plan, card, time.Now()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create installment plan"})
+		return
+	}
+
+	if err := h.budgetAlerter.Evaluate(card.UserID, plan.Category, time.Now()); err != nil {
+		log.Printf("Failed to evaluate budget alerts: %v", err)
+	}
+
+	c.JSON(http.StatusCreated, plan)
+}
+
+// GetInstallments lists a card's installment plans with their outstanding principal
+func (h *CreditCardHandler) GetInstallments(c *gin.Context) {
+	card, ok := h.getOwnedCard(c)
+	if !ok {
+		return
+	}
+
+	plans, err := h.cardRepo.GetInstallments(card.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get installment plans"})
+		return
+	}
+
+	c.JSON(http.StatusOK, plans)
+}
+
+// GetAnalysis reports utilization, interest and late fee projections across
+// all of the user's cards
+func (h *CreditCardHandler) GetAnalysis(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	report, err := h.cardRepo.AnalyzeAll(userID.(uuid.UUID), time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to analyze credit cards"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetCardAnalysis reports utilization, interest and late fee projections for one card
+func (h *CreditCardHandler) GetCardAnalysis(c *gin.Context) {
+	card, ok := h.getOwnedCard(c)
+	if !ok {
+		return
+	}
+
+	analysis, err := h.cardRepo.Analyze(card, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to analyze credit card"})
+		return
+	}
+
+	c.JSON(http.StatusOK, analysis)
+}
+
+// getOwnedCard loads the card from the :id parameter and checks that it
+// belongs to the current user, answering the request otherwise
+func (h *CreditCardHandler) getOwnedCard(c *gin.Context) (*models.CreditCard, bool) {
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid credit card ID"})
-		return
+		return nil, false
 	}
 
 	card, err := h.cardRepo.GetByID(id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Credit card not found"})
-		return
+		return nil, false
 	}
 
 	userID, _ := c.Get("user_id")
 	if card.UserID != userID.(uuid.UUID) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return nil, false
+	}
+
+	return card, true
+}
+
+// parseTransactionDate parses an optional YYYY-MM-DD date, defaulting to now,
+// and answers the request when it is malformed
+func parseTransactionDate(c *gin.Context, value string) (time.Time, bool) {
+	if value == "" {
+		return time.Now(), true
+	}
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format. Use YYYY-MM-DD"})
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+// CreateRewardRuleRequest - omit category for the card's default rule
+type CreateRewardRuleRequest struct {
+	Category   *string  `json:"category"`
+	RewardType string   `json:"reward_type" binding:"required,oneof=points miles cashback"`
+	EarnRate   float64  `json:"earn_rate" binding:"required,gt=0"`
+	SpendUnit  float64  `json:"spend_unit" binding:"required,gt=0"`
+	UnitValue  *float64 `json:"unit_value" binding:"omitempty,gte=0"`
+	MonthlyCap *float64 `json:"monthly_cap" binding:"omitempty,gt=0"`
+}
+
+type RedeemRewardsRequest struct {
+	RewardType  string  `json:"reward_type" binding:"required,oneof=points miles cashback"`
+	Amount      float64 `json:"amount" binding:"required,gt=0"`
+	Value       float64 `json:"value" binding:"gte=0"`
+	Description string  `json:"description"`
+	RedeemedAt  string  `json:"redeemed_at"`
+}
+
+func (h *CreditCardHandler) CreateRewardRule(c *gin.Context) {
+	card, ok := h.getOwnedCard(c)
+	if !ok {
 		return
 	}
 
-	if err := h.cardRepo.Delete(id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete credit card"})
+	var req CreateRewardRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if req.Category != nil && *req.Category == "" {
+		req.Category = nil
+	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Credit card deleted successfully"})
+	rule := &models.CreditCardRewardRule{
+		UserID:       card.UserID,
+		CreditCardID: card.ID,
+		Category:     req.Category,
+		RewardType:   models.RewardType(req.RewardType),
+		EarnRate:     req.EarnRate,
+		SpendUnit:    req.SpendUnit,
+		UnitValue:    1,
+		MonthlyCap:   req.MonthlyCap,
+	}
+	if req.UnitValue != nil {
+		rule.UnitValue = *req.UnitValue
+	}
+
+	if err := h.cardRepo.CreateRewardRule(rule); err != nil {
+		if repository.IsDuplicateError(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": "This card already has a reward rule for that category"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create reward rule"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+func (h *CreditCardHandler) GetRewardRules(c *gin.Context) {
+	card, ok := h.getOwnedCard(c)
+	if !ok {
+		return
+	}
+
+	rules, err := h.cardRepo.GetRewardRules(card.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get reward rules"})
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+func (h *CreditCardHandler) DeleteRewardRule(c *gin.Context) {
+	card, ok := h.getOwnedCard(c)
+	if !ok {
+		return
+	}
+
+	ruleID, err := uuid.Parse(c.Param("ruleId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid reward rule ID"})
+		return
+	}
+
+	rule, err := h.cardRepo.GetRewardRuleByID(ruleID)
+	if err != nil || rule.CreditCardID != card.ID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Reward rule not found"})
+		return
+	}
+
+	if err := h.cardRepo.DeleteRewardRule(ruleID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete reward rule"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Reward rule deleted successfully"})
+}
+
+// GetRewards shows earned rewards per statement cycle, redemptions and balances
+func (h *CreditCardHandler) GetRewards(c *gin.Context) {
+	card, ok := h.getOwnedCard(c)
+	if !ok {
+		return
+	}
+
+	summary, err := h.cardRepo.GetRewardSummary(card)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get rewards"})
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+// Redeem records spending earned reward units
+func (h *CreditCardHandler) Redeem(c *gin.Context) {
+	card, ok := h.getOwnedCard(c)
+	if !ok {
+		return
+	}
+
+	var req RedeemRewardsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	redeemedAt, ok := parseTransactionDate(c, req.RedeemedAt)
+	if !ok {
+		return
+	}
+
+	redemption := &models.CreditCardRewardRedemption{
+		UserID:       card.UserID,
+		CreditCardID: card.ID,
+		RewardType:   models.RewardType(req.RewardType),
+		Amount:       req.Amount,
+		Value:        req.Value,
+		Description:  req.Description,
+		RedeemedAt:   redeemedAt,
+	}
+
+	if err := h.cardRepo.CreateRedemption(redemption); err != nil {
+		if err == repository.ErrInsufficientRewards {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Not enough rewards to redeem"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to redeem rewards"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, redemption)
+}
+
+// GetBestCard ranks the user's cards for a purchase: ?category= is required,
+// ?amount= adds estimates that respect this cycle's caps
+func (h *CreditCardHandler) GetBestCard(c *gin.Context) {
+	category := c.Query("category")
+	if category == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "category is required"})
+		return
+	}
+
+	amount := 0.0
+	if a := c.Query("amount"); a != "" {
+		parsed, err := strconv.ParseFloat(a, 64)
+		if err != nil || parsed < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid amount"})
+			return
+		}
+		amount = parsed
+	}
+
+	userID, _ := c.Get("user_id")
+	options, err := h.cardRepo.BestCardsFor(userID.(uuid.UUID), category, amount, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compare credit cards"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"category": category,
+		"amount":   amount,
+		"cards":    options,
+	})
 }
@@ -1,10 +1,18 @@
 package handlers
 
 import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/financial-tracker/backend/internal/goldprice"
 	"github.com/financial-tracker/backend/internal/models"
 	"github.com/financial-tracker/backend/internal/repository"
 	"github.com/gin-gonic/gin"
@@ -12,26 +20,118 @@ import (
 )
 
 type GoldHandler struct {
-	goldRepo *repository.GoldRepository
+	goldRepo    *repository.GoldRepository
+	accountRepo *repository.AccountRepository
+	goldFetcher *goldprice.Fetcher
 }
 
-func NewGoldHandler(goldRepo *repository.GoldRepository) *GoldHandler {
-	return &GoldHandler{goldRepo: goldRepo}
+func NewGoldHandler(goldRepo *repository.GoldRepository, accountRepo *repository.AccountRepository, goldFetcher *goldprice.Fetcher) *GoldHandler {
+	return &GoldHandler{
+		goldRepo:    goldRepo,
+		accountRepo: accountRepo,
+		goldFetcher: goldFetcher,
+	}
 }
 
+// CreateGoldAssetRequest - item_kind defaults to bar. Purity is given as
+// karat or purity (a 0-1 share); bars and coins default to 24k, jewelry
+// needs one of them. resale_haircut_percent only applies to jewelry and
+// defaults to models.DefaultJewelryHaircutPercent.
 type CreateGoldAssetRequest struct {
-	Name                 string  `json:"name" binding:"required"`
-	GoldType             string  `json:"gold_type" binding:"required"`
-	WeightGram           float64 `json:"weight_gram" binding:"required,gt=0"`
-	PurchasePricePerGram float64 `json:"purchase_price_per_gram" binding:"required,gt=0"`
-	PurchaseDate         string  `json:"purchase_date" binding:"required"`
-	StorageLocation      string  `json:"storage_location"`
-	Notes                string  `json:"notes"`
+	Name                 string   `json:"name" binding:"required"`
+	GoldType             string   `json:"gold_type" binding:"required"`
+	ItemKind             string   `json:"item_kind"`
+	Karat                *float64 `json:"karat" binding:"omitempty,gt=0,lte=24"`
+	Purity               *float64 `json:"purity" binding:"omitempty,gt=0,lte=1"`
+	ResaleHaircutPercent *float64 `json:"resale_haircut_percent" binding:"omitempty,gte=0,lt=100"`
+	WeightGram           float64  `json:"weight_gram" binding:"required,gt=0"`
+	PurchasePricePerGram float64  `json:"purchase_price_per_gram" binding:"required,gt=0"`
+	PurchaseDate         string   `json:"purchase_date" binding:"required"`
+	StorageLocation      string   `json:"storage_location"`
+	Notes                string   `json:"notes"`
+}
+
+// SellGoldRequest - method fifo sells weight_gram from the oldest lots (of
+// gold_type, if given), leaving their pieces alone; method specific sells
+// the listed lots, by weight_gram or whole pieces by piece_ids. Without
+// price_per_gram the current buyback price of the lots' brand is used.
+type SellGoldRequest struct {
+	Method       string                    `json:"method" binding:"required,oneof=fifo specific"`
+	GoldType     string                    `json:"gold_type"`
+	WeightGram   float64                   `json:"weight_gram" binding:"omitempty,gt=0"`
+	Lots         []models.GoldLotSelection `json:"lots" binding:"omitempty,dive"`
+	PricePerGram float64                   `json:"price_per_gram" binding:"omitempty,gt=0"`
+	Fee          float64                   `json:"fee" binding:"gte=0"`
+	AccountID    *uuid.UUID                `json:"account_id"`
+	SaleDate     string                    `json:"sale_date"`
+	Notes        string                    `json:"notes"`
+}
+
+// UpdateGoldAssetRequest - omitted fields are left unchanged. Changing
+// item_kind resets the resale haircut to the kind's default unless one is
+// given.
+type UpdateGoldAssetRequest struct {
+	Name                 *string  `json:"name" binding:"omitempty,min=1"`
+	GoldType             *string  `json:"gold_type"`
+	ItemKind             *string  `json:"item_kind"`
+	Karat                *float64 `json:"karat" binding:"omitempty,gt=0,lte=24"`
+	Purity               *float64 `json:"purity" binding:"omitempty,gt=0,lte=1"`
+	ResaleHaircutPercent *float64 `json:"resale_haircut_percent" binding:"omitempty,gte=0,lt=100"`
+	WeightGram           *float64 `json:"weight_gram" binding:"omitempty,gt=0"`
+	PurchasePricePerGram *float64 `json:"purchase_price_per_gram" binding:"omitempty,gt=0"`
+	PurchaseDate         *string  `json:"purchase_date"`
+	StorageLocation      *string  `json:"storage_location"`
+	Notes                *string  `json:"notes"`
+}
+
+// GoldPieceRequest - one physical piece of a lot; packaging_condition
+// defaults to sealed
+type GoldPieceRequest struct {
+	DenominationGram   float64 `json:"denomination_gram" binding:"required,gt=0"`
+	CertificateNumber  string  `json:"certificate_number"`
+	MintingYear        *int    `json:"minting_year" binding:"omitempty,min=1900,max=2100"`
+	PackagingCondition string  `json:"packaging_condition" binding:"omitempty,oneof=sealed opened damaged none"`
+	Notes              string  `json:"notes"`
+}
+
+type AddGoldPiecesRequest struct {
+	Pieces []GoldPieceRequest `json:"pieces" binding:"required,min=1,dive"`
+}
+
+// UpdateGoldPieceRequest - omitted fields are left unchanged; an empty
+// certificate_number clears it
+type UpdateGoldPieceRequest struct {
+	DenominationGram   *float64 `json:"denomination_gram" binding:"omitempty,gt=0"`
+	CertificateNumber  *string  `json:"certificate_number"`
+	MintingYear        *int     `json:"minting_year" binding:"omitempty,min=1900,max=2100"`
+	PackagingCondition *string  `json:"packaging_condition" binding:"omitempty,oneof=sealed opened damaged none"`
+	Notes              *string  `json:"notes"`
+}
+
+// GoldAlertRequest - threshold is a price per gram for price_above and
+// price_below, a percent for daily_change and position_pl. gold_type may
+// only be omitted for position_pl (all gold). Updates leave omitted fields
+// unchanged.
+type GoldAlertRequest struct {
+	AlertType *string  `json:"alert_type"`
+	GoldType  *string  `json:"gold_type"`
+	PriceKind *string  `json:"price_kind" binding:"omitempty,oneof=buy buyback"`
+	Threshold *float64 `json:"threshold"`
+	IsActive  *bool    `json:"is_active"`
+}
+
+type FetchGoldPriceRequest struct {
+	Date string `json:"date"`
 }
 
+// UpdateGoldPriceRequest - date defaults to today; an existing quote of the
+// date and brand is corrected
 type UpdateGoldPriceRequest struct {
-	PricePerGram float64 `json:"price_per_gram" binding:"required,gt=0"`
-	Source       string  `json:"source"`
+	Date                string  `json:"date"`
+	GoldType            string  `json:"gold_type" binding:"required"`
+	BuyPricePerGram     float64 `json:"buy_price_per_gram" binding:"required,gt=0"`
+	BuybackPricePerGram float64 `json:"buyback_price_per_gram" binding:"required,gt=0,ltefield=BuyPricePerGram"`
+	Source              string  `json:"source"`
 }
 
 // Asset handlers
@@ -45,6 +145,11 @@ func (h *GoldHandler) CreateAsset(c *gin.Context) {
 
 	userID, _ := c.Get("user_id")
 
+	if !models.ValidGoldType(models.GoldType(req.GoldType)) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid gold type"})
+		return
+	}
+
 	purchaseDate, err := time.Parse("2006-01-02", req.PurchaseDate)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid purchase date format (use YYYY-MM-DD)"})
@@ -61,6 +166,13 @@ func (h *GoldHandler) CreateAsset(c *gin.Context) {
 		StorageLocation:      req.StorageLocation,
 		Notes:                req.Notes,
 	}
+	itemKind := req.ItemKind
+	if itemKind == "" {
+		itemKind = string(models.GoldItemBar)
+	}
+	if !applyGoldPurity(c, asset, &itemKind, req.Karat, req.Purity, req.ResaleHaircutPercent) {
+		return
+	}
 
 	if err := h.goldRepo.CreateAsset(asset); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create gold asset"})
@@ -73,9 +185,11 @@ func (h *GoldHandler) CreateAsset(c *gin.Context) {
 	c.JSON(http.StatusCreated, asset)
 }
 
+// GetAllAssets lists the user's lots; ?include_sold=true adds fully sold ones
 func (h *GoldHandler) GetAllAssets(c *gin.Context) {
 	userID, _ := c.Get("user_id")
-	assets, err := h.goldRepo.GetAssetsByUserID(userID.(uuid.UUID))
+	includeSold := c.Query("include_sold") == "true"
+	assets, err := h.goldRepo.GetAssetsByUserID(userID.(uuid.UUID), includeSold)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get gold assets"})
 		return
@@ -106,6 +220,131 @@ func (h *GoldHandler) GetAssetByID(c *gin.Context) {
 	c.JSON(http.StatusOK, asset)
 }
 
+func (h *GoldHandler) UpdateAsset(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid asset ID"})
+		return
+	}
+
+	asset, err := h.goldRepo.GetAssetByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Gold asset not found"})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if asset.UserID != userID.(uuid.UUID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return
+	}
+
+	var req UpdateGoldAssetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Name != nil {
+		asset.Name = *req.Name
+	}
+	if req.GoldType != nil {
+		if !models.ValidGoldType(models.GoldType(*req.GoldType)) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid gold type"})
+			return
+		}
+		asset.GoldType = models.GoldType(*req.GoldType)
+	}
+	if !applyGoldPurity(c, asset, req.ItemKind, req.Karat, req.Purity, req.ResaleHaircutPercent) {
+		return
+	}
+	if req.WeightGram != nil {
+		asset.WeightGram = models.RoundGram(*req.WeightGram)
+	}
+	if req.PurchasePricePerGram != nil {
+		asset.PurchasePricePerGram = *req.PurchasePricePerGram
+	}
+	if req.PurchaseDate != nil {
+		purchaseDate, err := time.Parse("2006-01-02", *req.PurchaseDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid purchase date format (use YYYY-MM-DD)"})
+			return
+		}
+		asset.PurchaseDate = purchaseDate
+	}
+	if req.StorageLocation != nil {
+		asset.StorageLocation = *req.StorageLocation
+	}
+	if req.Notes != nil {
+		asset.Notes = *req.Notes
+	}
+
+	if err := h.goldRepo.UpdateAsset(asset); err != nil {
+		if errors.Is(err, repository.ErrInvalidGoldAsset) || errors.Is(err, repository.ErrInvalidGoldPiece) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update gold asset"})
+		return
+	}
+
+	// Reload to get calculated values
+	asset, err = h.goldRepo.GetAssetByID(asset.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get gold asset"})
+		return
+	}
+
+	c.JSON(http.StatusOK, asset)
+}
+
+// applyGoldPurity sets the item kind, purity and resale haircut given in a
+// create or update request, writing a 400 and returning false when they
+// are invalid
+func applyGoldPurity(c *gin.Context, asset *models.GoldAsset, itemKind *string, karat, purity, haircut *float64) bool {
+	if itemKind != nil {
+		kind := models.GoldItemKind(*itemKind)
+		if !models.ValidGoldItemKind(kind) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "item_kind must be bar, coin or jewelry"})
+			return false
+		}
+		if kind != asset.ItemKind && haircut == nil {
+			asset.ResaleHaircutPercent = 0
+			if kind == models.GoldItemJewelry {
+				asset.ResaleHaircutPercent = models.DefaultJewelryHaircutPercent
+			}
+		}
+		asset.ItemKind = kind
+	}
+
+	if karat != nil && purity != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Give either karat or purity, not both"})
+		return false
+	}
+	if karat != nil {
+		asset.Purity = models.PurityOf(*karat)
+	}
+	if purity != nil {
+		asset.Purity = math.Round(*purity*10000) / 10000
+	}
+	if asset.Purity <= 0 {
+		if asset.ItemKind == models.GoldItemJewelry {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "karat or purity is required for jewelry"})
+			return false
+		}
+		asset.Purity = 1
+	}
+
+	if haircut != nil {
+		asset.ResaleHaircutPercent = *haircut
+	}
+	if asset.ItemKind != models.GoldItemJewelry && asset.ResaleHaircutPercent > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "resale_haircut_percent only applies to jewelry"})
+		return false
+	}
+	return true
+}
+
 func (h *GoldHandler) DeleteAsset(c *gin.Context) {
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
@@ -133,6 +372,552 @@ func (h *GoldHandler) DeleteAsset(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Gold asset deleted successfully"})
 }
 
+// GetHoldings groups the gold still held by brand with its average cost and
+// break-even price. ?sell_fee_percent raises the break-even by a selling fee.
+func (h *GoldHandler) GetHoldings(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	sellFeePercent := 0.0
+	if value := c.Query("sell_fee_percent"); value != "" {
+		fee, err := strconv.ParseFloat(value, 64)
+		if err != nil || fee < 0 || fee >= 100 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "sell_fee_percent must be between 0 and 100"})
+			return
+		}
+		sellFeePercent = fee
+	}
+
+	assets, err := h.goldRepo.GetAssetsByUserID(userID.(uuid.UUID), false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get gold assets"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.GroupHoldings(assets, sellFeePercent))
+}
+
+// Piece handlers
+
+// GetPieces lists the pieces of a lot, sold ones included
+func (h *GoldHandler) GetPieces(c *gin.Context) {
+	asset, ok := h.ownAsset(c)
+	if !ok {
+		return
+	}
+
+	pieces, err := h.goldRepo.GetPieces(asset.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get gold pieces"})
+		return
+	}
+
+	c.JSON(http.StatusOK, pieces)
+}
+
+// AddPieces adds pieces to a lot; with its unsold pieces they must fit in
+// the weight still held
+func (h *GoldHandler) AddPieces(c *gin.Context) {
+	asset, ok := h.ownAsset(c)
+	if !ok {
+		return
+	}
+
+	var req AddGoldPiecesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	pieces := make([]*models.GoldPiece, len(req.Pieces))
+	for i, p := range req.Pieces {
+		pieces[i] = &models.GoldPiece{
+			UserID:             asset.UserID,
+			DenominationGram:   models.RoundGram(p.DenominationGram),
+			CertificateNumber:  certificateNumber(p.CertificateNumber),
+			MintingYear:        p.MintingYear,
+			PackagingCondition: models.GoldPackagingSealed,
+			Notes:              p.Notes,
+		}
+		if p.PackagingCondition != "" {
+			pieces[i].PackagingCondition = models.GoldPackaging(p.PackagingCondition)
+		}
+	}
+
+	if err := h.goldRepo.AddPieces(asset.ID, pieces); err != nil {
+		h.pieceError(c, err, "Failed to add gold pieces")
+		return
+	}
+
+	c.JSON(http.StatusCreated, pieces)
+}
+
+// UpdatePiece changes a piece's details; the denomination of a sold piece
+// cannot change
+func (h *GoldHandler) UpdatePiece(c *gin.Context) {
+	piece, ok := h.ownPiece(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateGoldPieceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.DenominationGram != nil {
+		denomination := models.RoundGram(*req.DenominationGram)
+		if piece.SaleLotID != nil && denomination != piece.DenominationGram {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "The denomination of a sold piece cannot change"})
+			return
+		}
+		piece.DenominationGram = denomination
+	}
+	if req.CertificateNumber != nil {
+		piece.CertificateNumber = certificateNumber(*req.CertificateNumber)
+	}
+	if req.MintingYear != nil {
+		piece.MintingYear = req.MintingYear
+	}
+	if req.PackagingCondition != nil {
+		piece.PackagingCondition = models.GoldPackaging(*req.PackagingCondition)
+	}
+	if req.Notes != nil {
+		piece.Notes = *req.Notes
+	}
+
+	if err := h.goldRepo.UpdatePiece(piece); err != nil {
+		h.pieceError(c, err, "Failed to update gold piece")
+		return
+	}
+
+	c.JSON(http.StatusOK, piece)
+}
+
+// DeletePiece removes an unsold piece; its weight becomes loose weight of
+// the lot. Sold pieces are kept as the record of the sale.
+func (h *GoldHandler) DeletePiece(c *gin.Context) {
+	piece, ok := h.ownPiece(c)
+	if !ok {
+		return
+	}
+	if piece.SaleLotID != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A sold piece cannot be deleted"})
+		return
+	}
+
+	if err := h.goldRepo.DeletePiece(piece.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete gold piece"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Gold piece deleted successfully"})
+}
+
+// GetInventory lists every physical piece still held, and the loose weight
+// of lots not split into pieces, by storage location. ?storage_location
+// limits it to one location.
+func (h *GoldHandler) GetInventory(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	items, err := h.goldRepo.GetInventory(userID.(uuid.UUID), c.Query("storage_location"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get gold inventory"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.GroupInventory(items))
+}
+
+func (h *GoldHandler) ownAsset(c *gin.Context) (*models.GoldAsset, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid asset ID"})
+		return nil, false
+	}
+
+	asset, err := h.goldRepo.GetAssetByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Gold asset not found"})
+		return nil, false
+	}
+
+	userID, _ := c.Get("user_id")
+	if asset.UserID != userID.(uuid.UUID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return nil, false
+	}
+	return asset, true
+}
+
+func (h *GoldHandler) ownPiece(c *gin.Context) (*models.GoldPiece, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid piece ID"})
+		return nil, false
+	}
+
+	piece, err := h.goldRepo.GetPieceByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Gold piece not found"})
+		return nil, false
+	}
+
+	userID, _ := c.Get("user_id")
+	if piece.UserID != userID.(uuid.UUID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return nil, false
+	}
+	return piece, true
+}
+
+// pieceError answers a failed piece write
+func (h *GoldHandler) pieceError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, repository.ErrInvalidGoldPiece):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case repository.IsDuplicateError(err):
+		c.JSON(http.StatusConflict, gin.H{"error": "A piece with this certificate number already exists"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
+// certificateNumber trims a certificate number, empty meaning none
+func certificateNumber(value string) *string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+// Alert handlers
+
+func (h *GoldHandler) CreateAlert(c *gin.Context) {
+	var req GoldAlertRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.AlertType == nil || req.Threshold == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "alert_type and threshold are required"})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	alert := &models.GoldPriceAlert{
+		UserID:    userID.(uuid.UUID),
+		PriceKind: models.GoldPriceBuy,
+		IsActive:  true,
+	}
+	if !applyGoldAlertRequest(c, alert, req) {
+		return
+	}
+
+	if err := h.goldRepo.CreateAlert(alert); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create gold price alert"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, alert)
+}
+
+func (h *GoldHandler) GetAlerts(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	alerts, err := h.goldRepo.GetAlertsByUserID(userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get gold price alerts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, alerts)
+}
+
+// UpdateAlert changes an alert and re-arms it
+func (h *GoldHandler) UpdateAlert(c *gin.Context) {
+	alert, ok := h.ownAlert(c)
+	if !ok {
+		return
+	}
+
+	var req GoldAlertRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !applyGoldAlertRequest(c, alert, req) {
+		return
+	}
+
+	if err := h.goldRepo.UpdateAlert(alert); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update gold price alert"})
+		return
+	}
+
+	c.JSON(http.StatusOK, alert)
+}
+
+func (h *GoldHandler) DeleteAlert(c *gin.Context) {
+	alert, ok := h.ownAlert(c)
+	if !ok {
+		return
+	}
+
+	if err := h.goldRepo.DeleteAlert(alert.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete gold price alert"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Gold price alert deleted successfully"})
+}
+
+// ownAlert loads the alert in :id, answering the request itself when it
+// does not exist or belongs to another user
+func (h *GoldHandler) ownAlert(c *gin.Context) (*models.GoldPriceAlert, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid alert ID"})
+		return nil, false
+	}
+
+	alert, err := h.goldRepo.GetAlertByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Gold price alert not found"})
+		return nil, false
+	}
+
+	userID, _ := c.Get("user_id")
+	if alert.UserID != userID.(uuid.UUID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return nil, false
+	}
+	return alert, true
+}
+
+// applyGoldAlertRequest copies the given fields onto alert and validates the
+// result, answering the request itself when it is invalid
+func applyGoldAlertRequest(c *gin.Context, alert *models.GoldPriceAlert, req GoldAlertRequest) bool {
+	if req.AlertType != nil {
+		alert.AlertType = models.GoldAlertType(*req.AlertType)
+	}
+	if req.GoldType != nil {
+		if *req.GoldType == "" {
+			alert.GoldType = nil
+		} else {
+			goldType := models.GoldType(*req.GoldType)
+			alert.GoldType = &goldType
+		}
+	}
+	if req.PriceKind != nil {
+		alert.PriceKind = models.GoldPriceKind(*req.PriceKind)
+	}
+	if req.Threshold != nil {
+		alert.Threshold = *req.Threshold
+	}
+	if req.IsActive != nil {
+		alert.IsActive = *req.IsActive
+	}
+
+	if !models.ValidGoldAlertType(alert.AlertType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "alert_type must be price_above, price_below, daily_change or position_pl"})
+		return false
+	}
+	if alert.GoldType != nil && !models.ValidGoldType(*alert.GoldType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid gold type"})
+		return false
+	}
+	if alert.GoldType == nil && alert.AlertType != models.GoldAlertPositionPL {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "gold_type is required for price alerts"})
+		return false
+	}
+	if alert.AlertType != models.GoldAlertPositionPL && alert.Threshold <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "threshold must be greater than 0"})
+		return false
+	}
+	return true
+}
+
+// Sale handlers
+
+func (h *GoldHandler) Sell(c *gin.Context) {
+	var req SellGoldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	method := models.GoldSaleMethod(req.Method)
+	goldType := models.GoldType(req.GoldType)
+	if goldType != "" && !models.ValidGoldType(goldType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid gold type"})
+		return
+	}
+	if method == models.GoldSaleFIFO && req.WeightGram == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "weight_gram is required for FIFO sales"})
+		return
+	}
+	if method == models.GoldSaleSpecific && len(req.Lots) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "lots are required for specific-lot sales"})
+		return
+	}
+
+	if req.AccountID != nil {
+		account, err := h.accountRepo.GetByID(*req.AccountID)
+		if err != nil || account.UserID != userID.(uuid.UUID) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+			return
+		}
+	}
+
+	saleDate, ok := parseTransactionDate(c, req.SaleDate)
+	if !ok {
+		return
+	}
+
+	sale := &models.GoldSale{
+		UserID:       userID.(uuid.UUID),
+		AccountID:    req.AccountID,
+		SaleDate:     saleDate,
+		WeightGram:   req.WeightGram,
+		PricePerGram: req.PricePerGram,
+		Fee:          req.Fee,
+		Notes:        req.Notes,
+	}
+
+	err := h.goldRepo.CreateSale(sale, method, goldType, req.Lots, h.buybackPrice)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidGoldSale) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sell gold"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, sale)
+}
+
+// buybackPrice is the sale price used when none is given: the current
+// buyback price of the sold lots' brand, scaled by their purity and resale
+// haircut
+func (h *GoldHandler) buybackPrice(lots []models.GoldAsset) (float64, error) {
+	if len(lots) == 0 {
+		return 0, fmt.Errorf("no lots to sell")
+	}
+	for _, lot := range lots {
+		if lot.GoldType != lots[0].GoldType {
+			return 0, fmt.Errorf("lots of different gold types need a price_per_gram")
+		}
+		if lot.ValueFactor() != lots[0].ValueFactor() {
+			return 0, fmt.Errorf("lots of different purity or resale haircut need a price_per_gram")
+		}
+	}
+
+	quotes, err := h.goldRepo.GetLatestQuotes()
+	if err != nil {
+		return 0, err
+	}
+	price := quotes.For(lots[0].GoldType)
+	if price == nil {
+		return 0, fmt.Errorf("no %s price available, give a price_per_gram", lots[0].GoldType)
+	}
+	return math.Round(price.BuybackPricePerGram*lots[0].ValueFactor()*100) / 100, nil
+}
+
+func (h *GoldHandler) GetSales(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	sales, err := h.goldRepo.GetSales(userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get gold sales"})
+		return
+	}
+
+	c.JSON(http.StatusOK, sales)
+}
+
+// Valuation handlers
+
+// maxValuationPoints caps the length of a valuation series
+const maxValuationPoints = 1000
+
+// GetValuation values the gold held at the end of ?date (default today)
+func (h *GoldHandler) GetValuation(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	date, ok := parseTransactionDate(c, c.Query("date"))
+	if !ok {
+		return
+	}
+
+	valuation, err := h.goldRepo.GetValuation(userID.(uuid.UUID), date)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get gold valuation"})
+		return
+	}
+
+	c.JSON(http.StatusOK, valuation)
+}
+
+// GetValuationSeries values the gold position over time. ?interval is daily
+// or monthly (default); ?to defaults to today and ?from to the first
+// purchase for monthly series and to 30 days before ?to for daily ones.
+func (h *GoldHandler) GetValuationSeries(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	interval := models.ValuationInterval(c.DefaultQuery("interval", string(models.ValuationMonthly)))
+	if interval != models.ValuationDaily && interval != models.ValuationMonthly {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "interval must be daily or monthly"})
+		return
+	}
+
+	to, ok := parseTransactionDate(c, c.Query("to"))
+	if !ok {
+		return
+	}
+
+	var from time.Time
+	if c.Query("from") != "" {
+		if from, ok = parseTransactionDate(c, c.Query("from")); !ok {
+			return
+		}
+	} else if interval == models.ValuationDaily {
+		from = to.AddDate(0, 0, -30)
+	} else {
+		first, err := h.goldRepo.GetFirstPurchaseDate(userID.(uuid.UUID))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get gold valuation"})
+			return
+		}
+		from = to.AddDate(-1, 0, 0)
+		if first != nil {
+			from = *first
+		}
+	}
+
+	if from.After(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must not be after to"})
+		return
+	}
+	if len(models.SeriesDates(from, to, interval)) > maxValuationPoints {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Series is limited to %d points", maxValuationPoints)})
+		return
+	}
+
+	points, err := h.goldRepo.GetValuationSeries(userID.(uuid.UUID), from, to, interval)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get gold valuation"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"interval": interval,
+		"from":     from.Format("2006-01-02"),
+		"to":       to.Format("2006-01-02"),
+		"points":   points,
+	})
+}
+
 // Summary
 
 func (h *GoldHandler) GetSummary(c *gin.Context) {
@@ -149,35 +934,88 @@ func (h *GoldHandler) GetSummary(c *gin.Context) {
 		plPercent = (totalPL / totalPurchase) * 100
 	}
 
-	currentPrice, _ := h.goldRepo.GetLatestPrice()
-	var pricePerGram float64
+	realized, err := h.goldRepo.GetRealizedSummary(userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get summary"})
+		return
+	}
+
+	prices, _ := h.goldRepo.GetLatestPrices()
+	if prices == nil {
+		prices = []models.GoldPrice{}
+	}
+	quotes := models.NewGoldQuotes(prices)
+
+	// The headline price is the antam quote, the market's reference; like
+	// price_per_gram on /gold/price it is the buy price, with the buyback
+	// price that holdings are valued at alongside
+	var pricePerGram, buybackPricePerGram float64
 	var priceDate string
-	if currentPrice != nil {
-		pricePerGram = currentPrice.PricePerGram
-		priceDate = currentPrice.PriceDate.Format("2006-01-02")
+	if reference := quotes.For(models.GoldTypeAntam); reference != nil {
+		pricePerGram = reference.BuyPricePerGram
+		buybackPricePerGram = reference.BuybackPricePerGram
+		priceDate = reference.PriceDate.Format("2006-01-02")
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"total_weight_gram":     totalWeight,
-		"total_purchase_value":  totalPurchase,
-		"total_current_value":   totalCurrent,
-		"total_profit_loss":     totalPL,
-		"profit_loss_percent":   plPercent,
-		"current_price_per_gram": pricePerGram,
-		"price_date":            priceDate,
+		"total_weight_gram":              totalWeight,
+		"total_purchase_value":           totalPurchase,
+		"total_current_value":            totalCurrent,
+		"total_profit_loss":              totalPL,
+		"profit_loss_percent":            plPercent,
+		"realized":                       realized,
+		"current_price_per_gram":         pricePerGram,
+		"current_buyback_price_per_gram": buybackPricePerGram,
+		"current_prices":                 prices,
+		"price_date":                     priceDate,
 	})
 }
 
-// Price handlers (for admin)
+// Price handlers (writes are admin only)
 
+// goldPriceResponse - a quote with price_per_gram, the buy price, as it was
+// returned before prices were kept per brand
+type goldPriceResponse struct {
+	models.GoldPrice
+	PricePerGram float64 `json:"price_per_gram"`
+}
+
+// GetLatestPrice returns the latest antam quote (or the quote antam gold is
+// valued at, see GoldQuotes.For), the latest quote of ?gold_type, or with
+// ?all=true the latest quote of every brand
 func (h *GoldHandler) GetLatestPrice(c *gin.Context) {
-	price, err := h.goldRepo.GetLatestPrice()
-	if err != nil {
+	if c.Query("all") == "true" {
+		prices, err := h.goldRepo.GetLatestPrices()
+		if err != nil || len(prices) == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No price data available"})
+			return
+		}
+		c.JSON(http.StatusOK, prices)
+		return
+	}
+
+	if goldType := c.Query("gold_type"); goldType != "" {
+		if !models.ValidGoldType(models.GoldType(goldType)) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid gold type"})
+			return
+		}
+		price, err := h.goldRepo.GetLatestPrice(models.GoldType(goldType))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No price data available"})
+			return
+		}
+		c.JSON(http.StatusOK, goldPriceResponse{GoldPrice: *price, PricePerGram: price.BuyPricePerGram})
+		return
+	}
+
+	quotes, err := h.goldRepo.GetLatestQuotes()
+	reference := quotes.For(models.GoldTypeAntam)
+	if err != nil || reference == nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "No price data available"})
 		return
 	}
 
-	c.JSON(http.StatusOK, price)
+	c.JSON(http.StatusOK, goldPriceResponse{GoldPrice: *reference, PricePerGram: reference.BuyPricePerGram})
 }
 
 func (h *GoldHandler) GetPriceHistory(c *gin.Context) {
@@ -187,7 +1025,13 @@ func (h *GoldHandler) GetPriceHistory(c *gin.Context) {
 		limit = 30
 	}
 
-	prices, err := h.goldRepo.GetPriceHistory(limit)
+	goldType := models.GoldType(c.Query("gold_type"))
+	if goldType != "" && !models.ValidGoldType(goldType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid gold type"})
+		return
+	}
+
+	prices, err := h.goldRepo.GetPriceHistory(goldType, limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get price history"})
 		return
@@ -196,28 +1040,359 @@ func (h *GoldHandler) GetPriceHistory(c *gin.Context) {
 	c.JSON(http.StatusOK, prices)
 }
 
-func (h *GoldHandler) UpdateTodayPrice(c *gin.Context) {
+// GetPriceOHLC returns weekly or monthly (default) open-high-low-close bars
+// of ?gold_type (default antam) from ?from (default a year before ?to) to
+// ?to (default today). ?price_kind is buy (default) or buyback.
+func (h *GoldHandler) GetPriceOHLC(c *gin.Context) {
+	goldType, kind, ok := parsePriceSeries(c)
+	if !ok {
+		return
+	}
+
+	interval := models.GoldPriceInterval(c.DefaultQuery("interval", string(models.GoldPriceMonthly)))
+	if interval != models.GoldPriceWeekly && interval != models.GoldPriceMonthly {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "interval must be weekly or monthly"})
+		return
+	}
+
+	from, to, ok := parsePriceRange(c, 0, -1)
+	if !ok {
+		return
+	}
+
+	bars, err := h.goldRepo.GetPriceOHLC(goldType, kind, interval, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get gold price aggregates"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"gold_type":  goldType,
+		"price_kind": kind,
+		"interval":   interval,
+		"from":       from.Format("2006-01-02"),
+		"to":         to.Format("2006-01-02"),
+		"bars":       bars,
+	})
+}
+
+// GetPriceMovingAverages returns the quotes of ?gold_type with their 7, 30
+// and 90-day moving averages from ?from (default 90 days before ?to) to ?to
+func (h *GoldHandler) GetPriceMovingAverages(c *gin.Context) {
+	goldType, kind, ok := parsePriceSeries(c)
+	if !ok {
+		return
+	}
+
+	from, to, ok := parsePriceRange(c, -90, 0)
+	if !ok {
+		return
+	}
+	if to.Sub(from) > maxValuationPoints*24*time.Hour {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Range is limited to %d days", maxValuationPoints)})
+		return
+	}
+
+	points, err := h.goldRepo.GetPriceMovingAverages(goldType, kind, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get gold price moving averages"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"gold_type":  goldType,
+		"price_kind": kind,
+		"from":       from.Format("2006-01-02"),
+		"to":         to.Format("2006-01-02"),
+		"points":     points,
+	})
+}
+
+// GetPriceStats returns the latest quote of ?gold_type on or before ?date
+// (default today) with its changes over common ranges, plus one from ?from
+// when given, and its 52-week high and low
+func (h *GoldHandler) GetPriceStats(c *gin.Context) {
+	goldType, kind, ok := parsePriceSeries(c)
+	if !ok {
+		return
+	}
+
+	asOf, ok := parseTransactionDate(c, c.Query("date"))
+	if !ok {
+		return
+	}
+
+	var since *time.Time
+	if c.Query("from") != "" {
+		from, ok := parseTransactionDate(c, c.Query("from"))
+		if !ok {
+			return
+		}
+		since = &from
+	}
+
+	stats, err := h.goldRepo.GetPriceStats(goldType, kind, asOf, since)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No price data available"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get gold price statistics"})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// parsePriceSeries reads ?gold_type (default antam) and ?price_kind
+// (default buy) of a price statistics request
+func parsePriceSeries(c *gin.Context) (models.GoldType, models.GoldPriceKind, bool) {
+	goldType := models.GoldType(c.DefaultQuery("gold_type", string(models.GoldTypeAntam)))
+	if !models.ValidGoldType(goldType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid gold type"})
+		return "", "", false
+	}
+
+	kind := models.GoldPriceKind(c.DefaultQuery("price_kind", string(models.GoldPriceBuy)))
+	if kind != models.GoldPriceBuy && kind != models.GoldPriceBuyback {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "price_kind must be buy or buyback"})
+		return "", "", false
+	}
+	return goldType, kind, true
+}
+
+// parsePriceRange reads ?from and ?to. ?to defaults to today and ?from to
+// ?to moved by the given days and years.
+func parsePriceRange(c *gin.Context, defaultDays, defaultYears int) (time.Time, time.Time, bool) {
+	to, ok := parseTransactionDate(c, c.Query("to"))
+	if !ok {
+		return time.Time{}, time.Time{}, false
+	}
+
+	from := to.AddDate(defaultYears, 0, defaultDays)
+	if c.Query("from") != "" {
+		if from, ok = parseTransactionDate(c, c.Query("from")); !ok {
+			return time.Time{}, time.Time{}, false
+		}
+	}
+
+	if from.After(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must not be after to"})
+		return time.Time{}, time.Time{}, false
+	}
+	return from, to, true
+}
+
+// SetPrice sets or corrects the quote of one brand on any past day (admin)
+func (h *GoldHandler) SetPrice(c *gin.Context) {
 	var req UpdateGoldPriceRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
+	goldType := models.GoldType(req.GoldType)
+	if !models.ValidGoldType(goldType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid gold type"})
+		return
+	}
+
+	date, ok := parsePriceDate(c, req.Date)
+	if !ok {
+		return
+	}
+
 	source := req.Source
 	if source == "" {
 		source = "admin"
 	}
 
-	today := time.Now().Truncate(24 * time.Hour)
-	if err := h.goldRepo.UpsertPrice(today, req.PricePerGram, source); err != nil {
+	userID, _ := c.Get("user_id")
+	adminID := userID.(uuid.UUID)
+	price := models.GoldPrice{
+		PriceDate:           date,
+		GoldType:            goldType,
+		BuyPricePerGram:     math.Round(req.BuyPricePerGram*100) / 100,
+		BuybackPricePerGram: math.Round(req.BuybackPricePerGram*100) / 100,
+		Source:              source,
+	}
+	action, err := h.goldRepo.SetPrice(price, &adminID)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update price"})
 		return
 	}
 
+	message := "Price updated successfully"
+	if action == "" {
+		message = "Price unchanged"
+	}
 	c.JSON(http.StatusOK, gin.H{
-		"message":        "Price updated successfully",
-		"date":           today.Format("2006-01-02"),
-		"price_per_gram": req.PricePerGram,
-		"source":         source,
+		"message":                message,
+		"action":                 action,
+		"date":                   date.Format("2006-01-02"),
+		"gold_type":              goldType,
+		"buy_price_per_gram":     price.BuyPricePerGram,
+		"buyback_price_per_gram": price.BuybackPricePerGram,
+		"source":                 source,
 	})
 }
+
+// DeletePrice removes the quote of :gold_type on :date (admin)
+func (h *GoldHandler) DeletePrice(c *gin.Context) {
+	date, err := time.Parse("2006-01-02", c.Param("date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format. Use YYYY-MM-DD"})
+		return
+	}
+
+	goldType := models.GoldType(c.Param("gold_type"))
+	if !models.ValidGoldType(goldType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid gold type"})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	adminID := userID.(uuid.UUID)
+	if err := h.goldRepo.DeletePrice(date, goldType, &adminID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Gold price not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete price"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Gold price deleted successfully"})
+}
+
+// ImportPrices backfills historical quotes from a CSV, sent either as the
+// multipart field "file" or as the request body (admin). The whole file is
+// validated first and nothing is written if any line is invalid.
+func (h *GoldHandler) ImportPrices(c *gin.Context) {
+	var body io.Reader = c.Request.Body
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		header, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "CSV file is required"})
+			return
+		}
+		file, err := header.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read CSV file"})
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+
+	source := c.DefaultQuery("source", "import")
+	prices, importErrors, err := models.ParseGoldPriceCSV(io.LimitReader(body, maxGoldPriceCSVBytes), source)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(importErrors) > 0 {
+		c.JSON(http.StatusBadRequest, models.GoldPriceImportResult{
+			Rows:   len(prices) + len(importErrors),
+			Errors: importErrors,
+		})
+		return
+	}
+	if len(prices) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "CSV has no price rows"})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	adminID := userID.(uuid.UUID)
+	result, err := h.goldRepo.ImportPrices(prices, &adminID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import prices"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetPriceChanges returns the audit trail of price writes (admin), filtered
+// by ?gold_type and ?date
+func (h *GoldHandler) GetPriceChanges(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if limit <= 0 || limit > 1000 {
+		limit = 100
+	}
+
+	goldType := models.GoldType(c.Query("gold_type"))
+	if goldType != "" && !models.ValidGoldType(goldType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid gold type"})
+		return
+	}
+
+	var priceDate *time.Time
+	if value := c.Query("date"); value != "" {
+		date, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format. Use YYYY-MM-DD"})
+			return
+		}
+		priceDate = &date
+	}
+
+	changes, err := h.goldRepo.GetPriceChanges(goldType, priceDate, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get price changes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, changes)
+}
+
+// maxGoldPriceCSVBytes bounds the size of an uploaded price CSV
+const maxGoldPriceCSVBytes = 5 << 20
+
+// parsePriceDate parses a quote date, defaulting to today's local date.
+// Quotes cannot be set for future days.
+func parsePriceDate(c *gin.Context, value string) (time.Time, bool) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if value == "" {
+		return today, true
+	}
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format. Use YYYY-MM-DD"})
+		return time.Time{}, false
+	}
+	if date.After(today) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Price date cannot be in the future"})
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+// FetchPrices pulls the prices of a day (default today) from the configured
+// providers right away instead of waiting for the schedule. Provider errors
+// are logged, not returned.
+func (h *GoldHandler) FetchPrices(c *gin.Context) {
+	var req FetchGoldPriceRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	date, ok := parseTransactionDate(c, req.Date)
+	if !ok {
+		return
+	}
+
+	result, err := h.goldFetcher.Fetch(c.Request.Context(), date)
+	if err != nil {
+		log.Printf("Manual gold price fetch failed: %v", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to fetch gold prices from the configured providers"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,262 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/financial-tracker/backend/internal/alerts"
+	"github.com/financial-tracker/backend/internal/models"
+	"github.com/financial-tracker/backend/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type PaylaterHandler struct {
+	paylaterRepo  *repository.PaylaterRepository
+	accountRepo   *repository.AccountRepository
+	cardRepo      *repository.CreditCardRepository
+	budgetAlerter *alerts.BudgetAlerter
+}
+
+func NewPaylaterHandler(paylaterRepo *repository.PaylaterRepository, accountRepo *repository.AccountRepository, cardRepo *repository.CreditCardRepository, budgetAlerter *alerts.BudgetAlerter) *PaylaterHandler {
+	return &PaylaterHandler{
+		paylaterRepo:  paylaterRepo,
+		accountRepo:   accountRepo,
+		cardRepo:      cardRepo,
+		budgetAlerter: budgetAlerter,
+	}
+}
+
+type CreatePaylaterPurchaseRequest struct {
+	Description       string  `json:"description" binding:"required"`
+	Category          string  `json:"category" binding:"required"`
+	Principal         float64 `json:"principal" binding:"required,gt=0"`
+	TenorMonths       int     `json:"tenor_months" binding:"required,min=1,max=24"`
+	FeePercentMonthly float64 `json:"fee_percent_monthly" binding:"gte=0"`
+	AdminFee          float64 `json:"admin_fee" binding:"gte=0"`
+	PurchaseDate      string  `json:"purchase_date"`
+}
+
+type PayPaylaterInstallmentRequest struct {
+	AccountID   string `json:"account_id" binding:"required"`
+	PaymentDate string `json:"payment_date"`
+}
+
+// getOwnedPaylaterAccount loads the :id account and checks that it belongs
+// to the user and is a paylater account
+func (h *PaylaterHandler) getOwnedPaylaterAccount(c *gin.Context) (*models.Account, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid account ID"})
+		return nil, false
+	}
+
+	account, err := h.accountRepo.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+		return nil, false
+	}
+
+	userID, _ := c.Get("user_id")
+	if account.UserID != userID.(uuid.UUID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return nil, false
+	}
+
+	if !account.IsLiability() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Account is not a paylater account"})
+		return nil, false
+	}
+
+	return account, true
+}
+
+// CreatePurchase books a purchase split into monthly installments. The whole
+// obligation (principal and fees) must fit within the available credit.
+func (h *PaylaterHandler) CreatePurchase(c *gin.Context) {
+	account, ok := h.getOwnedPaylaterAccount(c)
+	if !ok {
+		return
+	}
+
+	var req CreatePaylaterPurchaseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	purchaseDate, ok := parseTransactionDate(c, req.PurchaseDate)
+	if !ok {
+		return
+	}
+
+	purchase := &models.PaylaterPurchase{
+		UserID:            account.UserID,
+		AccountID:         account.ID,
+		Description:       req.Description,
+		Category:          req.Category,
+		Principal:         req.Principal,
+		TenorMonths:       req.TenorMonths,
+		FeePercentMonthly: req.FeePercentMonthly,
+		AdminFee:          req.AdminFee,
+		PurchaseDate:      purchaseDate,
+	}
+
+	dueDay := purchaseDate.Day()
+	if account.DueDay != nil {
+		dueDay = *account.DueDay
+	}
+
+	obligation := 0.0
+	for _, inst := range purchase.Schedule(dueDay) {
+		obligation += inst.Amount
+	}
+	if account.AvailableCredit != nil && obligation > *account.AvailableCredit {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":            "Purchase exceeds available credit",
+			"available_credit": *account.AvailableCredit,
+		})
+		return
+	}
+
+	if err := h.paylaterRepo.CreatePurchase(purchase, dueDay); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create paylater purchase"})
+		return
+	}
+
+	for _, category := range []string{purchase.Category, "Paylater Fees"} {
+		if err := h.budgetAlerter.Evaluate(account.UserID, category, purchaseDate); err != nil {
+			log.Printf("Failed to evaluate budget alerts: %v", err)
+		}
+	}
+
+	c.JSON(http.StatusCreated, purchase)
+}
+
+func (h *PaylaterHandler) GetPurchases(c *gin.Context) {
+	account, ok := h.getOwnedPaylaterAccount(c)
+	if !ok {
+		return
+	}
+
+	purchases, err := h.paylaterRepo.GetPurchases(account.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get paylater purchases"})
+		return
+	}
+
+	c.JSON(http.StatusOK, purchases)
+}
+
+// GetUpcoming lists unpaid installments due within ?days (default 30),
+// including overdue ones
+func (h *PaylaterHandler) GetUpcoming(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	days, err := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if err != nil || days < 0 || days > 366 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "days must be between 0 and 366"})
+		return
+	}
+
+	now := time.Now()
+	upcoming, err := h.paylaterRepo.GetUpcoming(userID.(uuid.UUID), now.AddDate(0, 0, days), now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get upcoming installments"})
+		return
+	}
+
+	total := 0.0
+	for _, u := range upcoming {
+		total += u.Amount
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"installments": upcoming,
+		"total_due":    total,
+	})
+}
+
+// PayInstallment pays one installment from a bank account
+func (h *PaylaterHandler) PayInstallment(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid installment ID"})
+		return
+	}
+
+	inst, err := h.paylaterRepo.GetInstallmentByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Installment not found"})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if inst.UserID != userID.(uuid.UUID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return
+	}
+	if inst.PaidAt != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Installment already paid"})
+		return
+	}
+
+	var req PayPaylaterInstallmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	accountID, err := uuid.Parse(req.AccountID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid account ID"})
+		return
+	}
+	account, err := h.accountRepo.GetByID(accountID)
+	if err != nil || account.UserID != inst.UserID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+		return
+	}
+	if account.IsLiability() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Installments cannot be paid from a paylater account"})
+		return
+	}
+
+	paidAt, ok := parseTransactionDate(c, req.PaymentDate)
+	if !ok {
+		return
+	}
+
+	transfer, err := h.paylaterRepo.PayInstallment(inst, account.ID, paidAt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to pay installment"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"installment": inst,
+		"transfer":    transfer,
+	})
+}
+
+// GetBalanceSheet reports assets, liabilities (paylater and credit cards)
+// and net worth
+func (h *PaylaterHandler) GetBalanceSheet(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	cards, err := h.cardRepo.GetByUserID(userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get credit cards"})
+		return
+	}
+
+	sheet, err := h.paylaterRepo.GetBalanceSheet(userID.(uuid.UUID), cards, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get balance sheet"})
+		return
+	}
+
+	c.JSON(http.StatusOK, sheet)
+}
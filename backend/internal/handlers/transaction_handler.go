@@ -1,10 +1,12 @@
 package handlers
 
 import (
+	"log"
 	"net/http"
 	"strconv"
 	"time"
 
+	"github.com/financial-tracker/backend/internal/alerts"
 	"github.com/financial-tracker/backend/internal/models"
 	"github.com/financial-tracker/backend/internal/repository"
 	"github.com/gin-gonic/gin"
@@ -14,15 +16,24 @@ import (
 type TransactionHandler struct {
 	transactionRepo *repository.TransactionRepository
 	accountRepo     *repository.AccountRepository
+	budgetAlerter   *alerts.BudgetAlerter
 }
 
-func NewTransactionHandler(transactionRepo *repository.TransactionRepository, accountRepo *repository.AccountRepository) *TransactionHandler {
+func NewTransactionHandler(transactionRepo *repository.TransactionRepository, accountRepo *repository.AccountRepository, budgetAlerter *alerts.BudgetAlerter) *TransactionHandler {
 	return &TransactionHandler{
 		transactionRepo: transactionRepo,
 		accountRepo:     accountRepo,
+		budgetAlerter:   budgetAlerter,
 	}
 }
 
+type UpdateTransactionRequest struct {
+	Category        string  `json:"category"`
+	Amount          float64 `json:"amount" binding:"omitempty,gt=0"`
+	Description     *string `json:"description"`
+	TransactionDate string  `json:"transaction_date"`
+}
+
 func (h *TransactionHandler) Create(c *gin.Context) {
 	var req models.CreateTransactionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -49,6 +60,22 @@ func (h *TransactionHandler) Create(c *gin.Context) {
 		transactionDate = time.Now()
 	}
 
+	// A paylater account cannot be spent past its credit limit
+	if req.Type == models.TransactionTypeExpense {
+		account, err := h.accountRepo.GetByID(accountID)
+		if err != nil || account.UserID != userID.(uuid.UUID) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+			return
+		}
+		if account.AvailableCredit != nil && req.Amount > *account.AvailableCredit {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":            "Expense exceeds available credit",
+				"available_credit": *account.AvailableCredit,
+			})
+			return
+		}
+	}
+
 	transaction := &models.Transaction{
 		UserID:          userID.(uuid.UUID),
 		AccountID:       accountID,
@@ -75,6 +102,8 @@ func (h *TransactionHandler) Create(c *gin.Context) {
 		h.accountRepo.Update(account)
 	}
 
+	h.evaluateBudgetAlerts(transaction)
+
 	c.JSON(http.StatusCreated, transaction)
 }
 
@@ -128,6 +157,84 @@ func (h *TransactionHandler) GetByID(c *gin.Context) {
 	c.JSON(http.StatusOK, transaction)
 }
 
+func (h *TransactionHandler) Update(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid transaction ID"})
+		return
+	}
+
+	transaction, err := h.transactionRepo.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Transaction not found"})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if transaction.UserID != userID.(uuid.UUID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return
+	}
+
+	var req UpdateTransactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	oldAmount := transaction.Amount
+	if req.Category != "" {
+		transaction.Category = req.Category
+	}
+	if req.Amount > 0 {
+		transaction.Amount = req.Amount
+	}
+	if req.Description != nil {
+		transaction.Description = *req.Description
+	}
+	if req.TransactionDate != "" {
+		transactionDate, err := time.Parse("2006-01-02", req.TransactionDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format. Use YYYY-MM-DD"})
+			return
+		}
+		transaction.TransactionDate = transactionDate
+	}
+
+	if delta := transaction.Amount - oldAmount; delta > 0 && transaction.Type == models.TransactionTypeExpense {
+		account, err := h.accountRepo.GetByID(transaction.AccountID)
+		if err == nil && account.AvailableCredit != nil && delta > *account.AvailableCredit {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":            "Expense exceeds available credit",
+				"available_credit": *account.AvailableCredit,
+			})
+			return
+		}
+	}
+
+	if err := h.transactionRepo.Update(transaction); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update transaction"})
+		return
+	}
+
+	// Apply the amount difference to the account balance
+	if delta := transaction.Amount - oldAmount; delta != 0 {
+		account, err := h.accountRepo.GetByID(transaction.AccountID)
+		if err == nil {
+			if transaction.Type == models.TransactionTypeIncome {
+				account.Balance += delta
+			} else if transaction.Type == models.TransactionTypeExpense {
+				account.Balance -= delta
+			}
+			h.accountRepo.Update(account)
+		}
+	}
+
+	h.evaluateBudgetAlerts(transaction)
+
+	c.JSON(http.StatusOK, transaction)
+}
+
 func (h *TransactionHandler) Delete(c *gin.Context) {
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
@@ -156,8 +263,30 @@ func (h *TransactionHandler) Delete(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Transaction deleted successfully"})
 }
 
+// GetSummary returns all-time totals, or the totals of one financial month
+// when month and year are given
 func (h *TransactionHandler) GetSummary(c *gin.Context) {
 	userID, _ := c.Get("user_id")
+
+	monthStr := c.Query("month")
+	yearStr := c.Query("year")
+	if monthStr != "" && yearStr != "" {
+		month, err1 := strconv.Atoi(monthStr)
+		year, err2 := strconv.Atoi(yearStr)
+		if err1 != nil || err2 != nil || month < 1 || month > 12 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid month or year"})
+			return
+		}
+
+		summary, err := h.transactionRepo.GetMonthlySummary(userID.(uuid.UUID), month, year)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get summary"})
+			return
+		}
+		c.JSON(http.StatusOK, summary)
+		return
+	}
+
 	summary, err := h.transactionRepo.GetSummary(userID.(uuid.UUID))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get summary"})
@@ -166,3 +295,14 @@ func (h *TransactionHandler) GetSummary(c *gin.Context) {
 
 	c.JSON(http.StatusOK, summary)
 }
+
+// evaluateBudgetAlerts fires budget threshold notifications for an expense.
+// Failures are logged only; they must not fail the transaction request.
+func (h *TransactionHandler) evaluateBudgetAlerts(transaction *models.Transaction) {
+	if transaction.Type != models.TransactionTypeExpense {
+		return
+	}
+	if err := h.budgetAlerter.Evaluate(transaction.UserID, transaction.Category, transaction.TransactionDate); err != nil {
+		log.Printf("Failed to evaluate budget alerts: %v", err)
+	}
+}
@@ -0,0 +1,345 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/financial-tracker/backend/internal/alerts"
+	"github.com/financial-tracker/backend/internal/models"
+	"github.com/financial-tracker/backend/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ZakatHandler struct {
+	zakatRepo     *repository.ZakatRepository
+	goldRepo      *repository.GoldRepository
+	accountRepo   *repository.AccountRepository
+	budgetRepo    *repository.BudgetRepository
+	paylaterRepo  *repository.PaylaterRepository
+	cardRepo      *repository.CreditCardRepository
+	budgetAlerter *alerts.BudgetAlerter
+}
+
+func NewZakatHandler(zakatRepo *repository.ZakatRepository, goldRepo *repository.GoldRepository, accountRepo *repository.AccountRepository, budgetRepo *repository.BudgetRepository, paylaterRepo *repository.PaylaterRepository, cardRepo *repository.CreditCardRepository, budgetAlerter *alerts.BudgetAlerter) *ZakatHandler {
+	return &ZakatHandler{
+		zakatRepo:     zakatRepo,
+		goldRepo:      goldRepo,
+		accountRepo:   accountRepo,
+		budgetRepo:    budgetRepo,
+		paylaterRepo:  paylaterRepo,
+		cardRepo:      cardRepo,
+		budgetAlerter: budgetAlerter,
+	}
+}
+
+// CreateZakatPaymentRequest - month is required for zakat penghasilan and
+// not allowed for zakat mal. With account_id the amount is booked as an
+// expense from that account.
+type CreateZakatPaymentRequest struct {
+	ZakatType string     `json:"zakat_type" binding:"required,oneof=mal penghasilan"`
+	Year      int        `json:"year" binding:"required,min=2000,max=2100"`
+	Month     *int       `json:"month" binding:"omitempty,min=1,max=12"`
+	Amount    float64    `json:"amount" binding:"required,gt=0"`
+	AccountID *uuid.UUID `json:"account_id"`
+	PaidAt    string     `json:"paid_at"`
+	Notes     string     `json:"notes"`
+}
+
+// defaultIncomeExclusions are income categories that are not earned income
+var defaultIncomeExclusions = []string{"Gold Sale"}
+
+// GetMal computes zakat mal on ?date (default today): cash in asset
+// accounts and gold at market value, plus ?receivables, minus ?debts and,
+// with ?include_liabilities=true (today only), the paylater and credit card
+// debt. The haul is checked on the cash and gold held over the lunar year
+// before.
+// ?nisab_gold_type picks the brand pricing the nisab (default antam).
+func (h *ZakatHandler) GetMal(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uid := userID.(uuid.UUID)
+
+	day, ok := parseTransactionDate(c, c.Query("date"))
+	if !ok {
+		return
+	}
+	day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+
+	receivables, ok := parseAmountQuery(c, "receivables")
+	if !ok {
+		return
+	}
+	debts, ok := parseAmountQuery(c, "debts")
+	if !ok {
+		return
+	}
+
+	nisab, ok := h.nisabOn(c, day)
+	if !ok {
+		return
+	}
+
+	from := day.AddDate(0, 0, -models.HaulDays)
+	history, err := h.zakatRepo.GetCashHistory(uid, from, day)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get account history"})
+		return
+	}
+	gold, err := h.goldRepo.GetValuationSeries(uid, from, day, models.ValuationDaily)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get gold valuation"})
+		return
+	}
+	for i := range history {
+		if i < len(gold) {
+			history[i].GoldValue = gold[i].MarketValue
+		}
+	}
+
+	if c.Query("include_liabilities") == "true" {
+		// The balance sheet holds today's debt, which cannot be rebuilt for
+		// a past day
+		now := time.Now()
+		if day.Before(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "include_liabilities cannot be used with a past date"})
+			return
+		}
+		cards, err := h.cardRepo.GetByUserID(uid)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get credit cards"})
+			return
+		}
+		sheet, err := h.paylaterRepo.GetBalanceSheet(uid, cards, day)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get balance sheet"})
+			return
+		}
+		debts += sheet.TotalLiabilities
+	}
+
+	paid, err := h.zakatRepo.GetPaid(uid, models.ZakatMal, day.Year(), 0)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get zakat payments"})
+		return
+	}
+
+	today := history[len(history)-1]
+	report := &models.ZakatMalReport{
+		Date:        day,
+		Year:        day.Year(),
+		Nisab:       nisab,
+		Cash:        today.Cash,
+		GoldValue:   today.GoldValue,
+		Receivables: receivables,
+		Debts:       debts,
+		Haul:        models.HaulOf(history, nisab.Value),
+		Paid:        paid,
+	}
+	if len(gold) > 0 {
+		report.GoldWeightGram = gold[len(gold)-1].WeightGram
+	}
+	report.Calculate()
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetPenghasilan computes zakat penghasilan on the income of financial
+// month ?month/?year (default the current one). ?exclude_categories lists
+// income categories to leave out, by default Gold Sale.
+func (h *ZakatHandler) GetPenghasilan(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uid := userID.(uuid.UUID)
+
+	month, year, err := h.budgetRepo.MonthOf(uid, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get financial month"})
+		return
+	}
+	if value := c.Query("month"); value != "" {
+		if month, err = strconv.Atoi(value); err != nil || month < 1 || month > 12 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "month must be between 1 and 12"})
+			return
+		}
+	}
+	if value := c.Query("year"); value != "" {
+		if year, err = strconv.Atoi(value); err != nil || year < 2000 || year > 2100 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid year"})
+			return
+		}
+	}
+
+	exclude := defaultIncomeExclusions
+	if value, ok := c.GetQuery("exclude_categories"); ok {
+		exclude = []string{}
+		for _, category := range strings.Split(value, ",") {
+			if category = strings.TrimSpace(category); category != "" {
+				exclude = append(exclude, category)
+			}
+		}
+	}
+
+	income, start, end, err := h.zakatRepo.GetMonthlyIncome(uid, month, year, exclude)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get income"})
+		return
+	}
+
+	// Price the nisab at the end of the month, or today while it is running
+	nisabDay := end
+	if now := time.Now(); nisabDay.After(now) {
+		nisabDay = now
+	}
+	nisab, ok := h.nisabOn(c, nisabDay)
+	if !ok {
+		return
+	}
+
+	paid, err := h.zakatRepo.GetPaid(uid, models.ZakatPenghasilan, year, month)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get zakat payments"})
+		return
+	}
+
+	report := &models.ZakatPenghasilanReport{
+		Month:       month,
+		Year:        year,
+		PeriodStart: start,
+		PeriodEnd:   end,
+		Income:      income,
+		Nisab:       nisab,
+		Paid:        paid,
+	}
+	report.Calculate()
+
+	c.JSON(http.StatusOK, report)
+}
+
+// CreatePayment records zakat paid for a year (mal) or month (penghasilan)
+func (h *ZakatHandler) CreatePayment(c *gin.Context) {
+	var req CreateZakatPaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	zakatType := models.ZakatType(req.ZakatType)
+	if zakatType == models.ZakatPenghasilan && req.Month == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "month is required for zakat penghasilan"})
+		return
+	}
+	if zakatType == models.ZakatMal && req.Month != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "month is only used for zakat penghasilan"})
+		return
+	}
+
+	paidAt, ok := parseTransactionDate(c, req.PaidAt)
+	if !ok {
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if req.AccountID != nil {
+		account, err := h.accountRepo.GetByID(*req.AccountID)
+		if err != nil || account.UserID != userID.(uuid.UUID) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+			return
+		}
+		if account.AvailableCredit != nil && req.Amount > *account.AvailableCredit {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":            "Payment exceeds available credit",
+				"available_credit": *account.AvailableCredit,
+			})
+			return
+		}
+	}
+
+	payment := &models.ZakatPayment{
+		UserID:      userID.(uuid.UUID),
+		ZakatType:   zakatType,
+		PeriodYear:  req.Year,
+		PeriodMonth: req.Month,
+		Amount:      req.Amount,
+		AccountID:   req.AccountID,
+		PaidAt:      paidAt,
+		Notes:       req.Notes,
+	}
+	if err := h.zakatRepo.CreatePayment(payment); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record zakat payment"})
+		return
+	}
+
+	if payment.AccountID != nil {
+		if err := h.budgetAlerter.Evaluate(payment.UserID, "Zakat", paidAt); err != nil {
+			log.Printf("Failed to evaluate budget alerts: %v", err)
+		}
+	}
+
+	c.JSON(http.StatusCreated, payment)
+}
+
+// GetPayments lists zakat payments, filtered by ?zakat_type and ?year
+func (h *ZakatHandler) GetPayments(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	zakatType := models.ZakatType(c.Query("zakat_type"))
+	if zakatType != "" && zakatType != models.ZakatMal && zakatType != models.ZakatPenghasilan {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "zakat_type must be mal or penghasilan"})
+		return
+	}
+
+	year := 0
+	if value := c.Query("year"); value != "" {
+		var err error
+		if year, err = strconv.Atoi(value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid year"})
+			return
+		}
+	}
+
+	payments, err := h.zakatRepo.GetPayments(userID.(uuid.UUID), zakatType, year)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get zakat payments"})
+		return
+	}
+
+	c.JSON(http.StatusOK, payments)
+}
+
+// nisabOn prices the nisab with the ?nisab_gold_type (default antam) quote
+// on or before day
+func (h *ZakatHandler) nisabOn(c *gin.Context, day time.Time) (models.ZakatNisab, bool) {
+	goldType := models.GoldType(c.DefaultQuery("nisab_gold_type", string(models.GoldTypeAntam)))
+	if !models.ValidGoldType(goldType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid gold type"})
+		return models.ZakatNisab{}, false
+	}
+
+	quote, err := h.goldRepo.GetPriceBefore(goldType, day.AddDate(0, 0, 1))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get gold price"})
+		return models.ZakatNisab{}, false
+	}
+	if quote == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("No %s gold price to compute the nisab", goldType)})
+		return models.ZakatNisab{}, false
+	}
+	return models.NewZakatNisab(*quote), true
+}
+
+// parseAmountQuery reads an optional non-negative amount from the query
+func parseAmountQuery(c *gin.Context, name string) (float64, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return 0, true
+	}
+	amount, err := strconv.ParseFloat(value, 64)
+	if err != nil || amount < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%s must be a non-negative amount", name)})
+		return 0, false
+	}
+	return amount, true
+}
@@ -23,10 +23,29 @@ type Account struct {
 	Balance         float64     `db:"balance" json:"balance"`
 	Currency        string      `db:"currency" json:"currency"`
 	ParentAccountID *uuid.UUID  `db:"parent_account_id" json:"parent_account_id,omitempty"`
-	CreatedAt       time.Time   `db:"created_at" json:"created_at"`
-	UpdatedAt       time.Time   `db:"updated_at" json:"updated_at"`
+	// Paylater only - the most that may be owed and the monthly due day
+	CreditLimit *float64  `db:"credit_limit" json:"credit_limit,omitempty"`
+	DueDay      *int      `db:"due_day" json:"due_day,omitempty"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
 	// For response only - child accounts (pockets)
 	SubAccounts []Account `db:"-" json:"sub_accounts,omitempty"`
+	// For response only - credit limit minus what is owed
+	AvailableCredit *float64 `db:"-" json:"available_credit,omitempty"`
+}
+
+// IsLiability reports whether the account holds debt (a negative balance is owed)
+func (a *Account) IsLiability() bool {
+	return a.Type == AccountTypePaylater
+}
+
+// SetAvailableCredit fills AvailableCredit for paylater accounts with a limit
+func (a *Account) SetAvailableCredit() {
+	if !a.IsLiability() || a.CreditLimit == nil {
+		return
+	}
+	available := *a.CreditLimit + a.Balance
+	a.AvailableCredit = &available
 }
 
 type CreateAccountRequest struct {
@@ -34,6 +53,9 @@ type CreateAccountRequest struct {
 	Type            AccountType `json:"type" binding:"required"`
 	Currency        string      `json:"currency"`
 	ParentAccountID *uuid.UUID  `json:"parent_account_id,omitempty"`
+	// Required for paylater accounts
+	CreditLimit *float64 `json:"credit_limit" binding:"omitempty,gt=0"`
+	DueDay      *int     `json:"due_day" binding:"omitempty,min=1,max=31"`
 }
 
 type CreateSubAccountRequest struct {
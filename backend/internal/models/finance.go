@@ -1,35 +1,234 @@
 package models
 
 import (
+	"math"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
-// Budget - simplified with month/year instead of date range
+// BudgetPeriod - length of the period a budget covers
+type BudgetPeriod string
+
+const (
+	BudgetPeriodWeekly  BudgetPeriod = "weekly"
+	BudgetPeriodMonthly BudgetPeriod = "monthly"
+	BudgetPeriodYearly  BudgetPeriod = "yearly"
+)
+
+// RolloverMode - what happens to a budget's leftover at the end of its period
+type RolloverMode string
+
+const (
+	RolloverNone              RolloverMode = "none"
+	RolloverSurplus           RolloverMode = "surplus"
+	RolloverSurplusAndDeficit RolloverMode = "surplus_and_deficit"
+)
+
+// Budget - simplified with month/year instead of date range. Monthly budgets
+// use BudgetMonth 1-12; yearly budgets only use BudgetYear; weekly budgets use
+// the ISO week in BudgetWeek with BudgetYear as the ISO year.
+// AlertThresholds are percentages of the available budget that trigger a notification.
 type Budget struct {
-	ID          uuid.UUID `db:"id" json:"id"`
-	UserID      uuid.UUID `db:"user_id" json:"user_id"`
-	Category    string    `db:"category" json:"category"`
-	Amount      float64   `db:"amount" json:"amount"`
-	BudgetMonth int       `db:"budget_month" json:"budget_month"`
-	BudgetYear  int       `db:"budget_year" json:"budget_year"`
-	CreatedAt   time.Time `db:"created_at" json:"created_at"`
-	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+	ID                uuid.UUID     `db:"id" json:"id"`
+	UserID            uuid.UUID     `db:"user_id" json:"user_id"`
+	Category          string        `db:"category" json:"category"`
+	Amount            float64       `db:"amount" json:"amount"`
+	Period            BudgetPeriod  `db:"period" json:"period"`
+	BudgetMonth       int           `db:"budget_month" json:"budget_month"`
+	BudgetYear        int           `db:"budget_year" json:"budget_year"`
+	BudgetWeek        int           `db:"budget_week" json:"budget_week,omitempty"`
+	RolloverMode      RolloverMode  `db:"rollover_mode" json:"rollover_mode"`
+	RolloverCap       *float64      `db:"rollover_cap" json:"rollover_cap,omitempty"`
+	AlertThresholds   pq.Int64Array `db:"alert_thresholds" json:"alert_thresholds"`
+	EnvelopeAccountID *uuid.UUID    `db:"envelope_account_id" json:"envelope_account_id,omitempty"`
+	CreatedAt         time.Time     `db:"created_at" json:"created_at"`
+	UpdatedAt         time.Time     `db:"updated_at" json:"updated_at"`
 }
 
 type CreateBudgetRequest struct {
-	Category    string  `json:"category" binding:"required"`
-	Amount      float64 `json:"amount" binding:"required"`
-	BudgetMonth int     `json:"budget_month" binding:"required,min=1,max=12"`
-	BudgetYear  int     `json:"budget_year" binding:"required,min=2020"`
+	Category     string       `json:"category" binding:"required"`
+	Amount       float64      `json:"amount" binding:"required"`
+	Period       BudgetPeriod `json:"period" binding:"omitempty,oneof=weekly monthly yearly"`
+	BudgetMonth  int          `json:"budget_month" binding:"omitempty,min=1,max=12"`
+	BudgetYear   int          `json:"budget_year" binding:"required,min=2020"`
+	BudgetWeek   int          `json:"budget_week" binding:"omitempty,min=1,max=53"`
+	RolloverMode RolloverMode `json:"rollover_mode" binding:"omitempty,oneof=none surplus surplus_and_deficit"`
+	RolloverCap  *float64     `json:"rollover_cap" binding:"omitempty,gte=0"`
+	// Pocket (sub-account) holding this budget's money in zero-based mode
+	EnvelopeAccountID *uuid.UUID `json:"envelope_account_id"`
 }
 
 type CopyBudgetRequest struct {
-	FromMonth int `json:"from_month" binding:"required,min=1,max=12"`
-	FromYear  int `json:"from_year" binding:"required,min=2020"`
-	ToMonth   int `json:"to_month" binding:"required,min=1,max=12"`
-	ToYear    int `json:"to_year" binding:"required,min=2020"`
+	FromMonth    int     `json:"from_month" binding:"omitempty,min=1,max=12"`
+	FromYear     int     `json:"from_year" binding:"omitempty,min=2020"`
+	ToMonth      int     `json:"to_month" binding:"omitempty,min=1,max=12"`
+	ToYear       int     `json:"to_year" binding:"omitempty,min=2020"`
+	Overwrite    bool    `json:"overwrite"`
+	ScalePercent float64 `json:"scale_percent" binding:"gte=-100"`
+}
+
+// BudgetCopyOptions - how CopyFromMonth treats existing budgets and amounts
+type BudgetCopyOptions struct {
+	// Overwrite replaces budgets that already exist in the target month instead of skipping them
+	Overwrite bool
+	// ScalePercent adjusts every copied amount, e.g. 5 for +5% inflation
+	ScalePercent float64
+}
+
+type BudgetCopyStatus string
+
+const (
+	BudgetCopyCopied      BudgetCopyStatus = "copied"
+	BudgetCopyOverwritten BudgetCopyStatus = "overwritten"
+	BudgetCopySkipped     BudgetCopyStatus = "skipped"
+	BudgetCopyFailed      BudgetCopyStatus = "failed"
+)
+
+// BudgetCopyResult - outcome of writing one category's budget when copying
+// a month or applying suggestions
+type BudgetCopyResult struct {
+	Category string           `json:"category"`
+	Amount   float64          `json:"amount"`
+	Status   BudgetCopyStatus `json:"status"`
+	Error    string           `json:"error,omitempty"`
+}
+
+// BudgetProgress - budget vs. actual spending for one category.
+// Available is the budget amount plus whatever rolled over from the previous period.
+type BudgetProgress struct {
+	BudgetID       uuid.UUID `json:"budget_id"`
+	Category       string    `json:"category"`
+	Amount         float64   `json:"amount"`
+	Rollover       float64   `json:"rollover"`
+	Available      float64   `json:"available"`
+	Spent          float64   `json:"spent"`
+	Remaining      float64   `json:"remaining"`
+	PercentUsed    float64   `json:"percent_used"`
+	DailyBurnRate  float64   `json:"daily_burn_rate"`
+	ProjectedSpend float64   `json:"projected_spend"`
+}
+
+// ProratedBudgetProgress - a yearly budget seen from one month. MonthlyShare
+// is a twelfth of what is available for the year; ExpectedToDate is the share
+// of the year's budget that "belongs" to the months up to and including this one.
+type ProratedBudgetProgress struct {
+	BudgetID         uuid.UUID `json:"budget_id"`
+	Category         string    `json:"category"`
+	AnnualAmount     float64   `json:"annual_amount"`
+	Rollover         float64   `json:"rollover"`
+	Available        float64   `json:"available"`
+	MonthlyShare     float64   `json:"monthly_share"`
+	SpentThisMonth   float64   `json:"spent_this_month"`
+	SpentYearToDate  float64   `json:"spent_year_to_date"`
+	ExpectedToDate   float64   `json:"expected_to_date"`
+	RemainingForYear float64   `json:"remaining_for_year"`
+	PercentUsed      float64   `json:"percent_used"`
+}
+
+// UnbudgetedCategory - spending in a category without a budget for the period
+type UnbudgetedCategory struct {
+	Category string  `json:"category"`
+	Spent    float64 `json:"spent"`
+}
+
+// BudgetProgressReport - progress of all budgets of one period.
+// PeriodEnd is the last day of the period. Monthly reports also list the
+// year's yearly budgets prorated to the month.
+type BudgetProgressReport struct {
+	Period         BudgetPeriod             `json:"period"`
+	Month          int                      `json:"month,omitempty"`
+	Year           int                      `json:"year"`
+	Week           int                      `json:"week,omitempty"`
+	PeriodStart    time.Time                `json:"period_start"`
+	PeriodEnd      time.Time                `json:"period_end"`
+	DaysInPeriod   int                      `json:"days_in_period"`
+	DaysElapsed    int                      `json:"days_elapsed"`
+	TotalBudgeted  float64                  `json:"total_budgeted"`
+	TotalRollover  float64                  `json:"total_rollover"`
+	TotalSpent     float64                  `json:"total_spent"`
+	TotalRemaining float64                  `json:"total_remaining"`
+	Budgets        []BudgetProgress         `json:"budgets"`
+	Yearly         []ProratedBudgetProgress `json:"yearly,omitempty"`
+	Unbudgeted     []UnbudgetedCategory     `json:"unbudgeted"`
+}
+
+// Envelope - one budget seen as an envelope of assigned money in zero-based mode.
+// EnvelopeBalance is the balance of the backing pocket, when there is one.
+type Envelope struct {
+	BudgetID          uuid.UUID  `json:"budget_id"`
+	Category          string     `json:"category"`
+	Assigned          float64    `json:"assigned"`
+	Rollover          float64    `json:"rollover"`
+	Available         float64    `json:"available"`
+	Spent             float64    `json:"spent"`
+	Remaining         float64    `json:"remaining"`
+	Overspent         bool       `json:"overspent"`
+	EnvelopeAccountID *uuid.UUID `json:"envelope_account_id,omitempty"`
+	EnvelopeBalance   *float64   `json:"envelope_balance,omitempty"`
+}
+
+// ZeroBasedReport - a financial month's income and how much of it has been
+// given a job. ToBeAssigned is negative when more was assigned than earned.
+type ZeroBasedReport struct {
+	Month              int                  `json:"month"`
+	Year               int                  `json:"year"`
+	PeriodStart        time.Time            `json:"period_start"`
+	PeriodEnd          time.Time            `json:"period_end"`
+	Income             float64              `json:"income"`
+	TotalAssigned      float64              `json:"total_assigned"`
+	ToBeAssigned       float64              `json:"to_be_assigned"`
+	TotalSpent         float64              `json:"total_spent"`
+	TotalOverspent     float64              `json:"total_overspent"`
+	Envelopes          []Envelope           `json:"envelopes"`
+	OverspentEnvelopes []Envelope           `json:"overspent_envelopes"`
+	Unbudgeted         []UnbudgetedCategory `json:"unbudgeted"`
+}
+
+// SuggestionMethod - statistic used to turn past spending into a budget amount
+type SuggestionMethod string
+
+const (
+	SuggestionAverage    SuggestionMethod = "average"
+	SuggestionMedian     SuggestionMethod = "median"
+	SuggestionPercentile SuggestionMethod = "percentile"
+)
+
+type BudgetTrend string
+
+const (
+	TrendIncreasing BudgetTrend = "increasing"
+	TrendDecreasing BudgetTrend = "decreasing"
+	TrendStable     BudgetTrend = "stable"
+)
+
+// BudgetSuggestion - proposed budget for one category based on past spending.
+// MonthlySpending is ordered oldest month first.
+type BudgetSuggestion struct {
+	Category           string      `json:"category"`
+	SuggestedAmount    float64     `json:"suggested_amount"`
+	Average            float64     `json:"average"`
+	Median             float64     `json:"median"`
+	Min                float64     `json:"min"`
+	Max                float64     `json:"max"`
+	MonthsWithSpending int         `json:"months_with_spending"`
+	MonthlySpending    []float64   `json:"monthly_spending"`
+	Trend              BudgetTrend `json:"trend"`
+	TrendPercent       float64     `json:"trend_percent"`
+	Seasonal           bool        `json:"seasonal"`
+	LastYearSameMonth  *float64    `json:"last_year_same_month,omitempty"`
+	CurrentBudget      *float64    `json:"current_budget,omitempty"`
+}
+
+type BudgetSuggestionReport struct {
+	Month       int                `json:"month"`
+	Year        int                `json:"year"`
+	Months      int                `json:"months"`
+	Method      SuggestionMethod   `json:"method"`
+	Percentile  float64            `json:"percentile,omitempty"`
+	Suggestions []BudgetSuggestion `json:"suggestions"`
 }
 
 // Credit Card - kept separate for specific features
@@ -42,8 +241,192 @@ type CreditCard struct {
 	CurrentBalance float64   `db:"current_balance" json:"current_balance"`
 	BillingDate    int       `db:"billing_date" json:"billing_date"`
 	PaymentDueDate int       `db:"payment_due_date" json:"payment_due_date"`
-	CreatedAt      time.Time `db:"created_at" json:"created_at"`
-	UpdatedAt      time.Time `db:"updated_at" json:"updated_at"`
+	// Minimum payment: this percentage of the statement balance, but at least the floor
+	MinPaymentPercent float64 `db:"min_payment_percent" json:"min_payment_percent"`
+	MinPaymentFloor   float64 `db:"min_payment_floor" json:"min_payment_floor"`
+	// Cost of revolving: monthly interest (%) and late fee (% of amount due, capped)
+	InterestRateMonthly float64   `db:"interest_rate_monthly" json:"interest_rate_monthly"`
+	LateFeePercent      float64   `db:"late_fee_percent" json:"late_fee_percent"`
+	LateFeeCap          float64   `db:"late_fee_cap" json:"late_fee_cap"`
+	CreatedAt           time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt           time.Time `db:"updated_at" json:"updated_at"`
+	// For response only - principal of installment plans not billed yet, and
+	// the credit limit minus current balance and that principal
+	InstallmentBalance float64 `db:"-" json:"installment_balance"`
+	AvailableCredit    float64 `db:"-" json:"available_credit"`
+}
+
+const (
+	DefaultMinPaymentPercent   = 5
+	DefaultMinPaymentFloor     = 50000
+	DefaultInterestRateMonthly = 1.75
+	DefaultLateFeePercent      = 1
+	DefaultLateFeeCap          = 100000
+
+	// Minimum-payment simulations stop after this many months
+	maxPayoffMonths = 600
+)
+
+// MinimumPayment returns the minimum payment due on a statement balance
+func (c *CreditCard) MinimumPayment(balance float64) float64 {
+	if balance <= 0 {
+		return 0
+	}
+	minimum := math.Max(balance*c.MinPaymentPercent/100, c.MinPaymentFloor)
+	return math.Round(math.Min(minimum, balance)*100) / 100
+}
+
+// LateFee returns the fee charged when amountDue is not paid by the due date
+func (c *CreditCard) LateFee(amountDue float64) float64 {
+	if amountDue <= 0 {
+		return 0
+	}
+	return math.Round(math.Min(amountDue*c.LateFeePercent/100, c.LateFeeCap)*100) / 100
+}
+
+// StatementLateFee returns the late fee of an overdue statement. Only the
+// part of its amount due that was not already due on the previous (older)
+// statement is charged, so unpaid debt carried from month to month incurs a
+// late fee once.
+func (c *CreditCard) StatementLateFee(statement CreditCardStatement, previous *CreditCardStatement) float64 {
+	amountDue := statement.AmountDue
+	if previous != nil {
+		amountDue -= previous.AmountDue
+	}
+	return c.LateFee(amountDue)
+}
+
+// SimulateMinimumPayments projects paying only the minimum on balance each
+// month while monthly interest accrues on what is left. It returns the number
+// of payments, the total interest and the total paid; paidOff is false when
+// the balance is not cleared within maxPayoffMonths.
+func (c *CreditCard) SimulateMinimumPayments(balance float64) (months int, interest float64, paid float64, paidOff bool) {
+	for balance > 0 {
+		if months == maxPayoffMonths {
+			return months, interest, paid, false
+		}
+		payment := c.MinimumPayment(balance)
+		balance -= payment
+		paid += payment
+		months++
+
+		charge := math.Round(balance*c.InterestRateMonthly) / 100
+		balance += charge
+		interest += charge
+	}
+	return months, interest, paid, true
+}
+
+// CreditCardAnalysis - what a card's balance costs when it revolves
+type CreditCardAnalysis struct {
+	CreditCardID       uuid.UUID `json:"credit_card_id"`
+	CardName           string    `json:"card_name"`
+	CreditLimit        float64   `json:"credit_limit"`
+	CurrentBalance     float64   `json:"current_balance"`
+	InstallmentBalance float64   `json:"installment_balance"`
+	// (current balance + unbilled installment principal) / credit limit, in percent
+	Utilization         float64 `json:"utilization"`
+	InterestRateMonthly float64 `json:"interest_rate_monthly"`
+	// Paying only the minimum: next month's interest and the full payoff projection
+	MinimumPayment       float64 `json:"minimum_payment"`
+	NextMonthInterest    float64 `json:"next_month_interest"`
+	MinimumOnlyMonths    int     `json:"minimum_only_months"`
+	MinimumOnlyInterest  float64 `json:"minimum_only_interest"`
+	MinimumOnlyTotalPaid float64 `json:"minimum_only_total_paid"`
+	MinimumOnlyPaysOff   bool    `json:"minimum_only_pays_off"`
+	// Late fees on overdue statements, and the fee the open bill would incur if missed
+	OverdueStatements int        `json:"overdue_statements"`
+	LateFees          float64    `json:"late_fees"`
+	NextDueDate       *time.Time `json:"next_due_date,omitempty"`
+	LateFeeIfMissed   float64    `json:"late_fee_if_missed"`
+}
+
+// CreditCardAnalysisReport - all of a user's cards together
+type CreditCardAnalysisReport struct {
+	TotalLimit               float64              `json:"total_limit"`
+	TotalBalance             float64              `json:"total_balance"`
+	TotalInstallmentBalance  float64              `json:"total_installment_balance"`
+	Utilization              float64              `json:"utilization"`
+	TotalNextMonthInterest   float64              `json:"total_next_month_interest"`
+	TotalMinimumOnlyInterest float64              `json:"total_minimum_only_interest"`
+	TotalLateFees            float64              `json:"total_late_fees"`
+	Cards                    []CreditCardAnalysis `json:"cards"`
+}
+
+type StatementStatus string
+
+const (
+	// The statement period has not closed yet
+	StatementCurrent StatementStatus = "current"
+	// Closed and not yet due, nothing paid so far
+	StatementUnpaid        StatementStatus = "unpaid"
+	StatementPartiallyPaid StatementStatus = "partially_paid"
+	StatementPaid          StatementStatus = "paid"
+	// Past the due date without the minimum payment
+	StatementOverdue StatementStatus = "overdue"
+)
+
+// CreditCardStatement - one billing cycle of a card. Payments are those made
+// after the closing date up to the next closing date, i.e. the ones that
+// settle this statement.
+type CreditCardStatement struct {
+	CreditCardID     uuid.UUID       `json:"credit_card_id"`
+	Month            int             `json:"month"`
+	Year             int             `json:"year"`
+	PeriodStart      time.Time       `json:"period_start"`
+	ClosingDate      time.Time       `json:"closing_date"`
+	DueDate          time.Time       `json:"due_date"`
+	OpeningBalance   float64         `json:"opening_balance"`
+	Purchases        float64         `json:"purchases"`
+	Fees             float64         `json:"fees"`
+	Refunds          float64         `json:"refunds"`
+	Installments     float64         `json:"installments"`
+	PaymentsInPeriod float64         `json:"payments_in_period"`
+	ClosingBalance   float64         `json:"closing_balance"`
+	MinimumPayment   float64         `json:"minimum_payment"`
+	AmountPaid       float64         `json:"amount_paid"`
+	AmountDue        float64         `json:"amount_due"`
+	Status           StatementStatus `json:"status"`
+}
+
+type CreditCardTransactionType string
+
+const (
+	CreditCardPurchase CreditCardTransactionType = "purchase"
+	CreditCardRefund   CreditCardTransactionType = "refund"
+	CreditCardFee      CreditCardTransactionType = "fee"
+	CreditCardPayment  CreditCardTransactionType = "payment"
+	// One month of an installment plan (or its admin fee)
+	CreditCardInstallmentCharge CreditCardTransactionType = "installment"
+)
+
+// BalanceDelta returns how much a transaction of this type changes the card balance per unit of amount
+func (t CreditCardTransactionType) BalanceDelta() float64 {
+	switch t {
+	case CreditCardPurchase, CreditCardFee, CreditCardInstallmentCharge:
+		return 1
+	default:
+		return -1
+	}
+}
+
+// CreditCardTransaction - a charge or payment booked directly on a card.
+// Payments from a bank account link to the transfer transaction on that account.
+type CreditCardTransaction struct {
+	ID              uuid.UUID                 `db:"id" json:"id"`
+	UserID          uuid.UUID                 `db:"user_id" json:"user_id"`
+	CreditCardID    uuid.UUID                 `db:"credit_card_id" json:"credit_card_id"`
+	Type            CreditCardTransactionType `db:"type" json:"type"`
+	Category        string                    `db:"category" json:"category"`
+	Amount          float64                   `db:"amount" json:"amount"`
+	Description     string                    `db:"description" json:"description"`
+	TransactionDate time.Time                 `db:"transaction_date" json:"transaction_date"`
+	TransactionID   *uuid.UUID                `db:"transaction_id" json:"transaction_id,omitempty"`
+	// Installment plan postings: plan and installment number (0 = admin fee)
+	InstallmentID     *uuid.UUID `db:"installment_id" json:"installment_id,omitempty"`
+	InstallmentNumber *int       `db:"installment_number" json:"installment_number,omitempty"`
+	CreatedAt         time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt         time.Time  `db:"updated_at" json:"updated_at"`
 }
 
 type CreateCreditCardRequest struct {
@@ -54,6 +437,158 @@ type CreateCreditCardRequest struct {
 	PaymentDueDate int     `json:"payment_due_date" binding:"required,min=1,max=31"`
 }
 
+// CreditCardInstallment - a purchase converted into a fixed number of monthly
+// installments (cicilan). Each installment is principal / tenor plus flat
+// interest on the full principal; the first one is billed on the statement
+// closing in StartMonth/StartYear.
+type CreditCardInstallment struct {
+	ID                  uuid.UUID `db:"id" json:"id"`
+	UserID              uuid.UUID `db:"user_id" json:"user_id"`
+	CreditCardID        uuid.UUID `db:"credit_card_id" json:"credit_card_id"`
+	Description         string    `db:"description" json:"description"`
+	Category            string    `db:"category" json:"category"`
+	Principal           float64   `db:"principal" json:"principal"`
+	TenorMonths         int       `db:"tenor_months" json:"tenor_months"`
+	InterestRateMonthly float64   `db:"interest_rate_monthly" json:"interest_rate_monthly"`
+	AdminFee            float64   `db:"admin_fee" json:"admin_fee"`
+	StartMonth          int       `db:"start_month" json:"start_month"`
+	StartYear           int       `db:"start_year" json:"start_year"`
+	CreatedAt           time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt           time.Time `db:"updated_at" json:"updated_at"`
+	// For response only
+	InstallmentsPosted   int     `db:"installments_posted" json:"installments_posted"`
+	MonthlyAmount        float64 `db:"-" json:"monthly_amount"`
+	TotalCost            float64 `db:"-" json:"total_cost"`
+	OutstandingPrincipal float64 `db:"-" json:"outstanding_principal"`
+}
+
+// InstallmentAmount splits installment n (1-based) into principal and flat
+// interest. Amounts are rounded to cents; the last installment takes the
+// rounding remainder of the principal.
+func (p *CreditCardInstallment) InstallmentAmount(n int) (float64, float64) {
+	principal := math.Round(p.Principal/float64(p.TenorMonths)*100) / 100
+	if n == p.TenorMonths {
+		principal = math.Round((p.Principal-principal*float64(p.TenorMonths-1))*100) / 100
+	}
+	interest := math.Round(p.Principal*p.InterestRateMonthly) / 100
+	return principal, interest
+}
+
+// PrincipalOutstanding returns the principal not yet billed after posted installments
+func (p *CreditCardInstallment) PrincipalOutstanding(posted int) float64 {
+	if posted >= p.TenorMonths {
+		return 0
+	}
+	principal, _ := p.InstallmentAmount(1)
+	return math.Round((p.Principal-principal*float64(posted))*100) / 100
+}
+
+// Summarize fills the response-only amounts from InstallmentsPosted
+func (p *CreditCardInstallment) Summarize() {
+	principal, interest := p.InstallmentAmount(1)
+	p.MonthlyAmount = principal + interest
+	p.TotalCost = p.Principal + interest*float64(p.TenorMonths) + p.AdminFee
+	p.OutstandingPrincipal = p.PrincipalOutstanding(p.InstallmentsPosted)
+}
+
+type RewardType string
+
+const (
+	RewardPoints   RewardType = "points"
+	RewardMiles    RewardType = "miles"
+	RewardCashback RewardType = "cashback"
+)
+
+// CreditCardRewardRule - earns EarnRate units of RewardType per SpendUnit
+// spent in Category, at most MonthlyCap units per statement cycle. A nil
+// Category is the card's default rule for categories without their own.
+type CreditCardRewardRule struct {
+	ID           uuid.UUID  `db:"id" json:"id"`
+	UserID       uuid.UUID  `db:"user_id" json:"user_id"`
+	CreditCardID uuid.UUID  `db:"credit_card_id" json:"credit_card_id"`
+	Category     *string    `db:"category" json:"category"`
+	RewardType   RewardType `db:"reward_type" json:"reward_type"`
+	EarnRate     float64    `db:"earn_rate" json:"earn_rate"`
+	SpendUnit    float64    `db:"spend_unit" json:"spend_unit"`
+	UnitValue    float64    `db:"unit_value" json:"unit_value"`
+	MonthlyCap   *float64   `db:"monthly_cap" json:"monthly_cap,omitempty"`
+	CreatedAt    time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt    time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+// Earn returns the reward units earned on spend, given what the rule has
+// already earned in the same cycle
+func (r *CreditCardRewardRule) Earn(spend, earnedSoFar float64) float64 {
+	if spend <= 0 {
+		return 0
+	}
+	units := math.Floor(spend/r.SpendUnit) * r.EarnRate
+	if r.MonthlyCap != nil {
+		units = math.Max(math.Min(units, *r.MonthlyCap-earnedSoFar), 0)
+	}
+	return units
+}
+
+// ReturnPercent is the rupiah value earned per 100 rupiah spent, before caps
+func (r *CreditCardRewardRule) ReturnPercent() float64 {
+	return r.EarnRate / r.SpendUnit * r.UnitValue * 100
+}
+
+type CreditCardRewardRedemption struct {
+	ID           uuid.UUID  `db:"id" json:"id"`
+	UserID       uuid.UUID  `db:"user_id" json:"user_id"`
+	CreditCardID uuid.UUID  `db:"credit_card_id" json:"credit_card_id"`
+	RewardType   RewardType `db:"reward_type" json:"reward_type"`
+	Amount       float64    `db:"amount" json:"amount"`
+	Value        float64    `db:"value" json:"value"`
+	Description  string     `db:"description" json:"description"`
+	RedeemedAt   time.Time  `db:"redeemed_at" json:"redeemed_at"`
+	CreatedAt    time.Time  `db:"created_at" json:"created_at"`
+}
+
+// RewardEarning - units earned by one rule in one statement cycle. RuleID is
+// nil once the rule has been deleted; what it earned is kept.
+type RewardEarning struct {
+	Month      int        `db:"cycle_month" json:"month"`
+	Year       int        `db:"cycle_year" json:"year"`
+	RuleID     *uuid.UUID `db:"rule_id" json:"rule_id"`
+	Category   string     `db:"category" json:"category"`
+	RewardType RewardType `db:"reward_type" json:"reward_type"`
+	Spend      float64    `db:"spend" json:"spend"`
+	Earned     float64    `db:"earned" json:"earned"`
+	Capped     bool       `db:"capped" json:"capped"`
+}
+
+// RewardBalance - earned minus redeemed units of one reward type
+type RewardBalance struct {
+	RewardType RewardType `json:"reward_type"`
+	Earned     float64    `json:"earned"`
+	Redeemed   float64    `json:"redeemed"`
+	Balance    float64    `json:"balance"`
+	// Rupiah value of the balance at the card's unit value for this type
+	Value float64 `json:"value"`
+}
+
+type CreditCardRewardSummary struct {
+	CreditCardID uuid.UUID                    `json:"credit_card_id"`
+	Balances     []RewardBalance              `json:"balances"`
+	Earnings     []RewardEarning              `json:"earnings"`
+	Redemptions  []CreditCardRewardRedemption `json:"redemptions"`
+}
+
+// BestCardOption - what one card would earn on a purchase
+type BestCardOption struct {
+	CreditCardID    uuid.UUID  `json:"credit_card_id"`
+	CardName        string     `json:"card_name"`
+	RuleID          uuid.UUID  `json:"rule_id"`
+	RewardType      RewardType `json:"reward_type"`
+	ReturnPercent   float64    `json:"return_percent"`
+	EstimatedReward float64    `json:"estimated_reward,omitempty"`
+	EstimatedValue  float64    `json:"estimated_value,omitempty"`
+	RemainingCap    *float64   `json:"remaining_cap,omitempty"`
+	AvailableCredit float64    `json:"available_credit"`
+}
+
 // Gold Asset - replacing investments
 type GoldType string
 
@@ -65,24 +600,50 @@ const (
 	GoldTypeOther     GoldType = "other"
 )
 
+// GoldItemKind - the form a lot is held in
+type GoldItemKind string
+
+const (
+	GoldItemBar     GoldItemKind = "bar"
+	GoldItemCoin    GoldItemKind = "coin"
+	GoldItemJewelry GoldItemKind = "jewelry"
+)
+
+// DefaultJewelryHaircutPercent is the share of the gold value lost when
+// jewelry is sold back, used when none is given
+const DefaultJewelryHaircutPercent = 10.0
+
 type GoldAsset struct {
-	ID                   uuid.UUID `db:"id" json:"id"`
-	UserID               uuid.UUID `db:"user_id" json:"user_id"`
-	Name                 string    `db:"name" json:"name"`
-	GoldType             GoldType  `db:"gold_type" json:"gold_type"`
-	WeightGram           float64   `db:"weight_gram" json:"weight_gram"`
-	PurchasePricePerGram float64   `db:"purchase_price_per_gram" json:"purchase_price_per_gram"`
-	PurchaseDate         time.Time `db:"purchase_date" json:"purchase_date"`
-	StorageLocation      string    `db:"storage_location" json:"storage_location"`
-	Notes                string    `db:"notes" json:"notes"`
-	CreatedAt            time.Time `db:"created_at" json:"created_at"`
-	UpdatedAt            time.Time `db:"updated_at" json:"updated_at"`
-	// Calculated fields (from gold_prices)
-	CurrentPricePerGram float64 `db:"-" json:"current_price_per_gram,omitempty"`
-	CurrentValue        float64 `db:"-" json:"current_value,omitempty"`
-	PurchaseValue       float64 `db:"-" json:"purchase_value,omitempty"`
-	ProfitLoss          float64 `db:"-" json:"profit_loss,omitempty"`
-	ProfitLossPercent   float64 `db:"-" json:"profit_loss_percent,omitempty"`
+	ID                   uuid.UUID    `db:"id" json:"id"`
+	UserID               uuid.UUID    `db:"user_id" json:"user_id"`
+	Name                 string       `db:"name" json:"name"`
+	GoldType             GoldType     `db:"gold_type" json:"gold_type"`
+	ItemKind             GoldItemKind `db:"item_kind" json:"item_kind"`
+	Purity               float64      `db:"purity" json:"purity"`
+	ResaleHaircutPercent float64      `db:"resale_haircut_percent" json:"resale_haircut_percent"`
+	WeightGram           float64      `db:"weight_gram" json:"weight_gram"`
+	RemainingWeightGram  float64      `db:"remaining_weight_gram" json:"remaining_weight_gram"`
+	PurchasePricePerGram float64      `db:"purchase_price_per_gram" json:"purchase_price_per_gram"`
+	PurchaseDate         time.Time    `db:"purchase_date" json:"purchase_date"`
+	StorageLocation      string       `db:"storage_location" json:"storage_location"`
+	Notes                string       `db:"notes" json:"notes"`
+	CreatedAt            time.Time    `db:"created_at" json:"created_at"`
+	UpdatedAt            time.Time    `db:"updated_at" json:"updated_at"`
+	// Karat and the fine gold in the remaining weight, from Purity
+	Karat          float64 `db:"-" json:"karat"`
+	FineWeightGram float64 `db:"-" json:"fine_weight_gram"`
+	// Calculated fields (from gold_prices) over the remaining weight.
+	// QuotePricePerGram is the 24k buyback price of the quote in
+	// PriceGoldType/PriceDate; CurrentPricePerGram is what a gram of this
+	// lot fetches, scaled by purity and the resale haircut.
+	QuotePricePerGram   float64    `db:"-" json:"quote_price_per_gram,omitempty"`
+	CurrentPricePerGram float64    `db:"-" json:"current_price_per_gram,omitempty"`
+	PriceGoldType       GoldType   `db:"-" json:"price_gold_type,omitempty"`
+	PriceDate           *time.Time `db:"-" json:"price_date,omitempty"`
+	CurrentValue        float64    `db:"-" json:"current_value,omitempty"`
+	PurchaseValue       float64    `db:"-" json:"purchase_value,omitempty"`
+	ProfitLoss          float64    `db:"-" json:"profit_loss,omitempty"`
+	ProfitLossPercent   float64    `db:"-" json:"profit_loss_percent,omitempty"`
 }
 
 type CreateGoldAssetRequest struct {
@@ -95,17 +656,193 @@ type CreateGoldAssetRequest struct {
 	Notes                string   `json:"notes"`
 }
 
-// Gold Price - daily price data
+// KaratOf converts a purity (fine gold share) to karat
+func KaratOf(purity float64) float64 {
+	return math.Round(purity*24*100) / 100
+}
+
+// PurityOf converts a karat to a purity, rounded as stored
+func PurityOf(karat float64) float64 {
+	return math.Round(karat/24*10000) / 10000
+}
+
+// ValidGoldItemKind reports whether k is a known item kind
+func ValidGoldItemKind(k GoldItemKind) bool {
+	switch k {
+	case GoldItemBar, GoldItemCoin, GoldItemJewelry:
+		return true
+	}
+	return false
+}
+
+// ValueFactor is the share of the 24k buyback price a gram of the lot
+// fetches: its purity, less the resale haircut
+func (a *GoldAsset) ValueFactor() float64 {
+	return a.Purity * (1 - a.ResaleHaircutPercent/100)
+}
+
+// GoldHolding - the lots of one brand, item kind and purity still held,
+// added up. Prices are per gram of the item: the current price is the 24k
+// quote scaled by purity and resale haircut, and the break-even price is the
+// one at which selling everything, after the selling fee, returns the cost.
+type GoldHolding struct {
+	GoldType              GoldType     `json:"gold_type"`
+	ItemKind              GoldItemKind `json:"item_kind"`
+	Purity                float64      `json:"purity"`
+	Karat                 float64      `json:"karat"`
+	LotCount              int          `json:"lot_count"`
+	WeightGram            float64      `json:"weight_gram"`
+	FineWeightGram        float64      `json:"fine_weight_gram"`
+	CostBasis             float64      `json:"cost_basis"`
+	AveragePricePerGram   float64      `json:"average_price_per_gram"`
+	BreakEvenPricePerGram float64      `json:"break_even_price_per_gram"`
+	QuotePricePerGram     float64      `json:"quote_price_per_gram,omitempty"`
+	CurrentPricePerGram   float64      `json:"current_price_per_gram,omitempty"`
+	PriceGoldType         GoldType     `json:"price_gold_type,omitempty"`
+	PriceDate             *time.Time   `json:"price_date,omitempty"`
+	MarketValue           float64      `json:"market_value"`
+	UnrealizedPL          float64      `json:"unrealized_profit_loss"`
+	UnrealizedPLPercent   float64      `json:"unrealized_profit_loss_percent"`
+	// How far the price must move (in percent) to reach break-even
+	ChangeToBreakEven *float64 `json:"change_to_break_even_percent,omitempty"`
+}
+
+type goldHoldingKey struct {
+	goldType GoldType
+	itemKind GoldItemKind
+	purity   float64
+}
+
+// GroupHoldings adds up valued lots (see GoldRepository.calculateAssetValues)
+// per brand, item kind and purity. sellFeePercent is the fee charged on the
+// sale value.
+func GroupHoldings(assets []GoldAsset, sellFeePercent float64) []GoldHolding {
+	byKey := make(map[goldHoldingKey]*GoldHolding)
+	order := []goldHoldingKey{}
+	for _, a := range assets {
+		if a.RemainingWeightGram <= 0 {
+			continue
+		}
+		key := goldHoldingKey{a.GoldType, a.ItemKind, a.Purity}
+		h := byKey[key]
+		if h == nil {
+			h = &GoldHolding{GoldType: a.GoldType, ItemKind: a.ItemKind, Purity: a.Purity, Karat: KaratOf(a.Purity)}
+			byKey[key] = h
+			order = append(order, key)
+		}
+		h.LotCount++
+		h.WeightGram = RoundGram(h.WeightGram + a.RemainingWeightGram)
+		h.FineWeightGram = RoundGram(h.FineWeightGram + a.FineWeightGram)
+		h.CostBasis += a.PurchaseValue
+		if a.PriceDate != nil {
+			h.QuotePricePerGram = a.QuotePricePerGram
+			h.PriceGoldType = a.PriceGoldType
+			h.PriceDate = a.PriceDate
+			h.MarketValue += a.CurrentValue
+		}
+	}
+
+	holdings := []GoldHolding{}
+	for _, key := range order {
+		h := byKey[key]
+		h.AveragePricePerGram = roundMoney(h.CostBasis / h.WeightGram)
+		h.BreakEvenPricePerGram = roundMoney(h.CostBasis / h.WeightGram / (1 - sellFeePercent/100))
+		if h.PriceDate != nil {
+			// Lots with different haircuts are blended
+			h.CurrentPricePerGram = roundMoney(h.MarketValue / h.WeightGram)
+			h.UnrealizedPL = h.MarketValue - h.CostBasis
+			if h.CostBasis > 0 {
+				h.UnrealizedPLPercent = h.UnrealizedPL / h.CostBasis * 100
+			}
+			if h.CurrentPricePerGram > 0 {
+				change := (h.BreakEvenPricePerGram - h.CurrentPricePerGram) / h.CurrentPricePerGram * 100
+				h.ChangeToBreakEven = &change
+			}
+		}
+		holdings = append(holdings, *h)
+	}
+	sort.Slice(holdings, func(i, j int) bool { return holdings[i].WeightGram > holdings[j].WeightGram })
+	return holdings
+}
+
+// ValidGoldType reports whether t is one of the known brands
+func ValidGoldType(t GoldType) bool {
+	switch t {
+	case GoldTypeAntam, GoldTypeUBS, GoldTypeGaleri24, GoldTypePegadaian, GoldTypeOther:
+		return true
+	}
+	return false
+}
+
+// Gold Price - daily buy and buyback price of one brand
 type GoldPrice struct {
-	ID           uuid.UUID `db:"id" json:"id"`
-	PriceDate    time.Time `db:"price_date" json:"price_date"`
-	PricePerGram float64   `db:"price_per_gram" json:"price_per_gram"`
-	Source       string    `db:"source" json:"source"`
-	CreatedAt    time.Time `db:"created_at" json:"created_at"`
-	UpdatedAt    time.Time `db:"updated_at" json:"updated_at"`
+	ID                  uuid.UUID `db:"id" json:"id"`
+	PriceDate           time.Time `db:"price_date" json:"price_date"`
+	GoldType            GoldType  `db:"gold_type" json:"gold_type"`
+	BuyPricePerGram     float64   `db:"buy_price_per_gram" json:"buy_price_per_gram"`
+	BuybackPricePerGram float64   `db:"buyback_price_per_gram" json:"buyback_price_per_gram"`
+	Source              string    `db:"source" json:"source"`
+	CreatedAt           time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt           time.Time `db:"updated_at" json:"updated_at"`
 }
 
 type UpdateGoldPriceRequest struct {
-	PricePerGram float64 `json:"price_per_gram" binding:"required,gt=0"`
-	Source       string  `json:"source"`
+	GoldType            GoldType `json:"gold_type" binding:"required"`
+	BuyPricePerGram     float64  `json:"buy_price_per_gram" binding:"required,gt=0"`
+	BuybackPricePerGram float64  `json:"buyback_price_per_gram" binding:"required,gt=0,ltefield=BuyPricePerGram"`
+	Source              string   `json:"source"`
+}
+
+// GoldQuotes - the most recent quote of each brand
+type GoldQuotes map[GoldType]GoldPrice
+
+// NewGoldQuotes keeps the newest of the given quotes per brand
+func NewGoldQuotes(prices []GoldPrice) GoldQuotes {
+	quotes := make(GoldQuotes, len(prices))
+	for _, p := range prices {
+		if current, ok := quotes[p.GoldType]; !ok || p.PriceDate.After(current.PriceDate) {
+			quotes[p.GoldType] = p
+		}
+	}
+	return quotes
+}
+
+// LatestDate returns the newest quote date, zero when there are no quotes
+func (q GoldQuotes) LatestDate() time.Time {
+	var latest time.Time
+	for _, p := range q {
+		if p.PriceDate.After(latest) {
+			latest = p.PriceDate
+		}
+	}
+	return latest
+}
+
+// For picks the quote to value gold of type t at. In order: the brand's own
+// quote if it is from the latest date, the generic "other" quote from that
+// date, the brand's own older quote, and finally the lowest buyback quoted
+// on the latest date. Returns nil when there are no quotes.
+func (q GoldQuotes) For(t GoldType) *GoldPrice {
+	latest := q.LatestDate()
+	if p, ok := q[t]; ok && p.PriceDate.Equal(latest) {
+		return &p
+	}
+	if p, ok := q[GoldTypeOther]; ok && p.PriceDate.Equal(latest) {
+		return &p
+	}
+	if p, ok := q[t]; ok {
+		return &p
+	}
+
+	var lowest *GoldPrice
+	for _, p := range q {
+		if !p.PriceDate.Equal(latest) {
+			continue
+		}
+		if lowest == nil || p.BuybackPricePerGram < lowest.BuybackPricePerGram {
+			p := p
+			lowest = &p
+		}
+	}
+	return lowest
 }
@@ -0,0 +1,138 @@
+package models
+
+import (
+	"math"
+	"testing"
+)
+
+func TestStatementLateFee(t *testing.T) {
+	card := &CreditCard{LateFeePercent: 1, LateFeeCap: 100000}
+	statement := func(amountDue float64) *CreditCardStatement {
+		return &CreditCardStatement{Status: StatementOverdue, AmountDue: amountDue}
+	}
+
+	tests := []struct {
+		name      string
+		statement *CreditCardStatement
+		previous  *CreditCardStatement
+		want      float64
+	}{
+		{"oldest statement", statement(2000000), nil, 20000},
+		{"new charges on top of carried debt", statement(3000000), statement(2000000), 10000},
+		{"only carried debt", statement(2000000), statement(2000000), 0},
+		{"partly paid down carried debt", statement(1500000), statement(2000000), 0},
+		{"previous statement paid", statement(500000), statement(0), 5000},
+		{"fee is capped", statement(50000000), nil, 100000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := card.StatementLateFee(*tt.statement, tt.previous); got != tt.want {
+				t.Errorf("StatementLateFee() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKaratAndPurity(t *testing.T) {
+	tests := []struct {
+		karat  float64
+		purity float64
+	}{
+		{24, 1},
+		{22, 0.9167},
+		{18, 0.75},
+		{17, 0.7083},
+		{9, 0.375},
+		{0, 0},
+	}
+	for _, tt := range tests {
+		if got := PurityOf(tt.karat); got != tt.purity {
+			t.Errorf("PurityOf(%v) = %v, want %v", tt.karat, got, tt.purity)
+		}
+		// The stored purity converts back to the karat it came from
+		if got := KaratOf(tt.purity); got != tt.karat {
+			t.Errorf("KaratOf(%v) = %v, want %v", tt.purity, got, tt.karat)
+		}
+	}
+
+	if got := KaratOf(0.999); got != 23.98 {
+		t.Errorf("KaratOf(0.999) = %v, want 23.98", got)
+	}
+}
+
+func TestGoldAssetValueFactor(t *testing.T) {
+	tests := []struct {
+		name    string
+		purity  float64
+		haircut float64
+		want    float64
+	}{
+		{"fine bar", 1, 0, 1},
+		{"22k coin", 0.9167, 0, 0.9167},
+		{"18k jewelry with default haircut", 0.75, DefaultJewelryHaircutPercent, 0.675},
+		{"fine jewelry with haircut", 1, 25, 0.75},
+		{"no purity", 0, 10, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := GoldAsset{Purity: tt.purity, ResaleHaircutPercent: tt.haircut}
+			if got := a.ValueFactor(); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("ValueFactor() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// valuedLot values a lot the way GoldRepository does at a 24k buyback quote
+func valuedLot(a GoldAsset, buyback float64) GoldAsset {
+	a.FineWeightGram = RoundGram(a.RemainingWeightGram * a.Purity)
+	a.PurchaseValue = a.RemainingWeightGram * a.PurchasePricePerGram
+	a.QuotePricePerGram = buyback
+	a.CurrentPricePerGram = buyback * a.ValueFactor()
+	a.CurrentValue = a.RemainingWeightGram * a.CurrentPricePerGram
+	a.PriceGoldType = a.GoldType
+	day := date(2025, 3, 7)
+	a.PriceDate = &day
+	return a
+}
+
+func TestGroupHoldingsByPurity(t *testing.T) {
+	bar := goldLot("bar", 5, 1000000)
+	ring := goldLot("ring", 10, 600000)
+	ring.ItemKind, ring.Purity, ring.ResaleHaircutPercent = GoldItemJewelry, 0.75, 10
+	necklace := goldLot("necklace", 10, 600000)
+	necklace.ItemKind, necklace.Purity, necklace.ResaleHaircutPercent = GoldItemJewelry, 0.75, 20
+	bracelet := goldLot("bracelet", 2, 800000)
+	bracelet.ItemKind, bracelet.Purity = GoldItemJewelry, 0.9167
+	sold := goldLot("sold", 0, 1000000)
+
+	assets := []GoldAsset{}
+	for _, a := range []GoldAsset{bar, ring, necklace, bracelet, sold} {
+		assets = append(assets, valuedLot(a, 1000000))
+	}
+
+	holdings := GroupHoldings(assets, 0)
+	if len(holdings) != 3 {
+		t.Fatalf("GroupHoldings() = %d holdings, want 3", len(holdings))
+	}
+
+	// 18k jewelry: both lots blended, heaviest first
+	h := holdings[0]
+	if h.ItemKind != GoldItemJewelry || h.Purity != 0.75 || h.Karat != 18 || h.LotCount != 2 {
+		t.Errorf("holding = %s %v (%vk) of %d lots, want 18k jewelry of 2 lots", h.ItemKind, h.Purity, h.Karat, h.LotCount)
+	}
+	if h.WeightGram != 20 || h.FineWeightGram != 15 || h.CostBasis != 12000000 || h.MarketValue != 12750000 {
+		t.Errorf("holding = %.4fg (%.4fg fine), cost %.2f, market %.2f, want 20g (15g fine), 12000000, 12750000",
+			h.WeightGram, h.FineWeightGram, h.CostBasis, h.MarketValue)
+	}
+	if h.QuotePricePerGram != 1000000 || h.CurrentPricePerGram != 637500 {
+		t.Errorf("prices = quote %.2f, current %.2f, want 1000000 and 637500", h.QuotePricePerGram, h.CurrentPricePerGram)
+	}
+
+	if h := holdings[1]; h.ItemKind != GoldItemBar || h.WeightGram != 5 || h.CurrentPricePerGram != 1000000 {
+		t.Errorf("holding = %s %.4fg at %.2f, want the 5g bar at 1000000", h.ItemKind, h.WeightGram, h.CurrentPricePerGram)
+	}
+	if h := holdings[2]; h.Karat != 22 || h.WeightGram != 2 || h.CurrentPricePerGram != 916700 {
+		t.Errorf("holding = %vk %.4fg at %.2f, want 22k 2g at 916700", h.Karat, h.WeightGram, h.CurrentPricePerGram)
+	}
+}
@@ -0,0 +1,115 @@
+package models
+
+import (
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type GoldAlertType string
+
+const (
+	GoldAlertPriceAbove  GoldAlertType = "price_above"
+	GoldAlertPriceBelow  GoldAlertType = "price_below"
+	GoldAlertDailyChange GoldAlertType = "daily_change"
+	GoldAlertPositionPL  GoldAlertType = "position_pl"
+)
+
+// GoldPriceKind - which side of a quote a price alert watches
+type GoldPriceKind string
+
+const (
+	GoldPriceBuy     GoldPriceKind = "buy"
+	GoldPriceBuyback GoldPriceKind = "buyback"
+)
+
+// GoldPriceAlert - a per-user alert evaluated on every new quote. Threshold
+// is a price per gram for price_above/price_below and a percent for
+// daily_change and position_pl (negative to watch losses). GoldType is
+// empty only for a position_pl alert over all of the user's gold.
+type GoldPriceAlert struct {
+	ID              uuid.UUID     `db:"id" json:"id"`
+	UserID          uuid.UUID     `db:"user_id" json:"user_id"`
+	AlertType       GoldAlertType `db:"alert_type" json:"alert_type"`
+	GoldType        *GoldType     `db:"gold_type" json:"gold_type"`
+	PriceKind       GoldPriceKind `db:"price_kind" json:"price_kind"`
+	Threshold       float64       `db:"threshold" json:"threshold"`
+	IsActive        bool          `db:"is_active" json:"is_active"`
+	IsTriggered     bool          `db:"is_triggered" json:"is_triggered"`
+	LastTriggeredAt *time.Time    `db:"last_triggered_at" json:"last_triggered_at,omitempty"`
+	CreatedAt       time.Time     `db:"created_at" json:"created_at"`
+	UpdatedAt       time.Time     `db:"updated_at" json:"updated_at"`
+}
+
+// ValidGoldAlertType reports whether t is a known alert type
+func ValidGoldAlertType(t GoldAlertType) bool {
+	switch t {
+	case GoldAlertPriceAbove, GoldAlertPriceBelow, GoldAlertDailyChange, GoldAlertPositionPL:
+		return true
+	}
+	return false
+}
+
+// Price returns the buy or buyback price of p
+func (k GoldPriceKind) Price(p GoldPrice) float64 {
+	if k == GoldPriceBuyback {
+		return p.BuybackPricePerGram
+	}
+	return p.BuyPricePerGram
+}
+
+// Column returns the gold_prices column holding the price of kind
+func (k GoldPriceKind) Column() string {
+	if k == GoldPriceBuyback {
+		return "buyback_price_per_gram"
+	}
+	return "buy_price_per_gram"
+}
+
+// Holds reports whether value, the price, percent change or P/L percent the
+// alert watches, is on the alerting side of the threshold
+func (a GoldPriceAlert) Holds(value float64) bool {
+	switch a.AlertType {
+	case GoldAlertPriceAbove:
+		return value >= a.Threshold
+	case GoldAlertPriceBelow:
+		return value <= a.Threshold
+	case GoldAlertDailyChange:
+		return math.Abs(value) >= a.Threshold
+	case GoldAlertPositionPL:
+		if a.Threshold < 0 {
+			return value <= a.Threshold
+		}
+		return value >= a.Threshold
+	}
+	return false
+}
+
+// PercentChange returns the move from previous to current in percent
+func PercentChange(previous, current float64) float64 {
+	if previous == 0 {
+		return 0
+	}
+	return (current - previous) / previous * 100
+}
+
+// PositionPL adds up the valued lots (of goldType, or all when nil) still
+// held. ok is false when none of them has a price.
+func PositionPL(assets []GoldAsset, goldType *GoldType) (cost, value, percent float64, ok bool) {
+	for _, a := range assets {
+		if a.RemainingWeightGram <= 0 || a.PriceDate == nil {
+			continue
+		}
+		if goldType != nil && a.GoldType != *goldType {
+			continue
+		}
+		cost += a.PurchaseValue
+		value += a.CurrentValue
+		ok = true
+	}
+	if ok && cost > 0 {
+		percent = (value - cost) / cost * 100
+	}
+	return cost, value, percent, ok
+}
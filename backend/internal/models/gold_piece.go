@@ -0,0 +1,173 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type GoldPackaging string
+
+const (
+	GoldPackagingSealed  GoldPackaging = "sealed"
+	GoldPackagingOpened  GoldPackaging = "opened"
+	GoldPackagingDamaged GoldPackaging = "damaged"
+	GoldPackagingNone    GoldPackaging = "none"
+)
+
+// GoldPiece - one physical item of a lot, e.g. one of the two 5g bars of a
+// 10g lot. Pieces are sold whole; SaleLotID is set once the piece is sold.
+// AssetID is empty for a sold piece whose lot was deleted.
+type GoldPiece struct {
+	ID                 uuid.UUID     `db:"id" json:"id"`
+	UserID             uuid.UUID     `db:"user_id" json:"user_id"`
+	AssetID            *uuid.UUID    `db:"asset_id" json:"asset_id,omitempty"`
+	DenominationGram   float64       `db:"denomination_gram" json:"denomination_gram"`
+	CertificateNumber  *string       `db:"certificate_number" json:"certificate_number,omitempty"`
+	MintingYear        *int          `db:"minting_year" json:"minting_year,omitempty"`
+	PackagingCondition GoldPackaging `db:"packaging_condition" json:"packaging_condition"`
+	Notes              string        `db:"notes" json:"notes"`
+	SaleLotID          *uuid.UUID    `db:"sale_lot_id" json:"sale_lot_id,omitempty"`
+	CreatedAt          time.Time     `db:"created_at" json:"created_at"`
+	UpdatedAt          time.Time     `db:"updated_at" json:"updated_at"`
+}
+
+// ValidGoldPackaging reports whether p is a known packaging condition
+func ValidGoldPackaging(p GoldPackaging) bool {
+	switch p {
+	case GoldPackagingSealed, GoldPackagingOpened, GoldPackagingDamaged, GoldPackagingNone:
+		return true
+	}
+	return false
+}
+
+// UnsoldPieceWeights adds up the denominations of the unsold pieces per lot
+func UnsoldPieceWeights(pieces []GoldPiece) map[uuid.UUID]float64 {
+	weights := make(map[uuid.UUID]float64)
+	for _, p := range pieces {
+		if p.SaleLotID == nil && p.AssetID != nil {
+			weights[*p.AssetID] = RoundGram(weights[*p.AssetID] + p.DenominationGram)
+		}
+	}
+	return weights
+}
+
+// LooseLots returns copies of lots with the remaining weight cut down to
+// what is not in an unsold piece, the weight that can be sold by the gram
+func LooseLots(lots []GoldAsset, pieces []GoldPiece) []GoldAsset {
+	inPieces := UnsoldPieceWeights(pieces)
+	loose := make([]GoldAsset, len(lots))
+	for i, lot := range lots {
+		loose[i] = lot
+		loose[i].RemainingWeightGram = RoundGram(lot.RemainingWeightGram - inPieces[lot.ID])
+		if loose[i].RemainingWeightGram < 0 {
+			loose[i].RemainingWeightGram = 0
+		}
+	}
+	return loose
+}
+
+// AssignPieces checks the selections against the lots' unsold pieces. A
+// selection naming pieces sells exactly those and gets their total weight;
+// one without sells weight by the gram, which must come from the lot's
+// loose weight.
+func AssignPieces(lots map[uuid.UUID]GoldAsset, selections []GoldLotSelection, pieces []GoldPiece) error {
+	byID := make(map[uuid.UUID]GoldPiece, len(pieces))
+	for _, p := range pieces {
+		byID[p.ID] = p
+	}
+	inPieces := UnsoldPieceWeights(pieces)
+
+	taken := make(map[uuid.UUID]bool)
+	looseTaken := make(map[uuid.UUID]float64)
+	for i := range selections {
+		sel := &selections[i]
+		lot, ok := lots[sel.AssetID]
+		if !ok {
+			return fmt.Errorf("lot %s not found", sel.AssetID)
+		}
+
+		if len(sel.PieceIDs) == 0 {
+			if sel.WeightGram <= 0 {
+				return fmt.Errorf("give weight_gram or piece_ids for lot %s", lot.Name)
+			}
+			looseTaken[lot.ID] = RoundGram(looseTaken[lot.ID] + sel.WeightGram)
+			if loose := RoundGram(lot.RemainingWeightGram - inPieces[lot.ID]); looseTaken[lot.ID] > loose {
+				return fmt.Errorf("lot %s has only %.4fg outside its pieces, sell the pieces by piece_ids", lot.Name, loose)
+			}
+			continue
+		}
+
+		if sel.WeightGram != 0 {
+			return fmt.Errorf("give either weight_gram or piece_ids for lot %s", lot.Name)
+		}
+		for _, id := range sel.PieceIDs {
+			p, ok := byID[id]
+			if !ok || p.SaleLotID != nil || p.AssetID == nil || *p.AssetID != lot.ID {
+				return fmt.Errorf("piece %s is not an unsold piece of lot %s", id, lot.Name)
+			}
+			if taken[id] {
+				return fmt.Errorf("piece %s is selected twice", id)
+			}
+			taken[id] = true
+			sel.WeightGram = RoundGram(sel.WeightGram + p.DenominationGram)
+		}
+	}
+	return nil
+}
+
+// GoldInventoryItem - a physical piece, or the loose weight of a lot that
+// is not in any piece (PieceID empty)
+type GoldInventoryItem struct {
+	PieceID            *uuid.UUID     `db:"piece_id" json:"piece_id,omitempty"`
+	AssetID            uuid.UUID      `db:"asset_id" json:"asset_id"`
+	AssetName          string         `db:"asset_name" json:"asset_name"`
+	GoldType           GoldType       `db:"gold_type" json:"gold_type"`
+	ItemKind           GoldItemKind   `db:"item_kind" json:"item_kind"`
+	Purity             float64        `db:"purity" json:"purity"`
+	StorageLocation    string         `db:"storage_location" json:"-"`
+	WeightGram         float64        `db:"weight_gram" json:"weight_gram"`
+	CertificateNumber  *string        `db:"certificate_number" json:"certificate_number,omitempty"`
+	MintingYear        *int           `db:"minting_year" json:"minting_year,omitempty"`
+	PackagingCondition *GoldPackaging `db:"packaging_condition" json:"packaging_condition,omitempty"`
+	PurchaseDate       time.Time      `db:"purchase_date" json:"purchase_date"`
+}
+
+// GoldInventoryLocation - everything held at one storage location
+type GoldInventoryLocation struct {
+	StorageLocation string              `json:"storage_location"`
+	PieceCount      int                 `json:"piece_count"`
+	WeightGram      float64             `json:"weight_gram"`
+	LooseWeightGram float64             `json:"loose_weight_gram"`
+	Items           []GoldInventoryItem `json:"items"`
+}
+
+// GroupInventory groups items by storage location, keeping their order
+func GroupInventory(items []GoldInventoryItem) []GoldInventoryLocation {
+	byLocation := make(map[string]*GoldInventoryLocation)
+	order := []string{}
+	for _, item := range items {
+		loc := byLocation[item.StorageLocation]
+		if loc == nil {
+			loc = &GoldInventoryLocation{StorageLocation: item.StorageLocation, Items: []GoldInventoryItem{}}
+			byLocation[item.StorageLocation] = loc
+			order = append(order, item.StorageLocation)
+		}
+		if item.PieceID != nil {
+			loc.PieceCount++
+		} else {
+			loc.LooseWeightGram = RoundGram(loc.LooseWeightGram + item.WeightGram)
+		}
+		loc.WeightGram = RoundGram(loc.WeightGram + item.WeightGram)
+		loc.Items = append(loc.Items, item)
+	}
+
+	locations := []GoldInventoryLocation{}
+	for _, name := range order {
+		locations = append(locations, *byLocation[name])
+	}
+	sort.SliceStable(locations, func(i, j int) bool { return locations[i].StorageLocation < locations[j].StorageLocation })
+	return locations
+}
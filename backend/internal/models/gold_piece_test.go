@@ -0,0 +1,133 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func goldPiece(lot GoldAsset, weight float64) GoldPiece {
+	return GoldPiece{ID: uuid.New(), AssetID: &lot.ID, DenominationGram: weight, PackagingCondition: GoldPackagingSealed}
+}
+
+// pieceLots returns lot A of 10g with 5g and 2.5g unsold pieces and a sold
+// 1g one, lot B of 3g without pieces, and all the pieces
+func pieceLots() (GoldAsset, GoldAsset, []GoldPiece) {
+	a := goldLot("A", 10, 1000000)
+	b := goldLot("B", 3, 1000000)
+	sold := goldPiece(a, 1)
+	saleLotID := uuid.New()
+	sold.SaleLotID = &saleLotID
+	orphan := GoldPiece{ID: uuid.New(), DenominationGram: 2, SaleLotID: &saleLotID}
+	return a, b, []GoldPiece{goldPiece(a, 5), goldPiece(a, 2.5), sold, orphan}
+}
+
+func TestUnsoldPieceWeights(t *testing.T) {
+	a, b, pieces := pieceLots()
+
+	weights := UnsoldPieceWeights(pieces)
+	if len(weights) != 1 || weights[a.ID] != 7.5 || weights[b.ID] != 0 {
+		t.Errorf("UnsoldPieceWeights() = %v, want 7.5g for lot A only", weights)
+	}
+}
+
+func TestLooseLots(t *testing.T) {
+	a, b, pieces := pieceLots()
+	over := goldLot("over", 1, 1000000)
+	pieces = append(pieces, goldPiece(over, 2))
+
+	lots := []GoldAsset{a, b, over}
+	loose := LooseLots(lots, pieces)
+	want := []float64{2.5, 3, 0}
+	for i, lot := range loose {
+		if lot.ID != lots[i].ID || lot.RemainingWeightGram != want[i] {
+			t.Errorf("lot %s = %.4fg loose, want %.4fg", lot.Name, lot.RemainingWeightGram, want[i])
+		}
+	}
+	if lots[0].RemainingWeightGram != 10 {
+		t.Errorf("LooseLots() changed the lot passed in to %.4fg", lots[0].RemainingWeightGram)
+	}
+}
+
+func TestAssignPieces(t *testing.T) {
+	a, b, pieces := pieceLots()
+	five, half, sold := pieces[0], pieces[1], pieces[2]
+	lots := map[uuid.UUID]GoldAsset{a.ID: a, b.ID: b}
+
+	tests := []struct {
+		name        string
+		selections  []GoldLotSelection
+		wantWeights []float64
+		wantErr     string
+	}{
+		{"loose weight", []GoldLotSelection{{AssetID: a.ID, WeightGram: 2.5}}, []float64{2.5}, ""},
+		{"lot without pieces", []GoldLotSelection{{AssetID: b.ID, WeightGram: 3}}, []float64{3}, ""},
+		{"pieces get their weight", []GoldLotSelection{{AssetID: a.ID, PieceIDs: []uuid.UUID{five.ID, half.ID}}}, []float64{7.5}, ""},
+		{"pieces and loose weight of one lot", []GoldLotSelection{{AssetID: a.ID, PieceIDs: []uuid.UUID{five.ID}}, {AssetID: a.ID, WeightGram: 2.5}}, []float64{5, 2.5}, ""},
+		{"more than the loose weight", []GoldLotSelection{{AssetID: a.ID, WeightGram: 2.5001}}, nil, "lot A has only 2.5000g outside its pieces, sell the pieces by piece_ids"},
+		{"loose weight over two selections", []GoldLotSelection{{AssetID: a.ID, WeightGram: 1.5}, {AssetID: a.ID, WeightGram: 1.5}}, nil, "lot A has only 2.5000g outside its pieces, sell the pieces by piece_ids"},
+		{"neither weight nor pieces", []GoldLotSelection{{AssetID: a.ID}}, nil, "give weight_gram or piece_ids for lot A"},
+		{"both weight and pieces", []GoldLotSelection{{AssetID: a.ID, WeightGram: 5, PieceIDs: []uuid.UUID{five.ID}}}, nil, "give either weight_gram or piece_ids for lot A"},
+		{"sold piece", []GoldLotSelection{{AssetID: a.ID, PieceIDs: []uuid.UUID{sold.ID}}}, nil, "piece " + sold.ID.String() + " is not an unsold piece of lot A"},
+		{"piece of another lot", []GoldLotSelection{{AssetID: b.ID, PieceIDs: []uuid.UUID{five.ID}}}, nil, "piece " + five.ID.String() + " is not an unsold piece of lot B"},
+		{"piece selected twice", []GoldLotSelection{{AssetID: a.ID, PieceIDs: []uuid.UUID{five.ID}}, {AssetID: a.ID, PieceIDs: []uuid.UUID{five.ID}}}, nil, "piece " + five.ID.String() + " is selected twice"},
+		{"unknown lot", []GoldLotSelection{{AssetID: uuid.Nil, WeightGram: 1}}, nil, "lot " + uuid.Nil.String() + " not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := AssignPieces(lots, tt.selections, pieces)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("AssignPieces() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AssignPieces() error = %v", err)
+			}
+			for i, sel := range tt.selections {
+				if sel.WeightGram != tt.wantWeights[i] {
+					t.Errorf("selection %d weight = %.4fg, want %.4fg", i, sel.WeightGram, tt.wantWeights[i])
+				}
+			}
+		})
+	}
+}
+
+func TestGroupInventory(t *testing.T) {
+	pieceID := uuid.New()
+	otherPieceID := uuid.New()
+	items := []GoldInventoryItem{
+		{PieceID: &pieceID, AssetName: "A", StorageLocation: "Safe", WeightGram: 5},
+		{AssetName: "B", StorageLocation: "Bank", WeightGram: 2.5},
+		{AssetName: "A", StorageLocation: "Safe", WeightGram: 0.1},
+		{PieceID: &otherPieceID, AssetName: "C", StorageLocation: "Safe", WeightGram: 0.2},
+		{AssetName: "D", StorageLocation: "", WeightGram: 1},
+	}
+
+	locations := GroupInventory(items)
+	want := []struct {
+		name   string
+		pieces int
+		weight float64
+		loose  float64
+		items  int
+	}{
+		{"", 0, 1, 1, 1},
+		{"Bank", 0, 2.5, 2.5, 1},
+		{"Safe", 2, 5.3, 0.1, 3},
+	}
+	if len(locations) != len(want) {
+		t.Fatalf("GroupInventory() = %d locations, want %d", len(locations), len(want))
+	}
+	for i, loc := range locations {
+		w := want[i]
+		if loc.StorageLocation != w.name || loc.PieceCount != w.pieces || loc.WeightGram != w.weight || loc.LooseWeightGram != w.loose || len(loc.Items) != w.items {
+			t.Errorf("location %d = %q: %d pieces, %.4fg (%.4fg loose), %d items, want %q: %d, %.4fg (%.4fg), %d",
+				i, loc.StorageLocation, loc.PieceCount, loc.WeightGram, loc.LooseWeightGram, len(loc.Items), w.name, w.pieces, w.weight, w.loose, w.items)
+		}
+	}
+	if safe := locations[2].Items; safe[0].AssetName != "A" || safe[1].AssetName != "A" || safe[2].AssetName != "C" {
+		t.Errorf("safe items out of order: %+v", safe)
+	}
+}
@@ -0,0 +1,231 @@
+package models
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type GoldPriceAction string
+
+const (
+	GoldPriceCreated GoldPriceAction = "create"
+	GoldPriceUpdated GoldPriceAction = "update"
+	GoldPriceDeleted GoldPriceAction = "delete"
+)
+
+// GoldPriceChange - one audited write to gold_prices. Old prices are empty
+// for a create and new prices for a delete. ChangedBy is empty for changes
+// made by the scheduled fetcher.
+type GoldPriceChange struct {
+	ID                     uuid.UUID       `db:"id" json:"id"`
+	PriceDate              time.Time       `db:"price_date" json:"price_date"`
+	GoldType               GoldType        `db:"gold_type" json:"gold_type"`
+	Action                 GoldPriceAction `db:"action" json:"action"`
+	OldBuyPricePerGram     *float64        `db:"old_buy_price_per_gram" json:"old_buy_price_per_gram"`
+	OldBuybackPricePerGram *float64        `db:"old_buyback_price_per_gram" json:"old_buyback_price_per_gram"`
+	NewBuyPricePerGram     *float64        `db:"new_buy_price_per_gram" json:"new_buy_price_per_gram"`
+	NewBuybackPricePerGram *float64        `db:"new_buyback_price_per_gram" json:"new_buyback_price_per_gram"`
+	Source                 string          `db:"source" json:"source"`
+	ChangedBy              *uuid.UUID      `db:"changed_by" json:"changed_by,omitempty"`
+	ChangedByEmail         string          `db:"changed_by_email" json:"changed_by_email,omitempty"`
+	ChangedAt              time.Time       `db:"changed_at" json:"changed_at"`
+}
+
+// GoldPriceImportError - why one line of an import was rejected
+type GoldPriceImportError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// GoldPriceImportResult - counts of an import; nothing is written when
+// there are errors
+type GoldPriceImportResult struct {
+	Rows      int                    `json:"rows"`
+	Created   int                    `json:"created"`
+	Updated   int                    `json:"updated"`
+	Unchanged int                    `json:"unchanged"`
+	Errors    []GoldPriceImportError `json:"errors,omitempty"`
+}
+
+// MaxGoldPriceImportRows bounds the size of one CSV import
+const MaxGoldPriceImportRows = 10000
+
+var goldPriceCSVColumns = []string{"date", "gold_type", "buy_price_per_gram", "buyback_price_per_gram"}
+
+// ParseGoldPriceCSV reads historical quotes with the header
+// date,gold_type,buy_price_per_gram,buyback_price_per_gram and an optional
+// source column. Rows without a source get defaultSource. Every invalid
+// line is reported; a date and brand may only appear once.
+func ParseGoldPriceCSV(r io.Reader, defaultSource string) ([]GoldPrice, []GoldPriceImportError, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil, errors.New("CSV file is empty")
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid CSV: %w", err)
+	}
+
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, name := range goldPriceCSVColumns {
+		if _, ok := index[name]; !ok {
+			return nil, nil, fmt.Errorf("CSV header must contain %s", strings.Join(goldPriceCSVColumns, ","))
+		}
+	}
+	sourceIndex, hasSource := index["source"]
+
+	field := func(record []string, i int) string {
+		if i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	prices := []GoldPrice{}
+	importErrors := []GoldPriceImportError{}
+	seen := make(map[string]int)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				importErrors = append(importErrors, GoldPriceImportError{Line: parseErr.Line, Error: parseErr.Err.Error()})
+				continue
+			}
+			return nil, nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+		if len(prices)+len(importErrors) >= MaxGoldPriceImportRows {
+			return nil, nil, fmt.Errorf("CSV may have at most %d rows", MaxGoldPriceImportRows)
+		}
+
+		price, err := parseGoldPriceRecord(
+			field(record, index["date"]),
+			field(record, index["gold_type"]),
+			field(record, index["buy_price_per_gram"]),
+			field(record, index["buyback_price_per_gram"]),
+		)
+		if err != nil {
+			importErrors = append(importErrors, GoldPriceImportError{Line: line, Error: err.Error()})
+			continue
+		}
+
+		key := price.PriceDate.Format("2006-01-02") + "/" + string(price.GoldType)
+		if first, ok := seen[key]; ok {
+			importErrors = append(importErrors, GoldPriceImportError{Line: line, Error: fmt.Sprintf("duplicate of line %d", first)})
+			continue
+		}
+		seen[key] = line
+
+		price.Source = defaultSource
+		if hasSource && field(record, sourceIndex) != "" {
+			price.Source = field(record, sourceIndex)
+		}
+		prices = append(prices, price)
+	}
+
+	return prices, importErrors, nil
+}
+
+func parseGoldPriceRecord(date, goldType, buy, buyback string) (GoldPrice, error) {
+	priceDate, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return GoldPrice{}, fmt.Errorf("invalid date %q (use YYYY-MM-DD)", date)
+	}
+	if priceDate.After(time.Now()) {
+		return GoldPrice{}, fmt.Errorf("date %s is in the future", date)
+	}
+	if !ValidGoldType(GoldType(goldType)) {
+		return GoldPrice{}, fmt.Errorf("invalid gold type %q", goldType)
+	}
+	buyPrice, err := strconv.ParseFloat(buy, 64)
+	if err != nil || buyPrice <= 0 {
+		return GoldPrice{}, fmt.Errorf("buy_price_per_gram must be a positive number")
+	}
+	buybackPrice, err := strconv.ParseFloat(buyback, 64)
+	if err != nil || buybackPrice <= 0 {
+		return GoldPrice{}, fmt.Errorf("buyback_price_per_gram must be a positive number")
+	}
+	if buybackPrice > buyPrice {
+		return GoldPrice{}, fmt.Errorf("buyback_price_per_gram cannot exceed buy_price_per_gram")
+	}
+	return GoldPrice{
+		PriceDate:           priceDate,
+		GoldType:            GoldType(goldType),
+		BuyPricePerGram:     roundMoney(buyPrice),
+		BuybackPricePerGram: roundMoney(buybackPrice),
+	}, nil
+}
+
+type GoldPriceInterval string
+
+const (
+	GoldPriceWeekly  GoldPriceInterval = "weekly"
+	GoldPriceMonthly GoldPriceInterval = "monthly"
+)
+
+// GoldOHLC - open, high, low and close of one brand over a week (starting
+// Monday) or calendar month. FirstDate and LastDate are the days of the
+// opening and closing quotes.
+type GoldOHLC struct {
+	PeriodStart time.Time `db:"period_start" json:"period_start"`
+	FirstDate   time.Time `db:"first_date" json:"first_date"`
+	LastDate    time.Time `db:"last_date" json:"last_date"`
+	Open        float64   `db:"open" json:"open"`
+	High        float64   `db:"high" json:"high"`
+	Low         float64   `db:"low" json:"low"`
+	Close       float64   `db:"close" json:"close"`
+	Quotes      int       `db:"quotes" json:"quotes"`
+}
+
+// GoldMovingAverage - the averages of the quotes in the 7, 30 and 90
+// calendar days up to a quote. An average is empty while the price history
+// does not cover its whole window yet.
+type GoldMovingAverage struct {
+	PriceDate time.Time `db:"price_date" json:"price_date"`
+	Price     float64   `db:"price" json:"price"`
+	MA7       *float64  `db:"ma_7" json:"ma_7"`
+	MA30      *float64  `db:"ma_30" json:"ma_30"`
+	MA90      *float64  `db:"ma_90" json:"ma_90"`
+}
+
+// GoldPriceRangeChange - the move from the last quote on or before the
+// start of a range (FromDate) to the latest quote. Empty when there is no
+// quote that old.
+type GoldPriceRangeChange struct {
+	Range         string     `db:"range" json:"range"`
+	FromDate      *time.Time `db:"from_date" json:"from_date"`
+	FromPrice     *float64   `db:"from_price" json:"from_price"`
+	Change        *float64   `db:"change" json:"change"`
+	ChangePercent *float64   `db:"change_percent" json:"change_percent"`
+}
+
+// GoldPriceStats - the latest quote of a brand on or before a day, its
+// changes over common ranges and its 52-week high and low
+type GoldPriceStats struct {
+	GoldType       GoldType               `db:"-" json:"gold_type"`
+	PriceKind      GoldPriceKind          `db:"-" json:"price_kind"`
+	PriceDate      time.Time              `db:"price_date" json:"price_date"`
+	Price          float64                `db:"price" json:"price"`
+	High52Week     float64                `db:"high_52_week" json:"high_52_week"`
+	High52WeekDate time.Time              `db:"high_52_week_date" json:"high_52_week_date"`
+	Low52Week      float64                `db:"low_52_week" json:"low_52_week"`
+	Low52WeekDate  time.Time              `db:"low_52_week_date" json:"low_52_week_date"`
+	Changes        []GoldPriceRangeChange `db:"-" json:"changes"`
+}
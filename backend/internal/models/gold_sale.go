@@ -0,0 +1,167 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type GoldSaleMethod string
+
+const (
+	GoldSaleFIFO     GoldSaleMethod = "fifo"
+	GoldSaleSpecific GoldSaleMethod = "specific"
+)
+
+// GoldSale - gold sold from one or more lots (gold assets). Proceeds are the
+// sale value minus the fee; the realized gain is proceeds minus the cost of
+// the weight sold.
+type GoldSale struct {
+	ID            uuid.UUID  `db:"id" json:"id"`
+	UserID        uuid.UUID  `db:"user_id" json:"user_id"`
+	AccountID     *uuid.UUID `db:"account_id" json:"account_id,omitempty"`
+	TransactionID *uuid.UUID `db:"transaction_id" json:"transaction_id,omitempty"`
+	SaleDate      time.Time  `db:"sale_date" json:"sale_date"`
+	WeightGram    float64    `db:"weight_gram" json:"weight_gram"`
+	PricePerGram  float64    `db:"price_per_gram" json:"price_per_gram"`
+	Fee           float64    `db:"fee" json:"fee"`
+	Proceeds      float64    `db:"proceeds" json:"proceeds"`
+	CostBasis     float64    `db:"cost_basis" json:"cost_basis"`
+	RealizedGain  float64    `db:"realized_gain" json:"realized_gain"`
+	Notes         string     `db:"notes" json:"notes"`
+	CreatedAt     time.Time  `db:"created_at" json:"created_at"`
+	// For response only
+	Lots []GoldSaleLot `db:"-" json:"lots"`
+}
+
+type GoldSaleLot struct {
+	ID           uuid.UUID  `db:"id" json:"id"`
+	SaleID       uuid.UUID  `db:"sale_id" json:"sale_id"`
+	AssetID      *uuid.UUID `db:"asset_id" json:"asset_id,omitempty"`
+	GoldType     GoldType   `db:"gold_type" json:"gold_type"`
+	WeightGram   float64    `db:"weight_gram" json:"weight_gram"`
+	CostPerGram  float64    `db:"cost_per_gram" json:"cost_per_gram"`
+	Proceeds     float64    `db:"proceeds" json:"proceeds"`
+	RealizedGain float64    `db:"realized_gain" json:"realized_gain"`
+	// The pieces sold whole from the lot, for response only
+	Pieces []GoldPiece `db:"-" json:"pieces,omitempty"`
+}
+
+// GoldLotSelection - weight to take from one lot, either by the gram or as
+// whole pieces (see AssignPieces)
+type GoldLotSelection struct {
+	AssetID    uuid.UUID   `json:"asset_id" binding:"required"`
+	WeightGram float64     `json:"weight_gram" binding:"omitempty,gt=0"`
+	PieceIDs   []uuid.UUID `json:"piece_ids"`
+}
+
+// RealizedGoldSummary - totals over all sales of a user
+type RealizedGoldSummary struct {
+	SalesCount    int     `db:"sales_count" json:"sales_count"`
+	WeightSold    float64 `db:"weight_sold" json:"weight_sold"`
+	TotalProceeds float64 `db:"total_proceeds" json:"total_proceeds"`
+	TotalCost     float64 `db:"total_cost" json:"total_cost"`
+	RealizedGain  float64 `db:"realized_gain" json:"realized_gain"`
+}
+
+// RoundGram rounds a weight to the 4 decimals stored for grams
+func RoundGram(weight float64) float64 {
+	return math.Round(weight*10000) / 10000
+}
+
+func roundMoney(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// AllocateFIFO takes weight from lots in the order given (oldest first),
+// emptying each before moving to the next
+func AllocateFIFO(lots []GoldAsset, weight float64) ([]GoldLotSelection, error) {
+	weight = RoundGram(weight)
+	selections := []GoldLotSelection{}
+	for _, lot := range lots {
+		if weight <= 0 {
+			break
+		}
+		take := math.Min(lot.RemainingWeightGram, weight)
+		if take <= 0 {
+			continue
+		}
+		selections = append(selections, GoldLotSelection{AssetID: lot.ID, WeightGram: take})
+		weight = RoundGram(weight - take)
+	}
+	if weight > 0 {
+		return nil, fmt.Errorf("only %.4fg is held, %.4fg short", totalRemaining(lots), weight)
+	}
+	return selections, nil
+}
+
+func totalRemaining(lots []GoldAsset) float64 {
+	total := 0.0
+	for _, lot := range lots {
+		total += lot.RemainingWeightGram
+	}
+	return RoundGram(total)
+}
+
+// Allocate prices the sale over the selected lots. The fee is split over the
+// lots by weight and the last lot absorbs rounding, so lot proceeds and gains
+// add up to the sale's. lots must contain every selected asset, none of them
+// bought after the sale date.
+func (s *GoldSale) Allocate(lots map[uuid.UUID]GoldAsset, selections []GoldLotSelection) error {
+	if len(selections) == 0 {
+		return fmt.Errorf("no lots selected")
+	}
+
+	s.WeightGram = 0
+	taken := make(map[uuid.UUID]float64)
+	for _, sel := range selections {
+		lot, ok := lots[sel.AssetID]
+		if !ok {
+			return fmt.Errorf("lot %s not found", sel.AssetID)
+		}
+		if lot.PurchaseDate.After(s.SaleDate) {
+			return fmt.Errorf("lot %s was bought after the sale date", lot.Name)
+		}
+		taken[lot.ID] = RoundGram(taken[lot.ID] + sel.WeightGram)
+		if taken[lot.ID] > lot.RemainingWeightGram {
+			return fmt.Errorf("lot %s holds only %.4fg", lot.Name, lot.RemainingWeightGram)
+		}
+		s.WeightGram = RoundGram(s.WeightGram + sel.WeightGram)
+	}
+
+	s.Proceeds = roundMoney(s.WeightGram*s.PricePerGram - s.Fee)
+	if s.Proceeds < 0 {
+		return fmt.Errorf("fee exceeds the sale value")
+	}
+	s.CostBasis = 0
+	s.Lots = make([]GoldSaleLot, len(selections))
+
+	allocated := 0.0
+	for i, sel := range selections {
+		lot := lots[sel.AssetID]
+		assetID := lot.ID
+		proceeds := roundMoney(s.Proceeds * sel.WeightGram / s.WeightGram)
+		if i == len(selections)-1 {
+			proceeds = roundMoney(s.Proceeds - allocated)
+		}
+		allocated += proceeds
+		cost := roundMoney(RoundGram(sel.WeightGram) * lot.PurchasePricePerGram)
+
+		s.Lots[i] = GoldSaleLot{
+			ID:           uuid.New(),
+			SaleID:       s.ID,
+			AssetID:      &assetID,
+			GoldType:     lot.GoldType,
+			WeightGram:   RoundGram(sel.WeightGram),
+			CostPerGram:  lot.PurchasePricePerGram,
+			Proceeds:     proceeds,
+			RealizedGain: roundMoney(proceeds - cost),
+		}
+		s.CostBasis += cost
+	}
+	s.CostBasis = roundMoney(s.CostBasis)
+	s.RealizedGain = roundMoney(s.Proceeds - s.CostBasis)
+	return nil
+}
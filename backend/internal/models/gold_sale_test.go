@@ -0,0 +1,132 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func goldLot(name string, remaining, pricePerGram float64) GoldAsset {
+	return GoldAsset{
+		ID:                   uuid.New(),
+		Name:                 name,
+		GoldType:             GoldTypeAntam,
+		ItemKind:             GoldItemBar,
+		Purity:               1,
+		WeightGram:           remaining,
+		RemainingWeightGram:  remaining,
+		PurchasePricePerGram: pricePerGram,
+	}
+}
+
+func TestAllocateFIFO(t *testing.T) {
+	a := goldLot("A", 2, 1000000)
+	b := goldLot("B", 3, 1100000)
+	empty := goldLot("empty", 0, 1000000)
+	tenth := goldLot("tenth", 0.1, 1000000)
+	fifth := goldLot("fifth", 0.2, 1000000)
+
+	tests := []struct {
+		name    string
+		lots    []GoldAsset
+		weight  float64
+		want    []GoldLotSelection
+		wantErr string
+	}{
+		{"oldest lot first", []GoldAsset{a, b}, 1.5, []GoldLotSelection{{AssetID: a.ID, WeightGram: 1.5}}, ""},
+		{"spills into the next lot", []GoldAsset{a, b}, 4, []GoldLotSelection{{AssetID: a.ID, WeightGram: 2}, {AssetID: b.ID, WeightGram: 2}}, ""},
+		{"everything held", []GoldAsset{a, b}, 5, []GoldLotSelection{{AssetID: a.ID, WeightGram: 2}, {AssetID: b.ID, WeightGram: 3}}, ""},
+		{"skips empty lots", []GoldAsset{empty, b}, 1, []GoldLotSelection{{AssetID: b.ID, WeightGram: 1}}, ""},
+		{"rounds float noise", []GoldAsset{tenth, fifth}, 0.1 + 0.2, []GoldLotSelection{{AssetID: tenth.ID, WeightGram: 0.1}, {AssetID: fifth.ID, WeightGram: 0.2}}, ""},
+		{"more than held", []GoldAsset{a, b}, 6, nil, "only 5.0000g is held, 1.0000g short"},
+		{"no lots", nil, 1, nil, "only 0.0000g is held, 1.0000g short"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := AllocateFIFO(tt.lots, tt.weight)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("AllocateFIFO() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AllocateFIFO() error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("AllocateFIFO() = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i].AssetID != tt.want[i].AssetID || got[i].WeightGram != tt.want[i].WeightGram {
+					t.Errorf("selection %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestGoldSaleAllocate(t *testing.T) {
+	a := goldLot("A", 2, 1000000)
+	b := goldLot("B", 3, 1100000)
+	lots := map[uuid.UUID]GoldAsset{a.ID: a, b.ID: b}
+
+	sale := &GoldSale{ID: uuid.New(), PricePerGram: 1333333.33, Fee: 10000}
+	err := sale.Allocate(lots, []GoldLotSelection{{AssetID: a.ID, WeightGram: 1}, {AssetID: b.ID, WeightGram: 2}})
+	if err != nil {
+		t.Fatalf("Allocate() error = %v", err)
+	}
+
+	if sale.WeightGram != 3 || sale.Proceeds != 3989999.99 || sale.CostBasis != 3200000 || sale.RealizedGain != 789999.99 {
+		t.Errorf("sale = %.4fg, proceeds %.2f, cost %.2f, gain %.2f, want 3g, 3989999.99, 3200000, 789999.99",
+			sale.WeightGram, sale.Proceeds, sale.CostBasis, sale.RealizedGain)
+	}
+	if len(sale.Lots) != 2 {
+		t.Fatalf("lots = %d, want 2", len(sale.Lots))
+	}
+	// The fee is split by weight and the last lot absorbs the rounding
+	if sale.Lots[0].Proceeds != 1330000 || sale.Lots[1].Proceeds != 2659999.99 {
+		t.Errorf("lot proceeds = %.2f, %.2f, want 1330000, 2659999.99", sale.Lots[0].Proceeds, sale.Lots[1].Proceeds)
+	}
+	if sale.Lots[0].RealizedGain != 330000 || sale.Lots[1].RealizedGain != 459999.99 {
+		t.Errorf("lot gains = %.2f, %.2f, want 330000, 459999.99", sale.Lots[0].RealizedGain, sale.Lots[1].RealizedGain)
+	}
+	if total := roundMoney(sale.Lots[0].Proceeds + sale.Lots[1].Proceeds); total != sale.Proceeds {
+		t.Errorf("lot proceeds add up to %.2f, want %.2f", total, sale.Proceeds)
+	}
+	if *sale.Lots[1].AssetID != b.ID || sale.Lots[1].SaleID != sale.ID || sale.Lots[1].CostPerGram != 1100000 {
+		t.Errorf("lot = %+v, want lot B of the sale at its cost", sale.Lots[1])
+	}
+}
+
+func TestGoldSaleAllocateRejects(t *testing.T) {
+	a := goldLot("A", 2, 1000000)
+	late := goldLot("late", 2, 1000000)
+	saleDate := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	late.PurchaseDate = saleDate.AddDate(0, 0, 1)
+	lots := map[uuid.UUID]GoldAsset{a.ID: a, late.ID: late}
+
+	tests := []struct {
+		name       string
+		selections []GoldLotSelection
+		fee        float64
+		wantErr    string
+	}{
+		{"no selections", nil, 0, "no lots selected"},
+		{"unknown lot", []GoldLotSelection{{AssetID: uuid.New(), WeightGram: 1}}, 0, "not found"},
+		{"more than the lot holds", []GoldLotSelection{{AssetID: a.ID, WeightGram: 2.0001}}, 0, "lot A holds only 2.0000g"},
+		{"same lot twice over its weight", []GoldLotSelection{{AssetID: a.ID, WeightGram: 1.5}, {AssetID: a.ID, WeightGram: 1}}, 0, "lot A holds only 2.0000g"},
+		{"fee above the sale value", []GoldLotSelection{{AssetID: a.ID, WeightGram: 1}}, 1000001, "fee exceeds the sale value"},
+		{"lot bought after the sale", []GoldLotSelection{{AssetID: late.ID, WeightGram: 1}}, 0, "lot late was bought after the sale date"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sale := &GoldSale{ID: uuid.New(), SaleDate: saleDate, PricePerGram: 1000000, Fee: tt.fee}
+			err := sale.Allocate(lots, tt.selections)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Allocate() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package models
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ValuationInterval string
+
+const (
+	ValuationDaily   ValuationInterval = "daily"
+	ValuationMonthly ValuationInterval = "monthly"
+)
+
+// GoldValuationPoint - the gold position at the end of one day. Weight,
+// cost basis and market value cover what was held that day; realized gain is
+// cumulative over sales up to the day. Weight without any quote yet is
+// reported as unpriced and left out of the market value.
+type GoldValuationPoint struct {
+	Date                time.Time `json:"date"`
+	WeightGram          float64   `json:"weight_gram"`
+	CostBasis           float64   `json:"cost_basis"`
+	MarketValue         float64   `json:"market_value"`
+	UnrealizedPL        float64   `json:"unrealized_profit_loss"`
+	UnrealizedPLPercent float64   `json:"unrealized_profit_loss_percent"`
+	RealizedGain        float64   `json:"realized_gain"`
+	UnpricedWeightGram  float64   `json:"unpriced_weight_gram,omitempty"`
+}
+
+// GoldTypeValuation - the part of a valuation held in one brand.
+// PricePerGram is the 24k quote; each lot is valued at it scaled by its
+// value factor (see GoldAsset.ValueFactor).
+type GoldTypeValuation struct {
+	GoldType       GoldType   `json:"gold_type"`
+	WeightGram     float64    `json:"weight_gram"`
+	FineWeightGram float64    `json:"fine_weight_gram"`
+	CostBasis      float64    `json:"cost_basis"`
+	PricePerGram   float64    `json:"price_per_gram"`
+	PriceType      GoldType   `json:"price_gold_type,omitempty"`
+	PriceDate      *time.Time `json:"price_date,omitempty"`
+	MarketValue    float64    `json:"market_value"`
+	UnrealizedPL   float64    `json:"unrealized_profit_loss"`
+	// weight held, each lot scaled by its value factor
+	valueGram float64
+}
+
+type GoldValuation struct {
+	GoldValuationPoint
+	ByType []GoldTypeValuation `json:"by_type"`
+}
+
+// GoldLotSale - weight taken from a lot by a sale
+type GoldLotSale struct {
+	AssetID    uuid.UUID `db:"asset_id"`
+	SaleDate   time.Time `db:"sale_date"`
+	WeightGram float64   `db:"weight_gram"`
+}
+
+// GoldRealization - the realized gain of one sale
+type GoldRealization struct {
+	SaleDate     time.Time `db:"sale_date"`
+	RealizedGain float64   `db:"realized_gain"`
+}
+
+// GoldLedger - a user's lots, sales and the price history, enough to value
+// the position on any past day. Prices must be sorted by date ascending.
+type GoldLedger struct {
+	Assets       []GoldAsset
+	LotSales     []GoldLotSale
+	Realizations []GoldRealization
+	Prices       []GoldPrice
+}
+
+func dayOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// QuotesAt returns each brand's latest quote on or before day, so days
+// without a quote carry the previous one forward
+func (l *GoldLedger) QuotesAt(day time.Time) GoldQuotes {
+	day = dayOf(day)
+	quotes := GoldQuotes{}
+	for _, p := range l.Prices {
+		if dayOf(p.PriceDate).After(day) {
+			break
+		}
+		quotes[p.GoldType] = p
+	}
+	return quotes
+}
+
+// ValueAt values the position held at the end of day with the given quotes
+// (see GoldQuotes.For for the per-brand fallbacks)
+func (l *GoldLedger) ValueAt(day time.Time, quotes GoldQuotes) GoldValuation {
+	day = dayOf(day)
+	sold := make(map[uuid.UUID]float64)
+	for _, s := range l.LotSales {
+		if !dayOf(s.SaleDate).After(day) {
+			sold[s.AssetID] += s.WeightGram
+		}
+	}
+
+	byType := make(map[GoldType]*GoldTypeValuation)
+	for _, a := range l.Assets {
+		if dayOf(a.PurchaseDate).After(day) {
+			continue
+		}
+		held := RoundGram(a.WeightGram - sold[a.ID])
+		if held <= 0 {
+			continue
+		}
+		v := byType[a.GoldType]
+		if v == nil {
+			v = &GoldTypeValuation{GoldType: a.GoldType}
+			byType[a.GoldType] = v
+		}
+		v.WeightGram = RoundGram(v.WeightGram + held)
+		v.FineWeightGram = RoundGram(v.FineWeightGram + held*a.Purity)
+		v.valueGram += held * a.ValueFactor()
+		v.CostBasis += held * a.PurchasePricePerGram
+	}
+
+	valuation := GoldValuation{
+		GoldValuationPoint: GoldValuationPoint{Date: day},
+		ByType:             []GoldTypeValuation{},
+	}
+	for _, r := range l.Realizations {
+		if !dayOf(r.SaleDate).After(day) {
+			valuation.RealizedGain += r.RealizedGain
+		}
+	}
+
+	pricedCost := 0.0
+	for _, v := range byType {
+		valuation.WeightGram = RoundGram(valuation.WeightGram + v.WeightGram)
+		valuation.CostBasis += v.CostBasis
+		if price := quotes.For(v.GoldType); price != nil {
+			priceDate := price.PriceDate
+			v.PricePerGram = price.BuybackPricePerGram
+			v.PriceType = price.GoldType
+			v.PriceDate = &priceDate
+			v.MarketValue = v.valueGram * price.BuybackPricePerGram
+			v.UnrealizedPL = v.MarketValue - v.CostBasis
+			valuation.MarketValue += v.MarketValue
+			valuation.UnrealizedPL += v.UnrealizedPL
+			pricedCost += v.CostBasis
+		} else {
+			valuation.UnpricedWeightGram = RoundGram(valuation.UnpricedWeightGram + v.WeightGram)
+		}
+		valuation.ByType = append(valuation.ByType, *v)
+	}
+	if pricedCost > 0 {
+		valuation.UnrealizedPLPercent = valuation.UnrealizedPL / pricedCost * 100
+	}
+	sort.Slice(valuation.ByType, func(i, j int) bool {
+		return valuation.ByType[i].GoldType < valuation.ByType[j].GoldType
+	})
+
+	return valuation
+}
+
+// Series values the position on every day from from to to (daily) or at
+// every month end in between and on to itself (monthly)
+func (l *GoldLedger) Series(from, to time.Time, interval ValuationInterval) []GoldValuationPoint {
+	points := []GoldValuationPoint{}
+	for _, day := range SeriesDates(from, to, interval) {
+		points = append(points, l.ValueAt(day, l.QuotesAt(day)).GoldValuationPoint)
+	}
+	return points
+}
+
+// SeriesDates lists the days a series is valued on
+func SeriesDates(from, to time.Time, interval ValuationInterval) []time.Time {
+	from, to = dayOf(from), dayOf(to)
+	days := []time.Time{}
+	if interval == ValuationDaily {
+		for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+			days = append(days, day)
+		}
+		return days
+	}
+
+	for month := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, time.UTC); !month.After(to); month = month.AddDate(0, 1, 0) {
+		monthEnd := month.AddDate(0, 1, -1)
+		if monthEnd.After(to) {
+			monthEnd = to
+		}
+		days = append(days, monthEnd)
+	}
+	return days
+}
@@ -0,0 +1,181 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func goldQuote(goldType GoldType, day time.Time, buyback float64) GoldPrice {
+	return GoldPrice{ID: uuid.New(), PriceDate: day, GoldType: goldType, BuyPricePerGram: buyback + 10, BuybackPricePerGram: buyback}
+}
+
+func testLedger() (*GoldLedger, GoldAsset, GoldAsset) {
+	a := goldLot("A", 2, 100)
+	a.PurchaseDate = date(2025, 1, 1)
+	b := goldLot("B", 1, 80)
+	b.GoldType = GoldTypeUBS
+	b.PurchaseDate = date(2025, 1, 5)
+
+	return &GoldLedger{
+		Assets:       []GoldAsset{a, b},
+		LotSales:     []GoldLotSale{{AssetID: a.ID, SaleDate: date(2025, 1, 4), WeightGram: 0.5}},
+		Realizations: []GoldRealization{{SaleDate: date(2025, 1, 4), RealizedGain: 10}},
+		Prices: []GoldPrice{
+			goldQuote(GoldTypeAntam, date(2025, 1, 1), 110),
+			goldQuote(GoldTypeUBS, date(2025, 1, 2), 95),
+			goldQuote(GoldTypeAntam, date(2025, 1, 3), 120),
+		},
+	}, a, b
+}
+
+func TestGoldLedgerQuotesAt(t *testing.T) {
+	ledger, _, _ := testLedger()
+
+	tests := []struct {
+		name string
+		day  time.Time
+		want map[GoldType]float64
+	}{
+		{"before any quote", date(2024, 12, 31), map[GoldType]float64{}},
+		{"first day", date(2025, 1, 1), map[GoldType]float64{GoldTypeAntam: 110}},
+		{"carried forward", date(2025, 1, 2), map[GoldType]float64{GoldTypeAntam: 110, GoldTypeUBS: 95}},
+		{"newer quote replaces", date(2025, 1, 3), map[GoldType]float64{GoldTypeAntam: 120, GoldTypeUBS: 95}},
+		{"time of day is ignored", time.Date(2025, 1, 3, 18, 0, 0, 0, time.UTC), map[GoldType]float64{GoldTypeAntam: 120, GoldTypeUBS: 95}},
+		{"long after", date(2025, 6, 1), map[GoldType]float64{GoldTypeAntam: 120, GoldTypeUBS: 95}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quotes := ledger.QuotesAt(tt.day)
+			if len(quotes) != len(tt.want) {
+				t.Fatalf("QuotesAt() = %d quotes, want %d", len(quotes), len(tt.want))
+			}
+			for goldType, buyback := range tt.want {
+				if quotes[goldType].BuybackPricePerGram != buyback {
+					t.Errorf("%s = %.2f, want %.2f", goldType, quotes[goldType].BuybackPricePerGram, buyback)
+				}
+			}
+		})
+	}
+}
+
+func TestGoldLedgerValueAt(t *testing.T) {
+	ledger, _, _ := testLedger()
+
+	tests := []struct {
+		name         string
+		day          time.Time
+		weight       float64
+		cost         float64
+		market       float64
+		realized     float64
+		types        int
+		pricePerGram map[GoldType]float64
+	}{
+		{"before the first purchase", date(2024, 12, 31), 0, 0, 0, 0, 0, nil},
+		// Only an older antam quote, the ubs one is newer
+		{"brand's own older quote", date(2025, 1, 2), 2, 200, 220, 0, 1, map[GoldType]float64{GoldTypeAntam: 110}},
+		{"sale day counts the sale", date(2025, 1, 4), 1.5, 150, 180, 10, 1, map[GoldType]float64{GoldTypeAntam: 120}},
+		{"second brand bought", date(2025, 1, 5), 2.5, 230, 275, 10, 2, map[GoldType]float64{GoldTypeAntam: 120, GoldTypeUBS: 95}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := ledger.ValueAt(tt.day, ledger.QuotesAt(tt.day))
+			if v.WeightGram != tt.weight || v.CostBasis != tt.cost || v.MarketValue != tt.market || v.RealizedGain != tt.realized {
+				t.Errorf("ValueAt() = %.4fg, cost %.2f, market %.2f, realized %.2f, want %.4fg, %.2f, %.2f, %.2f",
+					v.WeightGram, v.CostBasis, v.MarketValue, v.RealizedGain, tt.weight, tt.cost, tt.market, tt.realized)
+			}
+			if v.UnrealizedPL != tt.market-tt.cost {
+				t.Errorf("unrealized = %.2f, want %.2f", v.UnrealizedPL, tt.market-tt.cost)
+			}
+			if len(v.ByType) != tt.types {
+				t.Fatalf("by type = %d, want %d", len(v.ByType), tt.types)
+			}
+			for i, bt := range v.ByType {
+				if i > 0 && v.ByType[i-1].GoldType > bt.GoldType {
+					t.Errorf("by type not sorted: %s before %s", v.ByType[i-1].GoldType, bt.GoldType)
+				}
+				if bt.PricePerGram != tt.pricePerGram[bt.GoldType] {
+					t.Errorf("%s price = %.2f, want %.2f", bt.GoldType, bt.PricePerGram, tt.pricePerGram[bt.GoldType])
+				}
+			}
+		})
+	}
+}
+
+func TestGoldLedgerValueAtUnpriced(t *testing.T) {
+	ledger, _, _ := testLedger()
+
+	v := ledger.ValueAt(date(2025, 1, 1), GoldQuotes{})
+	if v.UnpricedWeightGram != 2 || v.MarketValue != 0 || v.UnrealizedPL != 0 || v.UnrealizedPLPercent != 0 {
+		t.Errorf("ValueAt() = unpriced %.4fg, market %.2f, unrealized %.2f (%.2f%%), want 2g unpriced and no value",
+			v.UnpricedWeightGram, v.MarketValue, v.UnrealizedPL, v.UnrealizedPLPercent)
+	}
+	if v.WeightGram != 2 || v.CostBasis != 200 {
+		t.Errorf("ValueAt() = %.4fg at cost %.2f, want the unpriced lot counted", v.WeightGram, v.CostBasis)
+	}
+}
+
+func TestGoldLedgerSeries(t *testing.T) {
+	ledger, _, _ := testLedger()
+
+	points := ledger.Series(date(2025, 1, 1), date(2025, 1, 5), ValuationDaily)
+	want := []float64{220, 220, 240, 180, 275}
+	if len(points) != len(want) {
+		t.Fatalf("Series() = %d points, want %d", len(points), len(want))
+	}
+	for i, p := range points {
+		if p.MarketValue != want[i] {
+			t.Errorf("%s market = %.2f, want %.2f", p.Date.Format("2006-01-02"), p.MarketValue, want[i])
+		}
+	}
+}
+
+func TestSeriesDates(t *testing.T) {
+	tests := []struct {
+		name     string
+		from     time.Time
+		to       time.Time
+		interval ValuationInterval
+		want     []time.Time
+	}{
+		{"daily", date(2025, 1, 30), date(2025, 2, 1), ValuationDaily, []time.Time{date(2025, 1, 30), date(2025, 1, 31), date(2025, 2, 1)}},
+		{"daily single day", date(2025, 1, 30), date(2025, 1, 30), ValuationDaily, []time.Time{date(2025, 1, 30)}},
+		{"daily empty when reversed", date(2025, 1, 30), date(2025, 1, 29), ValuationDaily, []time.Time{}},
+		{"monthly ends on to", date(2025, 1, 15), date(2025, 3, 10), ValuationMonthly, []time.Time{date(2025, 1, 31), date(2025, 2, 28), date(2025, 3, 10)}},
+		{"monthly leap february", date(2024, 1, 1), date(2024, 3, 31), ValuationMonthly, []time.Time{date(2024, 1, 31), date(2024, 2, 29), date(2024, 3, 31)}},
+		{"monthly across the year", date(2024, 12, 5), date(2025, 1, 31), ValuationMonthly, []time.Time{date(2024, 12, 31), date(2025, 1, 31)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SeriesDates(tt.from, tt.to, tt.interval)
+			if len(got) != len(tt.want) {
+				t.Fatalf("SeriesDates() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if !got[i].Equal(tt.want[i]) {
+					t.Errorf("day %d = %s, want %s", i, got[i].Format("2006-01-02"), tt.want[i].Format("2006-01-02"))
+				}
+			}
+		})
+	}
+}
+
+func TestGoldLedgerValueAtPurity(t *testing.T) {
+	ring := goldLot("ring", 10, 600000)
+	ring.ItemKind, ring.Purity, ring.ResaleHaircutPercent = GoldItemJewelry, 0.75, 10
+	ring.PurchaseDate = date(2025, 1, 1)
+	ledger := &GoldLedger{
+		Assets: []GoldAsset{ring},
+		Prices: []GoldPrice{goldQuote(GoldTypeAntam, date(2025, 1, 1), 1000000)},
+	}
+
+	v := ledger.ValueAt(date(2025, 1, 1), ledger.QuotesAt(date(2025, 1, 1)))
+	if v.WeightGram != 10 || v.MarketValue != 6750000 || len(v.ByType) != 1 {
+		t.Fatalf("ValueAt() = %.4fg, market %.2f, want 10g at 6750000", v.WeightGram, v.MarketValue)
+	}
+	if bt := v.ByType[0]; bt.FineWeightGram != 7.5 || bt.PricePerGram != 1000000 || bt.MarketValue != 6750000 {
+		t.Errorf("by type = %.4fg fine at %.2f, market %.2f, want 7.5g at the 24k quote, 6750000", bt.FineWeightGram, bt.PricePerGram, bt.MarketValue)
+	}
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type NotificationType string
+
+const (
+	NotificationBudgetThreshold NotificationType = "budget_threshold"
+	NotificationGoldPriceAlert  NotificationType = "gold_price_alert"
+)
+
+// Notification - in-app message produced by alerts
+type Notification struct {
+	ID        uuid.UUID              `db:"id" json:"id"`
+	UserID    uuid.UUID              `db:"user_id" json:"user_id"`
+	Type      NotificationType       `db:"type" json:"type"`
+	Title     string                 `db:"title" json:"title"`
+	Message   string                 `db:"message" json:"message"`
+	Data      map[string]interface{} `db:"data" json:"data"`
+	DedupeKey *string                `db:"dedupe_key" json:"-"`
+	IsRead    bool                   `db:"is_read" json:"is_read"`
+	CreatedAt time.Time              `db:"created_at" json:"created_at"`
+}
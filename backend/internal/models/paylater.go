@@ -0,0 +1,108 @@
+package models
+
+import (
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PaylaterPurchase - a purchase on a paylater account split into monthly
+// installments. Each installment is principal / tenor plus a flat monthly
+// fee on the full principal; the admin fee is due with the first one.
+type PaylaterPurchase struct {
+	ID                uuid.UUID  `db:"id" json:"id"`
+	UserID            uuid.UUID  `db:"user_id" json:"user_id"`
+	AccountID         uuid.UUID  `db:"account_id" json:"account_id"`
+	TransactionID     *uuid.UUID `db:"transaction_id" json:"transaction_id,omitempty"`
+	Description       string     `db:"description" json:"description"`
+	Category          string     `db:"category" json:"category"`
+	Principal         float64    `db:"principal" json:"principal"`
+	TenorMonths       int        `db:"tenor_months" json:"tenor_months"`
+	FeePercentMonthly float64    `db:"fee_percent_monthly" json:"fee_percent_monthly"`
+	AdminFee          float64    `db:"admin_fee" json:"admin_fee"`
+	PurchaseDate      time.Time  `db:"purchase_date" json:"purchase_date"`
+	CreatedAt         time.Time  `db:"created_at" json:"created_at"`
+	// For response only
+	TotalFees    float64               `db:"-" json:"total_fees"`
+	Outstanding  float64               `db:"-" json:"outstanding"`
+	Installments []PaylaterInstallment `db:"-" json:"installments,omitempty"`
+}
+
+type PaylaterInstallment struct {
+	ID                   uuid.UUID  `db:"id" json:"id"`
+	PurchaseID           uuid.UUID  `db:"purchase_id" json:"purchase_id"`
+	InstallmentNumber    int        `db:"installment_number" json:"installment_number"`
+	DueDate              time.Time  `db:"due_date" json:"due_date"`
+	Principal            float64    `db:"principal" json:"principal"`
+	Fee                  float64    `db:"fee" json:"fee"`
+	Amount               float64    `db:"amount" json:"amount"`
+	PaidAt               *time.Time `db:"paid_at" json:"paid_at,omitempty"`
+	PaymentTransactionID *uuid.UUID `db:"payment_transaction_id" json:"payment_transaction_id,omitempty"`
+}
+
+// Schedule builds the purchase's installments. The first falls due on dueDay
+// of the month after the purchase (clamped for short months), the rest
+// monthly after that; the last installment takes the principal's rounding
+// remainder.
+func (p *PaylaterPurchase) Schedule(dueDay int) []PaylaterInstallment {
+	monthly := math.Round(p.Principal/float64(p.TenorMonths)*100) / 100
+	fee := math.Round(p.Principal*p.FeePercentMonthly) / 100
+
+	installments := make([]PaylaterInstallment, p.TenorMonths)
+	for i := range installments {
+		n := i + 1
+		principal := monthly
+		if n == p.TenorMonths {
+			principal = math.Round((p.Principal-monthly*float64(p.TenorMonths-1))*100) / 100
+		}
+		installmentFee := fee
+		if n == 1 {
+			installmentFee += p.AdminFee
+		}
+		installments[i] = PaylaterInstallment{
+			ID:                uuid.New(),
+			PurchaseID:        p.ID,
+			InstallmentNumber: n,
+			DueDate:           monthStart(int(p.PurchaseDate.Month())+n, p.PurchaseDate.Year(), dueDay),
+			Principal:         principal,
+			Fee:               installmentFee,
+			Amount:            principal + installmentFee,
+		}
+	}
+	return installments
+}
+
+// UpcomingDue - an unpaid paylater installment with its account and purchase
+type UpcomingDue struct {
+	PaylaterInstallment
+	UserID      uuid.UUID `db:"user_id" json:"-"`
+	AccountID   uuid.UUID `db:"account_id" json:"account_id"`
+	AccountName string    `db:"account_name" json:"account_name"`
+	Description string    `db:"description" json:"description"`
+	TenorMonths int       `db:"tenor_months" json:"tenor_months"`
+	Overdue     bool      `db:"-" json:"overdue"`
+}
+
+// LiabilityItem - one debt in the liabilities summary
+type LiabilityItem struct {
+	Kind        string     `json:"kind"`
+	ID          uuid.UUID  `json:"id"`
+	Name        string     `json:"name"`
+	Balance     float64    `json:"balance"`
+	CreditLimit *float64   `json:"credit_limit,omitempty"`
+	NextDueDate *time.Time `json:"next_due_date,omitempty"`
+	NextDue     float64    `json:"next_due"`
+}
+
+// BalanceSheet - a user's accounts split into assets and liabilities.
+// Liabilities are positive amounts owed: paylater balances below zero and
+// credit card balances plus unbilled installment principal.
+type BalanceSheet struct {
+	TotalAssets      float64         `json:"total_assets"`
+	TotalLiabilities float64         `json:"total_liabilities"`
+	NetWorth         float64         `json:"net_worth"`
+	PaylaterDebt     float64         `json:"paylater_debt"`
+	CreditCardDebt   float64         `json:"credit_card_debt"`
+	Liabilities      []LiabilityItem `json:"liabilities"`
+}
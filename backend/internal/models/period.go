@@ -0,0 +1,102 @@
+package models
+
+import "time"
+
+// FinancialMonthRange returns the [start, end) bounds of a budget month for a
+// user whose financial month starts on startDay. Month M runs from startDay of
+// calendar month M up to startDay of month M+1, so with startDay 25 the
+// January budget covers Jan 25 - Feb 24. A start day past the end of a short
+// month is clamped to that month's last day (startDay 31 starts February on
+// the 28th/29th and March on the 31st).
+func FinancialMonthRange(month, year, startDay int) (time.Time, time.Time) {
+	return monthStart(month, year, startDay), monthStart(month+1, year, startDay)
+}
+
+// FinancialMonthOf returns the budget month and year that contain t
+func FinancialMonthOf(t time.Time, startDay int) (int, int) {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	start := monthStart(int(t.Month()), t.Year(), startDay)
+	if day.Before(start) {
+		prev := time.Date(t.Year(), t.Month()-1, 1, 0, 0, 0, 0, time.UTC)
+		return int(prev.Month()), prev.Year()
+	}
+	return int(t.Month()), t.Year()
+}
+
+// FinancialYearRange returns the [start, end) bounds of a budget year: from
+// the start of its January budget month to the start of next January's
+func FinancialYearRange(year, startDay int) (time.Time, time.Time) {
+	return monthStart(1, year, startDay), monthStart(1, year+1, startDay)
+}
+
+// ISOWeekRange returns the [start, end) bounds (Monday to Monday) of an ISO
+// 8601 week. Weekly budgets ignore the financial month start day.
+func ISOWeekRange(year, week int) (time.Time, time.Time) {
+	// January 4th always falls in week 1
+	jan4 := time.Date(year, 1, 4, 0, 0, 0, 0, time.UTC)
+	sinceMonday := (int(jan4.Weekday()) + 6) % 7
+	start := jan4.AddDate(0, 0, (week-1)*7-sinceMonday)
+	return start, start.AddDate(0, 0, 7)
+}
+
+// ValidISOWeek reports whether week exists in the ISO week-numbering year
+func ValidISOWeek(year, week int) bool {
+	if week < 1 || week > 53 {
+		return false
+	}
+	start, _ := ISOWeekRange(year, week)
+	y, w := start.ISOWeek()
+	return y == year && w == week
+}
+
+// BudgetPeriodRange returns the [start, end) bounds of the period identified
+// by year, month and week
+func BudgetPeriodRange(period BudgetPeriod, year, month, week, startDay int) (time.Time, time.Time) {
+	switch period {
+	case BudgetPeriodYearly:
+		return FinancialYearRange(year, startDay)
+	case BudgetPeriodWeekly:
+		return ISOWeekRange(year, week)
+	default:
+		return FinancialMonthRange(month, year, startDay)
+	}
+}
+
+func monthStart(month, year, startDay int) time.Time {
+	if startDay < 1 {
+		startDay = 1
+	}
+	first := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+	lastDay := first.AddDate(0, 1, -1).Day()
+	if startDay > lastDay {
+		startDay = lastDay
+	}
+	return first.AddDate(0, 0, startDay-1)
+}
+
+// StatementDates returns the dates of the credit card statement that closes
+// in the given calendar month: the first day it covers, its closing date
+// (inclusive) and its payment due date. Billing and due days past the end of
+// a short month are clamped to its last day. The due date is the first due
+// day after the closing date, so a due day at or before the billing day
+// falls in the following month.
+func StatementDates(month, year, billingDay, dueDay int) (time.Time, time.Time, time.Time) {
+	closing := monthStart(month, year, billingDay)
+	start := monthStart(month-1, year, billingDay).AddDate(0, 0, 1)
+	due := monthStart(month, year, dueDay)
+	if !due.After(closing) {
+		due = monthStart(month+1, year, dueDay)
+	}
+	return start, closing, due
+}
+
+// StatementMonthOf returns the calendar month and year of the statement
+// whose period contains t
+func StatementMonthOf(t time.Time, billingDay int) (int, int) {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	if day.After(monthStart(int(t.Month()), t.Year(), billingDay)) {
+		next := time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		return int(next.Month()), next.Year()
+	}
+	return int(t.Month()), t.Year()
+}
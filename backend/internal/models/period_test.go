@@ -0,0 +1,122 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestFinancialMonthRange(t *testing.T) {
+	tests := []struct {
+		name      string
+		month     int
+		year      int
+		startDay  int
+		wantStart time.Time
+		wantEnd   time.Time
+	}{
+		{"calendar month", 1, 2025, 1, date(2025, 1, 1), date(2025, 2, 1)},
+		{"zero start day is the 1st", 3, 2025, 0, date(2025, 3, 1), date(2025, 4, 1)},
+		{"payday 25th", 1, 2025, 25, date(2025, 1, 25), date(2025, 2, 25)},
+		{"december crosses the year", 12, 2024, 25, date(2024, 12, 25), date(2025, 1, 25)},
+		{"day 31 clamps february", 1, 2025, 31, date(2025, 1, 31), date(2025, 2, 28)},
+		{"day 31 clamps leap february", 2, 2024, 31, date(2024, 2, 29), date(2024, 3, 31)},
+		{"day 31 clamps april", 4, 2025, 31, date(2025, 4, 30), date(2025, 5, 31)},
+		{"day 30 in february", 2, 2025, 30, date(2025, 2, 28), date(2025, 3, 30)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := FinancialMonthRange(tt.month, tt.year, tt.startDay)
+			if !start.Equal(tt.wantStart) || !end.Equal(tt.wantEnd) {
+				t.Errorf("FinancialMonthRange(%d, %d, %d) = %s - %s, want %s - %s", tt.month, tt.year, tt.startDay,
+					start.Format("2006-01-02"), end.Format("2006-01-02"), tt.wantStart.Format("2006-01-02"), tt.wantEnd.Format("2006-01-02"))
+			}
+		})
+	}
+}
+
+func TestFinancialMonthOf(t *testing.T) {
+	tests := []struct {
+		name      string
+		t         time.Time
+		startDay  int
+		wantMonth int
+		wantYear  int
+	}{
+		{"calendar month", date(2025, 3, 15), 1, 3, 2025},
+		{"on the start day", date(2025, 3, 25), 25, 3, 2025},
+		{"day before the start day", date(2025, 3, 24), 25, 2, 2025},
+		{"january before payday is december", date(2025, 1, 10), 25, 12, 2024},
+		{"time of day is ignored", time.Date(2025, 3, 25, 23, 59, 0, 0, time.UTC), 25, 3, 2025},
+		{"day 31 on clamped february start", date(2025, 2, 28), 31, 2, 2025},
+		{"day 31 before the start", date(2025, 2, 27), 31, 1, 2025},
+		{"day 31 in a 31-day month", date(2025, 3, 30), 31, 2, 2025},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			month, year := FinancialMonthOf(tt.t, tt.startDay)
+			if month != tt.wantMonth || year != tt.wantYear {
+				t.Errorf("FinancialMonthOf(%s, %d) = %d/%d, want %d/%d", tt.t.Format("2006-01-02"), tt.startDay, month, year, tt.wantMonth, tt.wantYear)
+			}
+		})
+	}
+}
+
+func TestStatementDates(t *testing.T) {
+	tests := []struct {
+		name        string
+		month       int
+		year        int
+		billingDay  int
+		dueDay      int
+		wantStart   time.Time
+		wantClosing time.Time
+		wantDue     time.Time
+	}{
+		{"due after closing", 3, 2025, 15, 25, date(2025, 2, 16), date(2025, 3, 15), date(2025, 3, 25)},
+		{"due before closing falls next month", 3, 2025, 15, 5, date(2025, 2, 16), date(2025, 3, 15), date(2025, 4, 5)},
+		{"due on closing day falls next month", 3, 2025, 15, 15, date(2025, 2, 16), date(2025, 3, 15), date(2025, 4, 15)},
+		{"january starts in december", 1, 2025, 20, 5, date(2024, 12, 21), date(2025, 1, 20), date(2025, 2, 5)},
+		{"december due in january", 12, 2025, 20, 10, date(2025, 11, 21), date(2025, 12, 20), date(2026, 1, 10)},
+		{"billing 31 after clamped february", 3, 2025, 31, 10, date(2025, 3, 1), date(2025, 3, 31), date(2025, 4, 10)},
+		{"billing 30 clamps leap february", 2, 2024, 30, 15, date(2024, 1, 31), date(2024, 2, 29), date(2024, 3, 15)},
+		{"due 31 clamps february", 1, 2025, 31, 31, date(2025, 1, 1), date(2025, 1, 31), date(2025, 2, 28)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, closing, due := StatementDates(tt.month, tt.year, tt.billingDay, tt.dueDay)
+			if !start.Equal(tt.wantStart) || !closing.Equal(tt.wantClosing) || !due.Equal(tt.wantDue) {
+				t.Errorf("StatementDates(%d, %d, %d, %d) = %s, %s, %s, want %s, %s, %s", tt.month, tt.year, tt.billingDay, tt.dueDay,
+					start.Format("2006-01-02"), closing.Format("2006-01-02"), due.Format("2006-01-02"),
+					tt.wantStart.Format("2006-01-02"), tt.wantClosing.Format("2006-01-02"), tt.wantDue.Format("2006-01-02"))
+			}
+		})
+	}
+}
+
+func TestStatementMonthOf(t *testing.T) {
+	tests := []struct {
+		name       string
+		t          time.Time
+		billingDay int
+		wantMonth  int
+		wantYear   int
+	}{
+		{"on the closing day", date(2025, 3, 15), 15, 3, 2025},
+		{"day after closing", date(2025, 3, 16), 15, 4, 2025},
+		{"december after closing", date(2025, 12, 20), 15, 1, 2026},
+		{"billing 31 on clamped february", date(2025, 2, 28), 31, 2, 2025},
+		{"billing 31 on march 1st", date(2025, 3, 1), 31, 3, 2025},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			month, year := StatementMonthOf(tt.t, tt.billingDay)
+			if month != tt.wantMonth || year != tt.wantYear {
+				t.Errorf("StatementMonthOf(%s, %d) = %d/%d, want %d/%d", tt.t.Format("2006-01-02"), tt.billingDay, month, year, tt.wantMonth, tt.wantYear)
+			}
+		})
+	}
+}
@@ -1,9 +1,15 @@
 package models
 
+import "time"
+
+// TransactionSummary - totals over all time, or over one financial month
+// (PeriodStart/PeriodEnd are inclusive days) when a month is requested
 type TransactionSummary struct {
-	TotalIncome  float64 `json:"total_income"`
-	TotalExpense float64 `json:"total_expense"`
-	Balance      float64 `json:"balance"`
+	TotalIncome  float64    `json:"total_income"`
+	TotalExpense float64    `json:"total_expense"`
+	Balance      float64    `json:"balance"`
+	PeriodStart  *time.Time `json:"period_start,omitempty"`
+	PeriodEnd    *time.Time `json:"period_end,omitempty"`
 }
 
 type DashboardStats struct {
@@ -6,14 +6,25 @@ import (
 	"github.com/google/uuid"
 )
 
+// BudgetingMode - how a user plans their money
+type BudgetingMode string
+
+const (
+	BudgetingStandard BudgetingMode = "standard"
+	// Every rupiah of income is assigned to a budget (envelope)
+	BudgetingZeroBased BudgetingMode = "zero_based"
+)
+
 type User struct {
-	ID           uuid.UUID `db:"id" json:"id"`
-	Email        string    `db:"email" json:"email"`
-	PasswordHash string    `db:"password_hash" json:"-"`
-	FullName     string    `db:"full_name" json:"full_name"`
-	IsAdmin      bool      `db:"is_admin" json:"is_admin"`
-	CreatedAt    time.Time `db:"created_at" json:"created_at"`
-	UpdatedAt    time.Time `db:"updated_at" json:"updated_at"`
+	ID            uuid.UUID     `db:"id" json:"id"`
+	Email         string        `db:"email" json:"email"`
+	PasswordHash  string        `db:"password_hash" json:"-"`
+	FullName      string        `db:"full_name" json:"full_name"`
+	IsAdmin       bool          `db:"is_admin" json:"is_admin"`
+	MonthStartDay int           `db:"month_start_day" json:"month_start_day"`
+	BudgetingMode BudgetingMode `db:"budgeting_mode" json:"budgeting_mode"`
+	CreatedAt     time.Time     `db:"created_at" json:"created_at"`
+	UpdatedAt     time.Time     `db:"updated_at" json:"updated_at"`
 }
 
 type RegisterRequest struct {
@@ -32,6 +43,12 @@ type LoginResponse struct {
 	User  User   `json:"user"`
 }
 
+// UpdateSettingsRequest - per-user preferences; omitted fields are unchanged
+type UpdateSettingsRequest struct {
+	MonthStartDay int           `json:"month_start_day" binding:"omitempty,min=1,max=31"`
+	BudgetingMode BudgetingMode `json:"budgeting_mode" binding:"omitempty,oneof=standard zero_based"`
+}
+
 // Admin models
 type UpdateUserRequest struct {
 	FullName string `json:"full_name"`
@@ -0,0 +1,177 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ZakatType string
+
+const (
+	ZakatMal         ZakatType = "mal"
+	ZakatPenghasilan ZakatType = "penghasilan"
+)
+
+const (
+	// ZakatRate is the share of zakatable wealth or income due
+	ZakatRate = 0.025
+	// ZakatNisabGoldGram is the nisab expressed in grams of gold
+	ZakatNisabGoldGram = 85.0
+	// HaulDays is one lunar year
+	HaulDays = 354
+)
+
+// ZakatPayment - zakat paid for a year (mal) or a financial month
+// (penghasilan). With an account the payment is booked as an expense.
+type ZakatPayment struct {
+	ID            uuid.UUID  `db:"id" json:"id"`
+	UserID        uuid.UUID  `db:"user_id" json:"user_id"`
+	ZakatType     ZakatType  `db:"zakat_type" json:"zakat_type"`
+	PeriodYear    int        `db:"period_year" json:"period_year"`
+	PeriodMonth   *int       `db:"period_month" json:"period_month,omitempty"`
+	Amount        float64    `db:"amount" json:"amount"`
+	AccountID     *uuid.UUID `db:"account_id" json:"account_id,omitempty"`
+	TransactionID *uuid.UUID `db:"transaction_id" json:"transaction_id,omitempty"`
+	PaidAt        time.Time  `db:"paid_at" json:"paid_at"`
+	Notes         string     `db:"notes" json:"notes"`
+	CreatedAt     time.Time  `db:"created_at" json:"created_at"`
+}
+
+// ZakatNisab - 85g of gold at the quote of GoldType on or before the day of
+// the calculation. Value is per year; zakat penghasilan uses a twelfth.
+type ZakatNisab struct {
+	GoldGram     float64   `json:"gold_gram"`
+	GoldType     GoldType  `json:"gold_type"`
+	PricePerGram float64   `json:"price_per_gram"`
+	PriceDate    time.Time `json:"price_date"`
+	Value        float64   `json:"value"`
+}
+
+// NewZakatNisab prices the nisab with the buy price of quote
+func NewZakatNisab(quote GoldPrice) ZakatNisab {
+	return ZakatNisab{
+		GoldGram:     ZakatNisabGoldGram,
+		GoldType:     quote.GoldType,
+		PricePerGram: quote.BuyPricePerGram,
+		PriceDate:    quote.PriceDate,
+		Value:        roundMoney(ZakatNisabGoldGram * quote.BuyPricePerGram),
+	}
+}
+
+// ZakatWealthDay - cash and gold held at the end of a day
+type ZakatWealthDay struct {
+	Date      time.Time `json:"date"`
+	Cash      float64   `json:"cash"`
+	GoldValue float64   `json:"gold_value"`
+}
+
+func (d ZakatWealthDay) Total() float64 {
+	return d.Cash + d.GoldValue
+}
+
+// ZakatHaul - since when wealth has stayed at or above the nisab. Start is
+// empty when it is below the nisab on the day itself; when it never dropped
+// below within the history checked, Start is the first day checked.
+type ZakatHaul struct {
+	Met          bool       `json:"met"`
+	Start        *time.Time `json:"start,omitempty"`
+	CompleteDate *time.Time `json:"complete_date,omitempty"`
+	DaysHeld     int        `json:"days_held"`
+}
+
+// HaulOf checks the haul over days, sorted by date and ending on the day of
+// the calculation
+func HaulOf(days []ZakatWealthDay, nisab float64) ZakatHaul {
+	haul := ZakatHaul{}
+	if len(days) == 0 || days[len(days)-1].Total() < nisab {
+		return haul
+	}
+
+	start := days[0].Date
+	for i := len(days) - 1; i >= 0; i-- {
+		if days[i].Total() < nisab {
+			start = days[i].Date.AddDate(0, 0, 1)
+			break
+		}
+	}
+
+	complete := start.AddDate(0, 0, HaulDays)
+	haul.Start = &start
+	haul.CompleteDate = &complete
+	haul.DaysHeld = int(days[len(days)-1].Date.Sub(start).Hours() / 24)
+	haul.Met = haul.DaysHeld >= HaulDays
+	return haul
+}
+
+// ZakatMalReport - zakat on wealth on a day: cash in asset accounts and gold
+// at market value, plus receivables, minus short-term debts. It is due when
+// that reaches the nisab and the haul is complete.
+type ZakatMalReport struct {
+	Date            time.Time  `json:"date"`
+	Year            int        `json:"year"`
+	Nisab           ZakatNisab `json:"nisab"`
+	Cash            float64    `json:"cash"`
+	GoldWeightGram  float64    `json:"gold_weight_gram"`
+	GoldValue       float64    `json:"gold_value"`
+	Receivables     float64    `json:"receivables"`
+	Debts           float64    `json:"debts"`
+	ZakatableWealth float64    `json:"zakatable_wealth"`
+	MeetsNisab      bool       `json:"meets_nisab"`
+	Haul            ZakatHaul  `json:"haul"`
+	IsDue           bool       `json:"is_due"`
+	Rate            float64    `json:"rate"`
+	Obligation      float64    `json:"obligation"`
+	Paid            float64    `json:"paid"`
+	Remaining       float64    `json:"remaining"`
+}
+
+// Calculate fills the wealth totals, due status and obligation from the
+// filled in amounts and haul
+func (r *ZakatMalReport) Calculate() {
+	r.Rate = ZakatRate
+	r.ZakatableWealth = roundMoney(r.Cash + r.GoldValue + r.Receivables - r.Debts)
+	r.MeetsNisab = r.ZakatableWealth >= r.Nisab.Value
+	r.IsDue = r.MeetsNisab && r.Haul.Met
+	r.Obligation = 0
+	if r.IsDue {
+		r.Obligation = roundMoney(r.ZakatableWealth * ZakatRate)
+	}
+	r.Remaining = remainingZakat(r.Obligation, r.Paid)
+}
+
+// ZakatPenghasilanReport - zakat on the income of a financial month. It is
+// due when income reaches a twelfth of the yearly nisab.
+type ZakatPenghasilanReport struct {
+	Month        int        `json:"month"`
+	Year         int        `json:"year"`
+	PeriodStart  time.Time  `json:"period_start"`
+	PeriodEnd    time.Time  `json:"period_end"`
+	Income       float64    `json:"income"`
+	Nisab        ZakatNisab `json:"nisab"`
+	MonthlyNisab float64    `json:"monthly_nisab"`
+	IsDue        bool       `json:"is_due"`
+	Rate         float64    `json:"rate"`
+	Obligation   float64    `json:"obligation"`
+	Paid         float64    `json:"paid"`
+	Remaining    float64    `json:"remaining"`
+}
+
+// Calculate fills the due status and obligation from Income and Nisab
+func (r *ZakatPenghasilanReport) Calculate() {
+	r.Rate = ZakatRate
+	r.MonthlyNisab = roundMoney(r.Nisab.Value / 12)
+	r.IsDue = r.Income >= r.MonthlyNisab
+	r.Obligation = 0
+	if r.IsDue {
+		r.Obligation = roundMoney(r.Income * ZakatRate)
+	}
+	r.Remaining = remainingZakat(r.Obligation, r.Paid)
+}
+
+func remainingZakat(obligation, paid float64) float64 {
+	if paid >= obligation {
+		return 0
+	}
+	return roundMoney(obligation - paid)
+}
@@ -0,0 +1,141 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+// wealthDays returns n days from from, each holding total in cash
+func wealthDays(from time.Time, n int, total float64) []ZakatWealthDay {
+	days := make([]ZakatWealthDay, n)
+	for i := range days {
+		days[i] = ZakatWealthDay{Date: from.AddDate(0, 0, i), Cash: total}
+	}
+	return days
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+func TestHaulOf(t *testing.T) {
+	from := date(2024, 1, 1)
+	lunarYear := wealthDays(from, HaulDays+1, 200)
+	dayShort := wealthDays(from, HaulDays, 200)
+	dropped := wealthDays(from, HaulDays+1, 200)
+	dropped[10].Cash = 99.99
+	belowToday := wealthDays(from, HaulDays+1, 200)
+	belowToday[HaulDays].Cash = 50
+	atNisab := wealthDays(from, HaulDays+1, 100)
+	cashAndGold := wealthDays(from, HaulDays+1, 60)
+	for i := range cashAndGold {
+		cashAndGold[i].GoldValue = 40
+	}
+
+	tests := []struct {
+		name      string
+		days      []ZakatWealthDay
+		wantMet   bool
+		wantStart *time.Time
+		wantHeld  int
+	}{
+		{"no history", nil, false, nil, 0},
+		{"held a full lunar year", lunarYear, true, &from, HaulDays},
+		{"one day short of a lunar year", dayShort, false, &from, HaulDays - 1},
+		{"dropped below restarts the haul", dropped, false, timePtr(date(2024, 1, 12)), HaulDays - 11},
+		{"below on the day itself", belowToday, false, nil, 0},
+		{"exactly the nisab counts", atNisab, true, &from, HaulDays},
+		{"cash and gold together", cashAndGold, true, &from, HaulDays},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			haul := HaulOf(tt.days, 100)
+			if haul.Met != tt.wantMet || haul.DaysHeld != tt.wantHeld {
+				t.Errorf("HaulOf() = met %v, %d days, want %v, %d days", haul.Met, haul.DaysHeld, tt.wantMet, tt.wantHeld)
+			}
+			if tt.wantStart == nil {
+				if haul.Start != nil || haul.CompleteDate != nil {
+					t.Errorf("start = %v, complete = %v, want none", haul.Start, haul.CompleteDate)
+				}
+				return
+			}
+			if haul.Start == nil || !haul.Start.Equal(*tt.wantStart) {
+				t.Fatalf("start = %v, want %s", haul.Start, tt.wantStart.Format("2006-01-02"))
+			}
+			if want := tt.wantStart.AddDate(0, 0, HaulDays); !haul.CompleteDate.Equal(want) {
+				t.Errorf("complete = %s, want %s", haul.CompleteDate.Format("2006-01-02"), want.Format("2006-01-02"))
+			}
+		})
+	}
+}
+
+func TestNewZakatNisab(t *testing.T) {
+	nisab := NewZakatNisab(GoldPrice{GoldType: GoldTypeAntam, PriceDate: date(2025, 3, 7), BuyPricePerGram: 1234567.89, BuybackPricePerGram: 1100000})
+	if nisab.Value != 104938270.65 || nisab.PricePerGram != 1234567.89 || nisab.GoldGram != ZakatNisabGoldGram {
+		t.Errorf("NewZakatNisab() = %+v, want 85g at the buy price, 104938270.65", nisab)
+	}
+}
+
+func TestZakatMalReportCalculate(t *testing.T) {
+	met := ZakatHaul{Met: true}
+
+	tests := []struct {
+		name           string
+		report         ZakatMalReport
+		wantWealth     float64
+		wantDue        bool
+		wantObligation float64
+		wantRemaining  float64
+	}{
+		{"a cent below the nisab", ZakatMalReport{Cash: 80000000, GoldValue: 4999999.99, Haul: met}, 84999999.99, false, 0, 0},
+		{"at the nisab", ZakatMalReport{Cash: 80000000, GoldValue: 5000000, Haul: met}, 85000000, true, 2125000, 2125000},
+		{"haul not complete", ZakatMalReport{Cash: 90000000, Haul: ZakatHaul{}}, 90000000, false, 0, 0},
+		{"debts pull below the nisab", ZakatMalReport{Cash: 90000000, Debts: 5000000.01, Haul: met}, 84999999.99, false, 0, 0},
+		{"receivables count", ZakatMalReport{Cash: 80000000, Receivables: 5000000, Haul: met}, 85000000, true, 2125000, 2125000},
+		{"obligation rounds to the cent", ZakatMalReport{Cash: 100000000.33, Haul: met}, 100000000.33, true, 2500000.01, 2500000.01},
+		{"partly paid", ZakatMalReport{Cash: 85000000, Paid: 1000000, Haul: met}, 85000000, true, 2125000, 1125000},
+		{"paid more than due", ZakatMalReport{Cash: 85000000, Paid: 3000000, Haul: met}, 85000000, true, 2125000, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := tt.report
+			r.Nisab = ZakatNisab{Value: 85000000}
+			r.Calculate()
+			if r.ZakatableWealth != tt.wantWealth || r.IsDue != tt.wantDue || r.Obligation != tt.wantObligation || r.Remaining != tt.wantRemaining {
+				t.Errorf("Calculate() = wealth %.2f, due %v, obligation %.2f, remaining %.2f, want %.2f, %v, %.2f, %.2f",
+					r.ZakatableWealth, r.IsDue, r.Obligation, r.Remaining, tt.wantWealth, tt.wantDue, tt.wantObligation, tt.wantRemaining)
+			}
+		})
+	}
+}
+
+func TestZakatPenghasilanReportCalculate(t *testing.T) {
+	tests := []struct {
+		name           string
+		income         float64
+		paid           float64
+		wantDue        bool
+		wantObligation float64
+		wantRemaining  float64
+	}{
+		{"no income", 0, 0, false, 0, 0},
+		{"a cent below the monthly nisab", 7083333.32, 0, false, 0, 0},
+		{"at the monthly nisab", 7083333.33, 0, true, 177083.33, 177083.33},
+		{"partly paid", 10000000, 100000, true, 250000, 150000},
+		{"paid more than due", 10000000, 300000, true, 250000, 0},
+		{"paid without an obligation", 1000000, 50000, false, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := ZakatPenghasilanReport{Income: tt.income, Paid: tt.paid, Nisab: ZakatNisab{Value: 85000000}}
+			r.Calculate()
+			if r.MonthlyNisab != 7083333.33 {
+				t.Errorf("monthly nisab = %.2f, want 7083333.33", r.MonthlyNisab)
+			}
+			if r.IsDue != tt.wantDue || r.Obligation != tt.wantObligation || r.Remaining != tt.wantRemaining {
+				t.Errorf("Calculate() = due %v, obligation %.2f, remaining %.2f, want %v, %.2f, %.2f",
+					r.IsDue, r.Obligation, r.Remaining, tt.wantDue, tt.wantObligation, tt.wantRemaining)
+			}
+		})
+	}
+}
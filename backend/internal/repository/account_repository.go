@@ -24,10 +24,10 @@ func (r *AccountRepository) Create(account *models.Account) error {
 	account.Balance = 0 // Always start with 0 balance
 
 	query := `
-		INSERT INTO accounts (id, user_id, name, type, balance, currency, parent_account_id, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO accounts (id, user_id, name, type, balance, currency, parent_account_id, credit_limit, due_day, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 	`
-	_, err := r.db.Exec(query, account.ID, account.UserID, account.Name, account.Type, account.Balance, account.Currency, account.ParentAccountID, account.CreatedAt, account.UpdatedAt)
+	_, err := r.db.Exec(query, account.ID, account.UserID, account.Name, account.Type, account.Balance, account.Currency, account.ParentAccountID, account.CreditLimit, account.DueDay, account.CreatedAt, account.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to create account: %w", err)
 	}
@@ -38,7 +38,7 @@ func (r *AccountRepository) Create(account *models.Account) error {
 // GetByUserID returns all main accounts (no parent) with their sub-accounts
 func (r *AccountRepository) GetByUserID(userID uuid.UUID) ([]models.Account, error) {
 	var allAccounts []models.Account
-	query := `SELECT id, user_id, name, type, balance, currency, parent_account_id, created_at, updated_at FROM accounts WHERE user_id = $1 ORDER BY created_at DESC`
+	query := `SELECT id, user_id, name, type, balance, currency, parent_account_id, credit_limit, due_day, created_at, updated_at FROM accounts WHERE user_id = $1 ORDER BY created_at DESC`
 	err := r.db.Select(&allAccounts, query, userID)
 	if err != nil {
 		return nil, err
@@ -49,6 +49,7 @@ func (r *AccountRepository) GetByUserID(userID uuid.UUID) ([]models.Account, err
 	subAccountsMap := make(map[uuid.UUID][]models.Account)
 
 	for _, acc := range allAccounts {
+		acc.SetAvailableCredit()
 		if acc.ParentAccountID == nil {
 			mainAccounts = append(mainAccounts, acc)
 		} else {
@@ -69,7 +70,7 @@ func (r *AccountRepository) GetByUserID(userID uuid.UUID) ([]models.Account, err
 // GetSubAccounts returns all sub-accounts for a parent account
 func (r *AccountRepository) GetSubAccounts(parentID uuid.UUID) ([]models.Account, error) {
 	var accounts []models.Account
-	query := `SELECT id, user_id, name, type, balance, currency, parent_account_id, created_at, updated_at FROM accounts WHERE parent_account_id = $1 ORDER BY created_at DESC`
+	query := `SELECT id, user_id, name, type, balance, currency, parent_account_id, credit_limit, due_day, created_at, updated_at FROM accounts WHERE parent_account_id = $1 ORDER BY created_at DESC`
 	err := r.db.Select(&accounts, query, parentID)
 	if err != nil {
 		return nil, err
@@ -79,11 +80,12 @@ func (r *AccountRepository) GetSubAccounts(parentID uuid.UUID) ([]models.Account
 
 func (r *AccountRepository) GetByID(id uuid.UUID) (*models.Account, error) {
 	var account models.Account
-	query := `SELECT id, user_id, name, type, balance, currency, parent_account_id, created_at, updated_at FROM accounts WHERE id = $1`
+	query := `SELECT id, user_id, name, type, balance, currency, parent_account_id, credit_limit, due_day, created_at, updated_at FROM accounts WHERE id = $1`
 	err := r.db.Get(&account, query, id)
 	if err != nil {
 		return nil, err
 	}
+	account.SetAvailableCredit()
 
 	// Load sub-accounts if this is a main account
 	if account.ParentAccountID == nil {
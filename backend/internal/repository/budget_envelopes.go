@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/financial-tracker/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// BudgetingMode returns whether the user plans in standard or zero-based mode
+func (r *BudgetRepository) BudgetingMode(userID uuid.UUID) (models.BudgetingMode, error) {
+	return getBudgetingMode(r.db, userID)
+}
+
+// GetIncome returns the total income recorded within [start, end)
+func (r *BudgetRepository) GetIncome(userID uuid.UUID, start, end time.Time) (float64, error) {
+	var income float64
+	err := r.db.Get(&income, `
+		SELECT COALESCE(SUM(amount), 0)
+		FROM transactions
+		WHERE user_id = $1 AND type = 'income' AND transaction_date >= $2 AND transaction_date < $3
+	`, userID, start, end)
+	return income, err
+}
+
+// GetZeroBased reports how much of a financial month's income has been
+// assigned to that month's budgets and which envelopes are overspent.
+// Rolled-over money was assigned in earlier months, so it counts towards an
+// envelope's availability but not towards this month's assignments.
+func (r *BudgetRepository) GetZeroBased(userID uuid.UUID, month, year int, now time.Time) (*models.ZeroBasedReport, error) {
+	progress, err := r.GetProgress(userID, models.BudgetPeriodMonthly, year, month, 0, now)
+	if err != nil {
+		return nil, err
+	}
+
+	startDay, err := getMonthStartDay(r.db, userID)
+	if err != nil {
+		return nil, err
+	}
+	start, end := models.FinancialMonthRange(month, year, startDay)
+
+	income, err := r.GetIncome(userID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	budgets, err := r.GetByMonthYear(userID, month, year)
+	if err != nil {
+		return nil, err
+	}
+	envelopeOf := make(map[uuid.UUID]*uuid.UUID)
+	accountIDs := pq.StringArray{}
+	for _, b := range budgets {
+		envelopeOf[b.ID] = b.EnvelopeAccountID
+		if b.EnvelopeAccountID != nil {
+			accountIDs = append(accountIDs, b.EnvelopeAccountID.String())
+		}
+	}
+
+	balances := make(map[uuid.UUID]float64)
+	if len(accountIDs) > 0 {
+		var rows []struct {
+			ID      uuid.UUID `db:"id"`
+			Balance float64   `db:"balance"`
+		}
+		if err := r.db.Select(&rows, `SELECT id, balance FROM accounts WHERE id = ANY($1::uuid[])`, accountIDs); err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			balances[row.ID] = row.Balance
+		}
+	}
+
+	report := &models.ZeroBasedReport{
+		Month:              month,
+		Year:               year,
+		PeriodStart:        progress.PeriodStart,
+		PeriodEnd:          progress.PeriodEnd,
+		Income:             income,
+		TotalSpent:         progress.TotalSpent,
+		Envelopes:          []models.Envelope{},
+		OverspentEnvelopes: []models.Envelope{},
+		Unbudgeted:         progress.Unbudgeted,
+	}
+
+	for _, p := range progress.Budgets {
+		envelope := models.Envelope{
+			BudgetID:          p.BudgetID,
+			Category:          p.Category,
+			Assigned:          p.Amount,
+			Rollover:          p.Rollover,
+			Available:         p.Available,
+			Spent:             p.Spent,
+			Remaining:         p.Remaining,
+			Overspent:         p.Remaining < 0,
+			EnvelopeAccountID: envelopeOf[p.BudgetID],
+		}
+		if envelope.EnvelopeAccountID != nil {
+			if balance, ok := balances[*envelope.EnvelopeAccountID]; ok {
+				envelope.EnvelopeBalance = &balance
+			}
+		}
+
+		report.TotalAssigned += envelope.Assigned
+		report.Envelopes = append(report.Envelopes, envelope)
+		if envelope.Overspent {
+			report.TotalOverspent -= envelope.Remaining
+			report.OverspentEnvelopes = append(report.OverspentEnvelopes, envelope)
+		}
+	}
+	report.ToBeAssigned = income - report.TotalAssigned
+
+	return report, nil
+}
+
+// Assign adds amount (negative to take money back) to the category's budget
+// of one month, creating the budget when needed. A given envelopeAccountID
+// replaces the budget's envelope. With moveFunds, the same amount also moves
+// from the envelope pocket's parent account into the pocket. Everything runs
+// in one database transaction.
+func (r *BudgetRepository) Assign(userID uuid.UUID, month, year int, category string, amount float64, envelopeAccountID *uuid.UUID, moveFunds bool) (*models.Budget, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	var budget models.Budget
+	err = tx.Get(&budget, `
+		INSERT INTO budgets (id, user_id, category, amount, period, budget_month, budget_year, budget_week, rollover_mode, alert_thresholds, envelope_account_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, 'monthly', $5, $6, 0, 'none', $7, $8, $9, $9)
+		ON CONFLICT (user_id, category, period, budget_year, budget_month, budget_week)
+		DO UPDATE SET amount = budgets.amount + EXCLUDED.amount,
+			envelope_account_id = COALESCE(EXCLUDED.envelope_account_id, budgets.envelope_account_id),
+			updated_at = EXCLUDED.updated_at
+		RETURNING `+budgetColumns,
+		uuid.New(), userID, category, amount, month, year, defaultAlertThresholds(), envelopeAccountID, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to assign budget: %w", err)
+	}
+	if budget.Amount < 0 {
+		return nil, ErrNegativeBudget
+	}
+
+	if moveFunds && budget.EnvelopeAccountID != nil && amount != 0 {
+		var parentID *uuid.UUID
+		if err := tx.Get(&parentID, `SELECT parent_account_id FROM accounts WHERE id = $1`, *budget.EnvelopeAccountID); err != nil {
+			return nil, err
+		}
+		if parentID != nil {
+			if _, err := tx.Exec(`UPDATE accounts SET balance = balance - $1, updated_at = $2 WHERE id = $3`, amount, now, *parentID); err != nil {
+				return nil, fmt.Errorf("failed to move funds: %w", err)
+			}
+			if _, err := tx.Exec(`UPDATE accounts SET balance = balance + $1, updated_at = $2 WHERE id = $3`, amount, now, *budget.EnvelopeAccountID); err != nil {
+				return nil, fmt.Errorf("failed to move funds: %w", err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &budget, nil
+}
@@ -2,11 +2,15 @@ package repository
 
 import (
 	"fmt"
+	"log"
+	"math"
+	"sort"
 	"time"
 
 	"github.com/financial-tracker/backend/internal/models"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 type BudgetRepository struct {
@@ -17,16 +21,27 @@ func NewBudgetRepository(db *sqlx.DB) *BudgetRepository {
 	return &BudgetRepository{db: db}
 }
 
+const budgetColumns = `id, user_id, category, amount, period, budget_month, budget_year, budget_week, rollover_mode, rollover_cap, alert_thresholds, envelope_account_id, created_at, updated_at`
+
 func (r *BudgetRepository) Create(budget *models.Budget) error {
 	budget.ID = uuid.New()
 	budget.CreatedAt = time.Now()
 	budget.UpdatedAt = time.Now()
+	if budget.Period == "" {
+		budget.Period = models.BudgetPeriodMonthly
+	}
+	if budget.RolloverMode == "" {
+		budget.RolloverMode = models.RolloverNone
+	}
+	if budget.AlertThresholds == nil {
+		budget.AlertThresholds = defaultAlertThresholds()
+	}
 
 	query := `
-		INSERT INTO budgets (id, user_id, category, amount, budget_month, budget_year, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO budgets (id, user_id, category, amount, period, budget_month, budget_year, budget_week, rollover_mode, rollover_cap, alert_thresholds, envelope_account_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 	`
-	_, err := r.db.Exec(query, budget.ID, budget.UserID, budget.Category, budget.Amount, budget.BudgetMonth, budget.BudgetYear, budget.CreatedAt, budget.UpdatedAt)
+	_, err := r.db.Exec(query, budget.ID, budget.UserID, budget.Category, budget.Amount, budget.Period, budget.BudgetMonth, budget.BudgetYear, budget.BudgetWeek, budget.RolloverMode, budget.RolloverCap, budget.AlertThresholds, budget.EnvelopeAccountID, budget.CreatedAt, budget.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to create budget: %w", err)
 	}
@@ -36,7 +51,7 @@ func (r *BudgetRepository) Create(budget *models.Budget) error {
 
 func (r *BudgetRepository) GetByUserID(userID uuid.UUID) ([]models.Budget, error) {
 	var budgets []models.Budget
-	query := `SELECT id, user_id, category, amount, budget_month, budget_year, created_at, updated_at FROM budgets WHERE user_id = $1 ORDER BY budget_year DESC, budget_month DESC, category ASC`
+	query := `SELECT ` + budgetColumns + ` FROM budgets WHERE user_id = $1 ORDER BY budget_year DESC, budget_month DESC, budget_week DESC, category ASC`
 	err := r.db.Select(&budgets, query, userID)
 	if err != nil {
 		return nil, err
@@ -44,13 +59,21 @@ func (r *BudgetRepository) GetByUserID(userID uuid.UUID) ([]models.Budget, error
 	return budgets, nil
 }
 
-// GetByMonthYear returns budgets for specific month/year
+// GetByMonthYear returns monthly budgets for specific month/year
 func (r *BudgetRepository) GetByMonthYear(userID uuid.UUID, month, year int) ([]models.Budget, error) {
+	return r.GetByPeriod(userID, models.BudgetPeriodMonthly, year, month, 0)
+}
+
+// GetByPeriod returns budgets of one period; month and week are ignored
+// where the period kind does not use them
+func (r *BudgetRepository) GetByPeriod(userID uuid.UUID, period models.BudgetPeriod, year, month, week int) ([]models.Budget, error) {
+	month, week = periodFields(period, month, week)
+
 	var budgets []models.Budget
-	query := `SELECT id, user_id, category, amount, budget_month, budget_year, created_at, updated_at 
-		FROM budgets WHERE user_id = $1 AND budget_month = $2 AND budget_year = $3 
+	query := `SELECT ` + budgetColumns + `
+		FROM budgets WHERE user_id = $1 AND period = $2 AND budget_year = $3 AND budget_month = $4 AND budget_week = $5
 		ORDER BY category ASC`
-	err := r.db.Select(&budgets, query, userID, month, year)
+	err := r.db.Select(&budgets, query, userID, period, year, month, week)
 	if err != nil {
 		return nil, err
 	}
@@ -59,7 +82,7 @@ func (r *BudgetRepository) GetByMonthYear(userID uuid.UUID, month, year int) ([]
 
 func (r *BudgetRepository) GetByID(id uuid.UUID) (*models.Budget, error) {
 	var budget models.Budget
-	query := `SELECT id, user_id, category, amount, budget_month, budget_year, created_at, updated_at FROM budgets WHERE id = $1`
+	query := `SELECT ` + budgetColumns + ` FROM budgets WHERE id = $1`
 	err := r.db.Get(&budget, query, id)
 	if err != nil {
 		return nil, err
@@ -69,40 +92,463 @@ func (r *BudgetRepository) GetByID(id uuid.UUID) (*models.Budget, error) {
 
 func (r *BudgetRepository) Update(budget *models.Budget) error {
 	budget.UpdatedAt = time.Now()
-	query := `UPDATE budgets SET category = $1, amount = $2, budget_month = $3, budget_year = $4, updated_at = $5 WHERE id = $6`
-	_, err := r.db.Exec(query, budget.Category, budget.Amount, budget.BudgetMonth, budget.BudgetYear, budget.UpdatedAt, budget.ID)
+	query := `UPDATE budgets SET category = $1, amount = $2, period = $3, budget_month = $4, budget_year = $5, budget_week = $6, rollover_mode = $7, rollover_cap = $8, alert_thresholds = $9, envelope_account_id = $10, updated_at = $11 WHERE id = $12`
+	_, err := r.db.Exec(query, budget.Category, budget.Amount, budget.Period, budget.BudgetMonth, budget.BudgetYear, budget.BudgetWeek, budget.RolloverMode, budget.RolloverCap, budget.AlertThresholds, budget.EnvelopeAccountID, budget.UpdatedAt, budget.ID)
+	return err
+}
+
+// UpdateAlertThresholds replaces the alert thresholds of a budget
+func (r *BudgetRepository) UpdateAlertThresholds(id uuid.UUID, thresholds []int64) error {
+	query := `UPDATE budgets SET alert_thresholds = $1, updated_at = $2 WHERE id = $3`
+	_, err := r.db.Exec(query, pq.Int64Array(thresholds), time.Now(), id)
 	return err
 }
 
+func defaultAlertThresholds() pq.Int64Array {
+	return pq.Int64Array{80, 100}
+}
+
 func (r *BudgetRepository) Delete(id uuid.UUID) error {
 	query := `DELETE FROM budgets WHERE id = $1`
 	_, err := r.db.Exec(query, id)
 	return err
 }
 
-// CopyFromMonth copies all budgets from one month to another
-func (r *BudgetRepository) CopyFromMonth(userID uuid.UUID, fromMonth, fromYear, toMonth, toYear int) (int, error) {
+// CopyFromMonth copies all budgets from one month to another and reports
+// the outcome per category
+func (r *BudgetRepository) CopyFromMonth(userID uuid.UUID, fromMonth, fromYear, toMonth, toYear int, opts models.BudgetCopyOptions) ([]models.BudgetCopyResult, error) {
 	// Get budgets from source month
 	sourceBudgets, err := r.GetByMonthYear(userID, fromMonth, fromYear)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
-	count := 0
+	targetBudgets, err := r.GetByMonthYear(userID, toMonth, toYear)
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]models.Budget)
+	for _, tb := range targetBudgets {
+		existing[tb.Category] = tb
+	}
+
+	results := []models.BudgetCopyResult{}
 	for _, sb := range sourceBudgets {
+		amount := math.Round(sb.Amount*(1+opts.ScalePercent/100)*100) / 100
+		result := models.BudgetCopyResult{Category: sb.Category, Amount: amount}
+
+		if tb, ok := existing[sb.Category]; ok {
+			if !opts.Overwrite {
+				result.Status = models.BudgetCopySkipped
+				results = append(results, result)
+				continue
+			}
+
+			tb.Amount = amount
+			tb.RolloverMode = sb.RolloverMode
+			tb.RolloverCap = sb.RolloverCap
+			tb.AlertThresholds = sb.AlertThresholds
+			tb.EnvelopeAccountID = sb.EnvelopeAccountID
+			result.Status = models.BudgetCopyOverwritten
+			if err := r.Update(&tb); err != nil {
+				log.Printf("Failed to overwrite %s budget: %v", sb.Category, err)
+				result.Status = models.BudgetCopyFailed
+				result.Error = "Failed to update budget"
+			}
+			results = append(results, result)
+			continue
+		}
+
 		newBudget := models.Budget{
-			UserID:      userID,
-			Category:    sb.Category,
-			Amount:      sb.Amount,
-			BudgetMonth: toMonth,
-			BudgetYear:  toYear,
+			UserID:            userID,
+			Category:          sb.Category,
+			Amount:            amount,
+			BudgetMonth:       toMonth,
+			BudgetYear:        toYear,
+			RolloverMode:      sb.RolloverMode,
+			RolloverCap:       sb.RolloverCap,
+			AlertThresholds:   sb.AlertThresholds,
+			EnvelopeAccountID: sb.EnvelopeAccountID,
+		}
+		result.Status = models.BudgetCopyCopied
+		if err := r.Create(&newBudget); err != nil {
+			log.Printf("Failed to copy %s budget: %v", sb.Category, err)
+			result.Status = models.BudgetCopyFailed
+			result.Error = "Failed to create budget"
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// UpsertMonth creates or updates the given monthly budgets of one month in a single
+// database transaction. Budgets with nil AlertThresholds keep their current
+// thresholds (or get the defaults when new), and budgets with a nil
+// EnvelopeAccountID keep their envelope. With removeMissing, budgets of
+// that month whose category is not in the list are deleted.
+func (r *BudgetRepository) UpsertMonth(userID uuid.UUID, month, year int, budgets []models.Budget, removeMissing bool) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO budgets (id, user_id, category, amount, period, budget_month, budget_year, budget_week, rollover_mode, rollover_cap, alert_thresholds, envelope_account_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, 'monthly', $5, $6, 0, $7, $8, COALESCE($9::integer[], $10::integer[]), $11, $12, $12)
+		ON CONFLICT (user_id, category, period, budget_year, budget_month, budget_week)
+		DO UPDATE SET amount = EXCLUDED.amount, rollover_mode = EXCLUDED.rollover_mode, rollover_cap = EXCLUDED.rollover_cap,
+			alert_thresholds = COALESCE($9::integer[], budgets.alert_thresholds),
+			envelope_account_id = COALESCE(EXCLUDED.envelope_account_id, budgets.envelope_account_id), updated_at = EXCLUDED.updated_at
+	`
+	now := time.Now()
+	categories := make([]string, 0, len(budgets))
+	for _, b := range budgets {
+		if b.RolloverMode == "" {
+			b.RolloverMode = models.RolloverNone
+		}
+		_, err := tx.Exec(query, uuid.New(), userID, b.Category, b.Amount, month, year, b.RolloverMode, b.RolloverCap, b.AlertThresholds, defaultAlertThresholds(), b.EnvelopeAccountID, now)
+		if err != nil {
+			return fmt.Errorf("failed to upsert budget %q: %w", b.Category, err)
+		}
+		categories = append(categories, b.Category)
+	}
+
+	if removeMissing {
+		_, err := tx.Exec(`DELETE FROM budgets WHERE user_id = $1 AND period = 'monthly' AND budget_month = $2 AND budget_year = $3 AND NOT (category = ANY($4))`,
+			userID, month, year, pq.StringArray(categories))
+		if err != nil {
+			return fmt.Errorf("failed to remove budgets: %w", err)
 		}
-		// Try to create, skip if duplicate
-		err := r.Create(&newBudget)
-		if err == nil {
-			count++
+	}
+
+	return tx.Commit()
+}
+
+// spendingSource yields (category, amount, transaction_date) rows of a user's
+// ($1) spending: account expenses plus credit card purchases and fees, with
+// card refunds counted negative. Card payments are transfers, not spending.
+const spendingSource = `
+	SELECT category, amount, transaction_date FROM transactions
+	WHERE user_id = $1 AND type = 'expense'
+	UNION ALL
+	SELECT category, CASE WHEN type = 'refund' THEN -amount ELSE amount END, transaction_date FROM credit_card_transactions
+	WHERE user_id = $1 AND type <> 'payment'
+`
+
+// GetSpentByCategory returns total spending per category within [start, end)
+func (r *BudgetRepository) GetSpentByCategory(userID uuid.UUID, start, end time.Time) (map[string]float64, error) {
+	rows, err := r.db.Query(`
+		SELECT category, COALESCE(SUM(amount), 0)
+		FROM (`+spendingSource+`) spending
+		WHERE transaction_date >= $2 AND transaction_date < $3
+		GROUP BY category
+	`, userID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	spent := make(map[string]float64)
+	for rows.Next() {
+		var category string
+		var amount float64
+		if err := rows.Scan(&category, &amount); err != nil {
+			return nil, err
 		}
+		spent[category] = amount
+	}
+	return spent, rows.Err()
+}
+
+// GetProgress compares each budget of a period with the actual spending in
+// that period. Monthly reports also include the year's yearly budgets,
+// prorated to the month.
+func (r *BudgetRepository) GetProgress(userID uuid.UUID, period models.BudgetPeriod, year, month, week int, now time.Time) (*models.BudgetProgressReport, error) {
+	month, week = periodFields(period, month, week)
+
+	budgets, err := r.GetByPeriod(userID, period, year, month, week)
+	if err != nil {
+		return nil, err
+	}
+
+	startDay, err := getMonthStartDay(r.db, userID)
+	if err != nil {
+		return nil, err
 	}
 
-	return count, nil
+	start, end := models.BudgetPeriodRange(period, year, month, week, startDay)
+	spent, err := r.GetSpentByCategory(userID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	rollover, err := r.GetRollover(userID, period, year, month, week)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.BudgetProgressReport{
+		Period:       period,
+		Month:        month,
+		Year:         year,
+		Week:         week,
+		PeriodStart:  start,
+		PeriodEnd:    end.AddDate(0, 0, -1),
+		DaysInPeriod: int(end.Sub(start).Hours() / 24),
+		Budgets:      []models.BudgetProgress{},
+		Unbudgeted:   []models.UnbudgetedCategory{},
+	}
+	report.DaysElapsed = daysElapsed(start, end, now)
+
+	budgeted := make(map[string]bool)
+	for _, b := range budgets {
+		budgeted[b.Category] = true
+		progress := models.BudgetProgress{
+			BudgetID:  b.ID,
+			Category:  b.Category,
+			Amount:    b.Amount,
+			Rollover:  rollover[b.Category],
+			Available: b.Amount + rollover[b.Category],
+			Spent:     spent[b.Category],
+		}
+		progress.Remaining = progress.Available - progress.Spent
+		if progress.Available > 0 {
+			progress.PercentUsed = (progress.Spent / progress.Available) * 100
+		}
+		if report.DaysElapsed > 0 {
+			progress.DailyBurnRate = progress.Spent / float64(report.DaysElapsed)
+			progress.ProjectedSpend = progress.DailyBurnRate * float64(report.DaysInPeriod)
+		}
+
+		report.TotalBudgeted += progress.Amount
+		report.TotalRollover += progress.Rollover
+		report.TotalSpent += progress.Spent
+		report.Budgets = append(report.Budgets, progress)
+	}
+	report.TotalRemaining = report.TotalBudgeted + report.TotalRollover - report.TotalSpent
+
+	if period == models.BudgetPeriodMonthly {
+		report.Yearly, err = r.getProratedYearly(userID, month, year, startDay, end, spent)
+		if err != nil {
+			return nil, err
+		}
+		for _, y := range report.Yearly {
+			budgeted[y.Category] = true
+		}
+	}
+
+	for category, amount := range spent {
+		if !budgeted[category] && amount > 0 {
+			report.Unbudgeted = append(report.Unbudgeted, models.UnbudgetedCategory{Category: category, Spent: amount})
+		}
+	}
+	sort.Slice(report.Unbudgeted, func(i, j int) bool {
+		return report.Unbudgeted[i].Spent > report.Unbudgeted[j].Spent
+	})
+
+	return report, nil
+}
+
+// getProratedYearly shows the yearly budgets of year from the point of view of
+// one month ending at monthEnd; monthSpent is that month's spending per category
+func (r *BudgetRepository) getProratedYearly(userID uuid.UUID, month, year, startDay int, monthEnd time.Time, monthSpent map[string]float64) ([]models.ProratedBudgetProgress, error) {
+	yearly, err := r.GetByPeriod(userID, models.BudgetPeriodYearly, year, 0, 0)
+	if err != nil || len(yearly) == 0 {
+		return nil, err
+	}
+
+	rollover, err := r.GetRollover(userID, models.BudgetPeriodYearly, year, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	yearStart, _ := models.FinancialYearRange(year, startDay)
+	spentToDate, err := r.GetSpentByCategory(userID, yearStart, monthEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	prorated := []models.ProratedBudgetProgress{}
+	for _, b := range yearly {
+		p := models.ProratedBudgetProgress{
+			BudgetID:        b.ID,
+			Category:        b.Category,
+			AnnualAmount:    b.Amount,
+			Rollover:        rollover[b.Category],
+			Available:       b.Amount + rollover[b.Category],
+			SpentThisMonth:  monthSpent[b.Category],
+			SpentYearToDate: spentToDate[b.Category],
+		}
+		p.MonthlyShare = p.Available / 12
+		p.ExpectedToDate = p.MonthlyShare * float64(month)
+		p.RemainingForYear = p.Available - p.SpentYearToDate
+		if p.Available > 0 {
+			p.PercentUsed = (p.SpentYearToDate / p.Available) * 100
+		}
+		prorated = append(prorated, p)
+	}
+	return prorated, nil
+}
+
+// GetRollover returns, per category, the amount carried into the given period.
+// Carry-over follows an unbroken chain of budgets of the same kind and category
+// (as produced by CopyFromMonth for monthly budgets); a period without a
+// budget resets the chain. Each period's own rollover settings decide what
+// leaves that period.
+func (r *BudgetRepository) GetRollover(userID uuid.UUID, period models.BudgetPeriod, year, month, week int) (map[string]float64, error) {
+	month, week = periodFields(period, month, week)
+
+	var budgets []models.Budget
+	query := `SELECT ` + budgetColumns + ` FROM budgets WHERE user_id = $1 AND period = $2`
+	if err := r.db.Select(&budgets, query, userID, period); err != nil {
+		return nil, err
+	}
+
+	target := periodIndex(period, year, month, week)
+
+	// Index earlier budgets by category and period number
+	byCategory := make(map[string]map[int]models.Budget)
+	for _, b := range budgets {
+		idx := periodIndex(period, b.BudgetYear, b.BudgetMonth, b.BudgetWeek)
+		if idx >= target {
+			continue
+		}
+		if byCategory[b.Category] == nil {
+			byCategory[b.Category] = make(map[int]models.Budget)
+		}
+		byCategory[b.Category][idx] = b
+	}
+
+	startDay, err := getMonthStartDay(r.db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	spentByPeriod := make(map[int]map[string]float64)
+	rollover := make(map[string]float64)
+
+	for category, periods := range byCategory {
+		first := target
+		for {
+			if _, ok := periods[first-1]; !ok {
+				break
+			}
+			first--
+		}
+
+		carry := 0.0
+		for idx := first; idx < target; idx++ {
+			spent, ok := spentByPeriod[idx]
+			if !ok {
+				start, end := periodRangeAt(period, idx, startDay)
+				var err error
+				spent, err = r.GetSpentByCategory(userID, start, end)
+				if err != nil {
+					return nil, err
+				}
+				spentByPeriod[idx] = spent
+			}
+
+			b := periods[idx]
+			carry = applyRollover(b, b.Amount+carry-spent[category])
+		}
+
+		if carry != 0 {
+			rollover[category] = carry
+		}
+	}
+
+	return rollover, nil
+}
+
+// applyRollover returns the part of a month's leftover that carries into the next month
+func applyRollover(budget models.Budget, leftover float64) float64 {
+	switch budget.RolloverMode {
+	case models.RolloverSurplus:
+		if leftover < 0 {
+			return 0
+		}
+	case models.RolloverSurplusAndDeficit:
+	default:
+		return 0
+	}
+
+	if budget.RolloverCap != nil {
+		if leftover > *budget.RolloverCap {
+			return *budget.RolloverCap
+		}
+		if leftover < -*budget.RolloverCap {
+			return -*budget.RolloverCap
+		}
+	}
+	return leftover
+}
+
+func monthIndex(month, year int) int {
+	return year*12 + month - 1
+}
+
+// periodFields zeroes the month and week where the period kind does not use them
+func periodFields(period models.BudgetPeriod, month, week int) (int, int) {
+	switch period {
+	case models.BudgetPeriodYearly:
+		return 0, 0
+	case models.BudgetPeriodWeekly:
+		return 0, week
+	default:
+		return month, 0
+	}
+}
+
+// periodIndex numbers periods of one kind so that idx-1 is the previous period
+func periodIndex(period models.BudgetPeriod, year, month, week int) int {
+	switch period {
+	case models.BudgetPeriodYearly:
+		return year
+	case models.BudgetPeriodWeekly:
+		// Mondays since the Unix epoch (1970-01-05 is Monday number 1)
+		start, _ := models.ISOWeekRange(year, week)
+		return (int(start.Unix()/86400) + 3) / 7
+	default:
+		return monthIndex(month, year)
+	}
+}
+
+// periodRangeAt returns the [start, end) bounds of the period numbered idx
+func periodRangeAt(period models.BudgetPeriod, idx, startDay int) (time.Time, time.Time) {
+	switch period {
+	case models.BudgetPeriodYearly:
+		return models.FinancialYearRange(idx, startDay)
+	case models.BudgetPeriodWeekly:
+		start := time.Unix(int64(idx*7-3)*86400, 0).UTC()
+		return start, start.AddDate(0, 0, 7)
+	default:
+		return models.FinancialMonthRange(idx%12+1, idx/12, startDay)
+	}
+}
+
+// MonthOf returns the user's budget month and year containing t, taking
+// their financial month start day into account. The year is also the budget
+// year that yearly budgets use.
+func (r *BudgetRepository) MonthOf(userID uuid.UUID, t time.Time) (int, int, error) {
+	startDay, err := getMonthStartDay(r.db, userID)
+	if err != nil {
+		return 0, 0, err
+	}
+	month, year := models.FinancialMonthOf(t, startDay)
+	return month, year, nil
+}
+
+// daysElapsed returns how many days of [start, end) have passed as of now
+func daysElapsed(start, end, now time.Time) int {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if today.Before(start) {
+		return 0
+	}
+	if !today.Before(end) {
+		return int(end.Sub(start).Hours() / 24)
+	}
+	return int(today.Sub(start).Hours()/24) + 1
 }
@@ -0,0 +1,243 @@
+package repository
+
+import (
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/financial-tracker/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+const (
+	// A category trends when spending moved more than this share of its average over the window
+	trendThreshold = 0.3
+	// A category is seasonal when the same month last year deviates this much from the average
+	seasonalThreshold = 0.5
+	// Suggested amounts are rounded up to a whole multiple of this
+	suggestionRounding = 1000
+)
+
+// GetSuggestions proposes budget amounts for the given month from the
+// spending of the preceding months
+func (r *BudgetRepository) GetSuggestions(userID uuid.UUID, month, year, months int, method models.SuggestionMethod, percentile float64) (*models.BudgetSuggestionReport, error) {
+	target := monthIndex(month, year)
+	first := target - months
+	// Always look one year back so seasonality can be checked
+	if target-12 < first {
+		first = target - 12
+	}
+
+	startDay, err := getMonthStartDay(r.db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	fromStart, _ := models.FinancialMonthRange(first%12+1, first/12, startDay)
+	toStart, _ := models.FinancialMonthRange(month, year, startDay)
+
+	// Aggregate per day, then bucket into the user's financial months
+	rows, err := r.db.Query(`
+		SELECT category, transaction_date::date, SUM(amount)
+		FROM (`+spendingSource+`) spending
+		WHERE transaction_date >= $2 AND transaction_date < $3
+		GROUP BY 1, 2
+	`, userID, fromStart, toStart)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	spending := make(map[string]map[int]float64)
+	for rows.Next() {
+		var category string
+		var day time.Time
+		var amount float64
+		if err := rows.Scan(&category, &day, &amount); err != nil {
+			return nil, err
+		}
+		if spending[category] == nil {
+			spending[category] = make(map[int]float64)
+		}
+		m, y := models.FinancialMonthOf(day, startDay)
+		spending[category][monthIndex(m, y)] += amount
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	current, err := r.GetByMonthYear(userID, month, year)
+	if err != nil {
+		return nil, err
+	}
+	currentAmounts := make(map[string]float64)
+	for _, b := range current {
+		currentAmounts[b.Category] = b.Amount
+	}
+
+	report := &models.BudgetSuggestionReport{
+		Month:       month,
+		Year:        year,
+		Months:      months,
+		Method:      method,
+		Suggestions: []models.BudgetSuggestion{},
+	}
+	if method == models.SuggestionPercentile {
+		report.Percentile = percentile
+	}
+
+	for category, byMonth := range spending {
+		values := make([]float64, months)
+		total := 0.0
+		suggestion := models.BudgetSuggestion{Category: category}
+		for i := range values {
+			values[i] = byMonth[target-months+i]
+			total += values[i]
+			if values[i] > 0 {
+				suggestion.MonthsWithSpending++
+			}
+		}
+		if total <= 0 {
+			continue
+		}
+
+		sorted := append([]float64{}, values...)
+		sort.Float64s(sorted)
+
+		suggestion.MonthlySpending = values
+		suggestion.Average = total / float64(months)
+		suggestion.Median = percentileOf(sorted, 50)
+		suggestion.Min = sorted[0]
+		suggestion.Max = sorted[len(sorted)-1]
+
+		switch method {
+		case models.SuggestionMedian:
+			suggestion.SuggestedAmount = suggestion.Median
+		case models.SuggestionPercentile:
+			suggestion.SuggestedAmount = percentileOf(sorted, percentile)
+		default:
+			suggestion.SuggestedAmount = suggestion.Average
+		}
+		suggestion.SuggestedAmount = math.Ceil(suggestion.SuggestedAmount/suggestionRounding) * suggestionRounding
+
+		suggestion.Trend = models.TrendStable
+		if months >= 3 {
+			change := linearSlope(values) * float64(months-1)
+			suggestion.TrendPercent = change / suggestion.Average * 100
+			if change > trendThreshold*suggestion.Average {
+				suggestion.Trend = models.TrendIncreasing
+			} else if change < -trendThreshold*suggestion.Average {
+				suggestion.Trend = models.TrendDecreasing
+			}
+		}
+
+		if lastYear, ok := byMonth[target-12]; ok {
+			suggestion.LastYearSameMonth = &lastYear
+			suggestion.Seasonal = math.Abs(lastYear-suggestion.Average) > seasonalThreshold*suggestion.Average
+		}
+
+		if amount, ok := currentAmounts[category]; ok {
+			suggestion.CurrentBudget = &amount
+		}
+
+		report.Suggestions = append(report.Suggestions, suggestion)
+	}
+
+	sort.Slice(report.Suggestions, func(i, j int) bool {
+		return report.Suggestions[i].SuggestedAmount > report.Suggestions[j].SuggestedAmount
+	})
+
+	return report, nil
+}
+
+// ApplyAmounts writes the given per-category amounts as budgets of a month.
+// Existing budgets keep their settings and only get a new amount when overwrite is set.
+func (r *BudgetRepository) ApplyAmounts(userID uuid.UUID, month, year int, amounts map[string]float64, overwrite bool) ([]models.BudgetCopyResult, error) {
+	current, err := r.GetByMonthYear(userID, month, year)
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]models.Budget)
+	for _, b := range current {
+		existing[b.Category] = b
+	}
+
+	categories := make([]string, 0, len(amounts))
+	for category := range amounts {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+
+	results := []models.BudgetCopyResult{}
+	for _, category := range categories {
+		result := models.BudgetCopyResult{Category: category, Amount: amounts[category]}
+
+		if b, ok := existing[category]; ok {
+			if !overwrite {
+				result.Status = models.BudgetCopySkipped
+				results = append(results, result)
+				continue
+			}
+			b.Amount = amounts[category]
+			result.Status = models.BudgetCopyOverwritten
+			if err := r.Update(&b); err != nil {
+				log.Printf("Failed to overwrite %s budget: %v", category, err)
+				result.Status = models.BudgetCopyFailed
+				result.Error = "Failed to update budget"
+			}
+			results = append(results, result)
+			continue
+		}
+
+		budget := models.Budget{
+			UserID:      userID,
+			Category:    category,
+			Amount:      amounts[category],
+			BudgetMonth: month,
+			BudgetYear:  year,
+		}
+		result.Status = models.BudgetCopyCopied
+		if err := r.Create(&budget); err != nil {
+			log.Printf("Failed to create %s budget: %v", category, err)
+			result.Status = models.BudgetCopyFailed
+			result.Error = "Failed to create budget"
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// percentileOf returns the p-th percentile (0-100) of sorted values using
+// linear interpolation between closest ranks
+func percentileOf(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	if lower == upper {
+		return sorted[lower]
+	}
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
+
+// linearSlope returns the least-squares slope of values against their index
+func linearSlope(values []float64) float64 {
+	n := float64(len(values))
+	var sumX, sumY, sumXY, sumXX float64
+	for i, v := range values {
+		x := float64(i)
+		sumX += x
+		sumY += v
+		sumXY += x * v
+		sumXX += x * x
+	}
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return 0
+	}
+	return (n*sumXY - sumX*sumY) / denominator
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/financial-tracker/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// lateFeeLookback is how many past statements are checked for unpaid due dates
+const lateFeeLookback = 12
+
+// Analyze reports utilization and the cost of revolving a card's balance
+func (r *CreditCardRepository) Analyze(card *models.CreditCard, now time.Time) (*models.CreditCardAnalysis, error) {
+	analysis := &models.CreditCardAnalysis{
+		CreditCardID:        card.ID,
+		CardName:            card.CardName,
+		CreditLimit:         card.CreditLimit,
+		CurrentBalance:      card.CurrentBalance,
+		InstallmentBalance:  card.InstallmentBalance,
+		InterestRateMonthly: card.InterestRateMonthly,
+	}
+	if card.CreditLimit > 0 {
+		analysis.Utilization = (card.CurrentBalance + card.InstallmentBalance) / card.CreditLimit * 100
+	}
+
+	analysis.MinimumPayment = card.MinimumPayment(card.CurrentBalance)
+	if remaining := card.CurrentBalance - analysis.MinimumPayment; remaining > 0 {
+		analysis.NextMonthInterest = remaining * card.InterestRateMonthly / 100
+	}
+	analysis.MinimumOnlyMonths, analysis.MinimumOnlyInterest, analysis.MinimumOnlyTotalPaid, analysis.MinimumOnlyPaysOff =
+		card.SimulateMinimumPayments(card.CurrentBalance)
+
+	// One extra statement tells what the oldest checked statement carried in
+	statements, err := r.GetStatements(card, lateFeeLookback+1, now)
+	if err != nil {
+		return nil, err
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	for i := 0; i < len(statements) && i < lateFeeLookback; i++ {
+		s := statements[i]
+		switch s.Status {
+		case models.StatementOverdue:
+			var previous *models.CreditCardStatement
+			if i+1 < len(statements) {
+				previous = &statements[i+1]
+			}
+			analysis.OverdueStatements++
+			analysis.LateFees += card.StatementLateFee(s, previous)
+		case models.StatementUnpaid, models.StatementPartiallyPaid:
+			if !s.DueDate.Before(today) && analysis.NextDueDate == nil {
+				due := s.DueDate
+				analysis.NextDueDate = &due
+				analysis.LateFeeIfMissed = card.LateFee(s.AmountDue)
+			}
+		}
+	}
+
+	return analysis, nil
+}
+
+// AnalyzeAll analyzes every card of a user and totals the results
+func (r *CreditCardRepository) AnalyzeAll(userID uuid.UUID, now time.Time) (*models.CreditCardAnalysisReport, error) {
+	cards, err := r.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.CreditCardAnalysisReport{Cards: []models.CreditCardAnalysis{}}
+	for i := range cards {
+		analysis, err := r.Analyze(&cards[i], now)
+		if err != nil {
+			return nil, err
+		}
+
+		report.TotalLimit += analysis.CreditLimit
+		report.TotalBalance += analysis.CurrentBalance
+		report.TotalInstallmentBalance += analysis.InstallmentBalance
+		report.TotalNextMonthInterest += analysis.NextMonthInterest
+		report.TotalMinimumOnlyInterest += analysis.MinimumOnlyInterest
+		report.TotalLateFees += analysis.LateFees
+		report.Cards = append(report.Cards, *analysis)
+	}
+	if report.TotalLimit > 0 {
+		report.Utilization = (report.TotalBalance + report.TotalInstallmentBalance) / report.TotalLimit * 100
+	}
+
+	return report, nil
+}
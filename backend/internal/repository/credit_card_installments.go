@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/financial-tracker/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const installmentColumns = `i.id, i.user_id, i.credit_card_id, i.description, i.category, i.principal, i.tenor_months,
+	i.interest_rate_monthly, i.admin_fee, i.start_month, i.start_year, i.created_at, i.updated_at,
+	(SELECT COUNT(*) FROM credit_card_transactions t WHERE t.installment_id = i.id AND t.installment_number > 0) AS installments_posted`
+
+// CreateInstallment saves a new plan for card and, in the same database
+// transaction, books the installments that are already due by now
+func (r *CreditCardRepository) CreateInstallment(plan *models.CreditCardInstallment, card *models.CreditCard, now time.Time) error {
+	plan.ID = uuid.New()
+	plan.CreatedAt = now
+	plan.UpdatedAt = now
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO credit_card_installments (id, user_id, credit_card_id, description, category, principal, tenor_months, interest_rate_monthly, admin_fee, start_month, start_year, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`
+	_, err = tx.Exec(query, plan.ID, plan.UserID, plan.CreditCardID, plan.Description, plan.Category, plan.Principal, plan.TenorMonths, plan.InterestRateMonthly, plan.AdminFee, plan.StartMonth, plan.StartYear, plan.CreatedAt, plan.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create installment plan: %w", err)
+	}
+
+	posted, err := postInstallments(tx, *plan, card.BillingDate, card.PaymentDueDate, now)
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	plan.InstallmentsPosted = posted
+	plan.Summarize()
+	return nil
+}
+
+// GetInstallments returns a card's installment plans, newest first
+func (r *CreditCardRepository) GetInstallments(cardID uuid.UUID) ([]models.CreditCardInstallment, error) {
+	plans := []models.CreditCardInstallment{}
+	query := `SELECT ` + installmentColumns + ` FROM credit_card_installments i WHERE i.credit_card_id = $1 ORDER BY i.created_at DESC`
+	if err := r.db.Select(&plans, query, cardID); err != nil {
+		return nil, err
+	}
+	for i := range plans {
+		plans[i].Summarize()
+	}
+	return plans, nil
+}
+
+// PostDueInstallments books, for every user, each installment whose
+// statement period has started by now. It runs on a schedule (see
+// cmd/main.go); postings are idempotent, so a run that overlaps another or
+// follows a failure books nothing twice.
+func (r *CreditCardRepository) PostDueInstallments(now time.Time) error {
+	var plans []struct {
+		models.CreditCardInstallment
+		BillingDate    int `db:"billing_date"`
+		PaymentDueDate int `db:"payment_due_date"`
+	}
+	query := `SELECT ` + installmentColumns + `, c.billing_date, c.payment_due_date
+		FROM credit_card_installments i JOIN credit_cards c ON c.id = i.credit_card_id`
+	if err := r.db.Select(&plans, query); err != nil {
+		return err
+	}
+
+	for _, p := range plans {
+		if p.InstallmentsPosted >= p.TenorMonths {
+			continue
+		}
+
+		tx, err := r.db.Beginx()
+		if err != nil {
+			return err
+		}
+		if _, err := postInstallments(tx, p.CreditCardInstallment, p.BillingDate, p.PaymentDueDate, now); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// postInstallments books the plan's installments after the ones already
+// posted whose statement period has started by now, dated on the first day
+// of that period. The admin fee is booked with the first installment. It
+// returns how many installments the plan has posted afterwards.
+func postInstallments(tx *sqlx.Tx, plan models.CreditCardInstallment, billingDate, paymentDueDate int, now time.Time) (int, error) {
+	posted := plan.InstallmentsPosted
+	for n := posted + 1; n <= plan.TenorMonths; n++ {
+		start, _, _ := models.StatementDates(plan.StartMonth+n-1, plan.StartYear, billingDate, paymentDueDate)
+		if start.After(now) {
+			break
+		}
+
+		principal, interest := plan.InstallmentAmount(n)
+		postings := []models.CreditCardTransaction{{
+			Type:        models.CreditCardInstallmentCharge,
+			Category:    plan.Category,
+			Amount:      principal + interest,
+			Description: fmt.Sprintf("%s (%d/%d)", plan.Description, n, plan.TenorMonths),
+		}}
+		if n == 1 && plan.AdminFee > 0 {
+			postings = append(postings, models.CreditCardTransaction{
+				Type:        models.CreditCardInstallmentCharge,
+				Category:    "Credit Card Fees",
+				Amount:      plan.AdminFee,
+				Description: fmt.Sprintf("%s (admin fee)", plan.Description),
+			})
+		}
+
+		for i := range postings {
+			number := n
+			if i > 0 {
+				number = 0
+			}
+			posting := postings[i]
+			posting.UserID = plan.UserID
+			posting.CreditCardID = plan.CreditCardID
+			posting.TransactionDate = start
+			posting.InstallmentID = &plan.ID
+			posting.InstallmentNumber = &number
+			if _, err := insertCreditCardTransaction(tx, &posting); err != nil {
+				return 0, err
+			}
+		}
+		posted = n
+	}
+
+	return posted, nil
+}
+
+// attachInstallmentBalances sets the unbilled installment principal and the
+// available credit of each card
+func (r *CreditCardRepository) attachInstallmentBalances(cards []models.CreditCard) error {
+	if len(cards) == 0 {
+		return nil
+	}
+
+	ids := make(pq.StringArray, len(cards))
+	for i, c := range cards {
+		ids[i] = c.ID.String()
+	}
+
+	plans := []models.CreditCardInstallment{}
+	query := `SELECT ` + installmentColumns + ` FROM credit_card_installments i WHERE i.credit_card_id = ANY($1::uuid[])`
+	if err := r.db.Select(&plans, query, ids); err != nil {
+		return err
+	}
+
+	outstanding := make(map[uuid.UUID]float64)
+	for _, p := range plans {
+		outstanding[p.CreditCardID] += p.PrincipalOutstanding(p.InstallmentsPosted)
+	}
+
+	for i := range cards {
+		cards[i].InstallmentBalance = outstanding[cards[i].ID]
+		cards[i].AvailableCredit = cards[i].CreditLimit - cards[i].CurrentBalance - cards[i].InstallmentBalance
+	}
+	return nil
+}
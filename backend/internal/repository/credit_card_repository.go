@@ -23,10 +23,10 @@ func (r *CreditCardRepository) Create(card *models.CreditCard) error {
 	card.UpdatedAt = time.Now()
 
 	query := `
-		INSERT INTO credit_cards (id, user_id, card_name, last_four_digits, credit_limit, current_balance, billing_date, payment_due_date, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		INSERT INTO credit_cards (id, user_id, card_name, last_four_digits, credit_limit, current_balance, billing_date, payment_due_date, min_payment_percent, min_payment_floor, interest_rate_monthly, late_fee_percent, late_fee_cap, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
 	`
-	_, err := r.db.Exec(query, card.ID, card.UserID, card.CardName, card.LastFourDigits, card.CreditLimit, card.CurrentBalance, card.BillingDate, card.PaymentDueDate, card.CreatedAt, card.UpdatedAt)
+	_, err := r.db.Exec(query, card.ID, card.UserID, card.CardName, card.LastFourDigits, card.CreditLimit, card.CurrentBalance, card.BillingDate, card.PaymentDueDate, card.MinPaymentPercent, card.MinPaymentFloor, card.InterestRateMonthly, card.LateFeePercent, card.LateFeeCap, card.CreatedAt, card.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to create credit card: %w", err)
 	}
@@ -36,29 +36,42 @@ func (r *CreditCardRepository) Create(card *models.CreditCard) error {
 
 func (r *CreditCardRepository) GetByUserID(userID uuid.UUID) ([]models.CreditCard, error) {
 	var cards []models.CreditCard
-	query := `SELECT id, user_id, card_name, last_four_digits, credit_limit, current_balance, billing_date, payment_due_date, created_at, updated_at FROM credit_cards WHERE user_id = $1 ORDER BY created_at DESC`
+	query := `SELECT id, user_id, card_name, last_four_digits, credit_limit, current_balance, billing_date, payment_due_date, min_payment_percent, min_payment_floor, interest_rate_monthly, late_fee_percent, late_fee_cap, created_at, updated_at FROM credit_cards WHERE user_id = $1 ORDER BY created_at DESC`
 	err := r.db.Select(&cards, query, userID)
 	if err != nil {
 		return nil, err
 	}
+	if err := r.attachInstallmentBalances(cards); err != nil {
+		return nil, err
+	}
 	return cards, nil
 }
 
 func (r *CreditCardRepository) GetByID(id uuid.UUID) (*models.CreditCard, error) {
 	var card models.CreditCard
-	query := `SELECT id, user_id, card_name, last_four_digits, credit_limit, current_balance, billing_date, payment_due_date, created_at, updated_at FROM credit_cards WHERE id = $1`
+	query := `SELECT id, user_id, card_name, last_four_digits, credit_limit, current_balance, billing_date, payment_due_date, min_payment_percent, min_payment_floor, interest_rate_monthly, late_fee_percent, late_fee_cap, created_at, updated_at FROM credit_cards WHERE id = $1`
 	err := r.db.Get(&card, query, id)
 	if err != nil {
 		return nil, err
 	}
-	return &card, nil
+	cards := []models.CreditCard{card}
+	if err := r.attachInstallmentBalances(cards); err != nil {
+		return nil, err
+	}
+	return &cards[0], nil
 }
 
+// Update saves the card details. The balance is left alone; it only changes
+// through card transactions.
 func (r *CreditCardRepository) Update(card *models.CreditCard) error {
 	card.UpdatedAt = time.Now()
-	query := `UPDATE credit_cards SET card_name = $1, credit_limit = $2, current_balance = $3, billing_date = $4, payment_due_date = $5, updated_at = $6 WHERE id = $7`
-	_, err := r.db.Exec(query, card.CardName, card.CreditLimit, card.CurrentBalance, card.BillingDate, card.PaymentDueDate, card.UpdatedAt, card.ID)
-	return err
+	query := `UPDATE credit_cards SET card_name = $1, credit_limit = $2, billing_date = $3, payment_due_date = $4, min_payment_percent = $5, min_payment_floor = $6, interest_rate_monthly = $7, late_fee_percent = $8, late_fee_cap = $9, updated_at = $10 WHERE id = $11`
+	_, err := r.db.Exec(query, card.CardName, card.CreditLimit, card.BillingDate, card.PaymentDueDate, card.MinPaymentPercent, card.MinPaymentFloor, card.InterestRateMonthly, card.LateFeePercent, card.LateFeeCap, card.UpdatedAt, card.ID)
+	if err != nil {
+		return err
+	}
+	card.AvailableCredit = card.CreditLimit - card.CurrentBalance - card.InstallmentBalance
+	return nil
 }
 
 func (r *CreditCardRepository) Delete(id uuid.UUID) error {
@@ -66,3 +79,125 @@ func (r *CreditCardRepository) Delete(id uuid.UUID) error {
 	_, err := r.db.Exec(query, id)
 	return err
 }
+
+const creditCardTransactionColumns = `id, user_id, credit_card_id, type, category, amount, description, transaction_date, transaction_id, installment_id, installment_number, created_at, updated_at`
+
+// CreateTransaction books a purchase, refund or fee on a card and adjusts the
+// card balance in the same database transaction
+func (r *CreditCardRepository) CreateTransaction(t *models.CreditCardTransaction) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := insertCreditCardTransaction(tx, t); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Pay books a payment of the card from a bank account. The account gets a
+// linked transfer transaction and both balances go down, all in one
+// database transaction. Paying more than the card balance returns
+// ErrCardOverpayment.
+func (r *CreditCardRepository) Pay(t *models.CreditCardTransaction, fromAccountID uuid.UUID, cardName string) (*models.Transaction, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var balance float64
+	if err := tx.Get(&balance, `SELECT current_balance FROM credit_cards WHERE id = $1 FOR UPDATE`, t.CreditCardID); err != nil {
+		return nil, err
+	}
+	if t.Amount > balance {
+		return nil, ErrCardOverpayment
+	}
+
+	now := time.Now()
+	transfer := &models.Transaction{
+		ID:              uuid.New(),
+		UserID:          t.UserID,
+		AccountID:       fromAccountID,
+		Type:            models.TransactionTypeTransfer,
+		Category:        t.Category,
+		Amount:          t.Amount,
+		Description:     t.Description,
+		TransactionDate: t.TransactionDate,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if transfer.Description == "" {
+		transfer.Description = fmt.Sprintf("Payment to %s", cardName)
+	}
+
+	if err := insertTransaction(tx, transfer); err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(`UPDATE accounts SET balance = balance - $1, updated_at = $2 WHERE id = $3`, t.Amount, now, fromAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update account balance: %w", err)
+	}
+
+	t.Type = models.CreditCardPayment
+	t.TransactionID = &transfer.ID
+	if _, err := insertCreditCardTransaction(tx, t); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return transfer, nil
+}
+
+// insertCreditCardTransaction books t and moves the card balance. An
+// installment posting that already exists is skipped; the result reports
+// whether t was booked.
+func insertCreditCardTransaction(tx *sqlx.Tx, t *models.CreditCardTransaction) (bool, error) {
+	t.ID = uuid.New()
+	t.CreatedAt = time.Now()
+	t.UpdatedAt = time.Now()
+
+	query := `
+		INSERT INTO credit_card_transactions (id, user_id, credit_card_id, type, category, amount, description, transaction_date, transaction_id, installment_id, installment_number, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		ON CONFLICT (installment_id, installment_number) WHERE installment_id IS NOT NULL DO NOTHING
+	`
+	result, err := tx.Exec(query, t.ID, t.UserID, t.CreditCardID, t.Type, t.Category, t.Amount, t.Description, t.TransactionDate, t.TransactionID, t.InstallmentID, t.InstallmentNumber, t.CreatedAt, t.UpdatedAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to create credit card transaction: %w", err)
+	}
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		return false, err
+	}
+
+	// The update locks the card row, which keeps reward caps exact when
+	// charges are booked concurrently
+	var billingDate int
+	err = tx.QueryRow(`UPDATE credit_cards SET current_balance = current_balance + $1, updated_at = $2 WHERE id = $3 RETURNING billing_date`,
+		t.Amount*t.Type.BalanceDelta(), t.UpdatedAt, t.CreditCardID).Scan(&billingDate)
+	if err != nil {
+		return false, fmt.Errorf("failed to update credit card balance: %w", err)
+	}
+	if err := recordRewardEarning(tx, t, billingDate); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// GetTransactions returns a card's transactions, newest first
+func (r *CreditCardRepository) GetTransactions(cardID uuid.UUID, limit, offset int) ([]models.CreditCardTransaction, error) {
+	transactions := []models.CreditCardTransaction{}
+	query := `SELECT ` + creditCardTransactionColumns + ` FROM credit_card_transactions
+		WHERE credit_card_id = $1 ORDER BY transaction_date DESC, created_at DESC LIMIT $2 OFFSET $3`
+	err := r.db.Select(&transactions, query, cardID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
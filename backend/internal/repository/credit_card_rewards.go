@@ -0,0 +1,306 @@
+package repository
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/financial-tracker/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+const rewardRuleColumns = `id, user_id, credit_card_id, category, reward_type, earn_rate, spend_unit, unit_value, monthly_cap, created_at, updated_at`
+
+func (r *CreditCardRepository) CreateRewardRule(rule *models.CreditCardRewardRule) error {
+	rule.ID = uuid.New()
+	rule.CreatedAt = time.Now()
+	rule.UpdatedAt = time.Now()
+
+	query := `
+		INSERT INTO credit_card_reward_rules (id, user_id, credit_card_id, category, reward_type, earn_rate, spend_unit, unit_value, monthly_cap, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+	_, err := r.db.Exec(query, rule.ID, rule.UserID, rule.CreditCardID, rule.Category, rule.RewardType, rule.EarnRate, rule.SpendUnit, rule.UnitValue, rule.MonthlyCap, rule.CreatedAt, rule.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create reward rule: %w", err)
+	}
+
+	return nil
+}
+
+// GetRewardRules returns a card's reward rules, the default rule last
+func (r *CreditCardRepository) GetRewardRules(cardID uuid.UUID) ([]models.CreditCardRewardRule, error) {
+	rules := []models.CreditCardRewardRule{}
+	query := `SELECT ` + rewardRuleColumns + ` FROM credit_card_reward_rules WHERE credit_card_id = $1 ORDER BY category ASC NULLS LAST`
+	if err := r.db.Select(&rules, query, cardID); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (r *CreditCardRepository) GetRewardRuleByID(id uuid.UUID) (*models.CreditCardRewardRule, error) {
+	var rule models.CreditCardRewardRule
+	query := `SELECT ` + rewardRuleColumns + ` FROM credit_card_reward_rules WHERE id = $1`
+	if err := r.db.Get(&rule, query, id); err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *CreditCardRepository) DeleteRewardRule(id uuid.UUID) error {
+	query := `DELETE FROM credit_card_reward_rules WHERE id = $1`
+	_, err := r.db.Exec(query, id)
+	return err
+}
+
+// CreateRedemption records a redemption, failing with ErrInsufficientRewards
+// when it is larger than the card's balance of that reward type
+func (r *CreditCardRepository) CreateRedemption(redemption *models.CreditCardRewardRedemption) error {
+	redemption.ID = uuid.New()
+	redemption.CreatedAt = time.Now()
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Lock the card so concurrent redemptions see each other
+	if _, err := tx.Exec(`SELECT id FROM credit_cards WHERE id = $1 FOR UPDATE`, redemption.CreditCardID); err != nil {
+		return err
+	}
+
+	var balance float64
+	err = tx.Get(&balance, `
+		SELECT
+			COALESCE((SELECT SUM(earned) FROM credit_card_reward_earnings WHERE credit_card_id = $1 AND reward_type = $2), 0) -
+			COALESCE((SELECT SUM(amount) FROM credit_card_reward_redemptions WHERE credit_card_id = $1 AND reward_type = $2), 0)
+	`, redemption.CreditCardID, redemption.RewardType)
+	if err != nil {
+		return err
+	}
+	if redemption.Amount > balance {
+		return ErrInsufficientRewards
+	}
+
+	query := `
+		INSERT INTO credit_card_reward_redemptions (id, user_id, credit_card_id, reward_type, amount, value, description, redeemed_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+	_, err = tx.Exec(query, redemption.ID, redemption.UserID, redemption.CreditCardID, redemption.RewardType, redemption.Amount, redemption.Value, redemption.Description, redemption.RedeemedAt, redemption.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create reward redemption: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// GetRewardSummary returns what a card has earned per statement cycle, what
+// was redeemed, and the balance per reward type
+func (r *CreditCardRepository) GetRewardSummary(card *models.CreditCard) (*models.CreditCardRewardSummary, error) {
+	rules, err := r.GetRewardRules(card.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	earnings := []models.RewardEarning{}
+	err = r.db.Select(&earnings, `
+		SELECT cycle_month, cycle_year, rule_id, category, reward_type,
+			SUM(spend) AS spend, SUM(earned) AS earned, bool_or(capped) AS capped
+		FROM credit_card_reward_earnings WHERE credit_card_id = $1
+		GROUP BY cycle_month, cycle_year, rule_id, category, reward_type
+		ORDER BY cycle_year DESC, cycle_month DESC, category ASC
+	`, card.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	redemptions := []models.CreditCardRewardRedemption{}
+	err = r.db.Select(&redemptions, `
+		SELECT id, user_id, credit_card_id, reward_type, amount, value, description, redeemed_at, created_at
+		FROM credit_card_reward_redemptions WHERE credit_card_id = $1 ORDER BY redeemed_at DESC
+	`, card.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Value units at the highest unit value configured for their type
+	unitValue := make(map[models.RewardType]float64)
+	for _, rule := range rules {
+		if rule.UnitValue > unitValue[rule.RewardType] {
+			unitValue[rule.RewardType] = rule.UnitValue
+		}
+	}
+
+	byType := make(map[models.RewardType]*models.RewardBalance)
+	balanceOf := func(t models.RewardType) *models.RewardBalance {
+		if byType[t] == nil {
+			byType[t] = &models.RewardBalance{RewardType: t}
+		}
+		return byType[t]
+	}
+	for _, e := range earnings {
+		balanceOf(e.RewardType).Earned += e.Earned
+	}
+	for _, rd := range redemptions {
+		balanceOf(rd.RewardType).Redeemed += rd.Amount
+	}
+
+	summary := &models.CreditCardRewardSummary{
+		CreditCardID: card.ID,
+		Balances:     []models.RewardBalance{},
+		Earnings:     earnings,
+		Redemptions:  redemptions,
+	}
+	for _, b := range byType {
+		b.Balance = b.Earned - b.Redeemed
+		b.Value = b.Balance * unitValue[b.RewardType]
+		summary.Balances = append(summary.Balances, *b)
+	}
+	sort.Slice(summary.Balances, func(i, j int) bool {
+		return summary.Balances[i].RewardType < summary.Balances[j].RewardType
+	})
+
+	return summary, nil
+}
+
+// BestCardsFor ranks the user's cards by the reward value they would give on
+// a purchase in category. With amount > 0 the estimate respects what each
+// rule has already earned in the current statement cycle.
+func (r *CreditCardRepository) BestCardsFor(userID uuid.UUID, category string, amount float64, now time.Time) ([]models.BestCardOption, error) {
+	cards, err := r.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	options := []models.BestCardOption{}
+	for i := range cards {
+		card := &cards[i]
+		rules, err := r.GetRewardRules(card.ID)
+		if err != nil {
+			return nil, err
+		}
+		rule := matchRewardRule(rules, category)
+		if rule == nil {
+			continue
+		}
+
+		option := models.BestCardOption{
+			CreditCardID:    card.ID,
+			CardName:        card.CardName,
+			RuleID:          rule.ID,
+			RewardType:      rule.RewardType,
+			ReturnPercent:   rule.ReturnPercent(),
+			AvailableCredit: card.AvailableCredit,
+		}
+
+		month, year := models.StatementMonthOf(now, card.BillingDate)
+		earnedSoFar, err := earnedInCycle(r.db, rule.ID, month, year)
+		if err != nil {
+			return nil, err
+		}
+		if rule.MonthlyCap != nil {
+			remaining := *rule.MonthlyCap - earnedSoFar
+			if remaining < 0 {
+				remaining = 0
+			}
+			option.RemainingCap = &remaining
+		}
+		if amount > 0 {
+			option.EstimatedReward = rule.Earn(amount, earnedSoFar)
+			option.EstimatedValue = option.EstimatedReward * rule.UnitValue
+		}
+
+		options = append(options, option)
+	}
+
+	sort.SliceStable(options, func(i, j int) bool {
+		if amount > 0 && options[i].EstimatedValue != options[j].EstimatedValue {
+			return options[i].EstimatedValue > options[j].EstimatedValue
+		}
+		return options[i].ReturnPercent > options[j].ReturnPercent
+	})
+
+	return options, nil
+}
+
+// recordRewardEarning stores what a booked charge earns under the card's
+// current rules. Purchases and installment charges earn; refunds take back
+// what their amount earned, at most what the rule earned in that cycle.
+// Installment admin fees earn nothing.
+func recordRewardEarning(tx *sqlx.Tx, t *models.CreditCardTransaction, billingDate int) error {
+	switch t.Type {
+	case models.CreditCardPurchase, models.CreditCardRefund:
+	case models.CreditCardInstallmentCharge:
+		if t.InstallmentNumber == nil || *t.InstallmentNumber == 0 {
+			return nil
+		}
+	default:
+		return nil
+	}
+
+	rules := []models.CreditCardRewardRule{}
+	query := `SELECT ` + rewardRuleColumns + ` FROM credit_card_reward_rules WHERE credit_card_id = $1`
+	if err := tx.Select(&rules, query, t.CreditCardID); err != nil {
+		return err
+	}
+	rule := matchRewardRule(rules, t.Category)
+	if rule == nil {
+		return nil
+	}
+
+	month, year := models.StatementMonthOf(t.TransactionDate, billingDate)
+	earnedSoFar, err := earnedInCycle(tx, rule.ID, month, year)
+	if err != nil {
+		return err
+	}
+
+	uncapped := *rule
+	uncapped.MonthlyCap = nil
+	spend := t.Amount
+	earned := rule.Earn(t.Amount, earnedSoFar)
+	capped := uncapped.Earn(t.Amount, 0) > earned
+	if t.Type == models.CreditCardRefund {
+		spend = -t.Amount
+		earned = -math.Min(uncapped.Earn(t.Amount, 0), math.Max(earnedSoFar, 0))
+		capped = false
+	}
+
+	category := "(other)"
+	if rule.Category != nil {
+		category = *rule.Category
+	}
+	_, err = tx.Exec(`
+		INSERT INTO credit_card_reward_earnings (id, user_id, credit_card_id, credit_card_transaction_id, rule_id, category, reward_type, cycle_month, cycle_year, spend, earned, capped, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`, uuid.New(), t.UserID, t.CreditCardID, t.ID, rule.ID, category, rule.RewardType, month, year, spend, earned, capped, t.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record reward earning: %w", err)
+	}
+	return nil
+}
+
+// earnedInCycle returns the units a rule has earned in one statement cycle
+func earnedInCycle(q sqlx.Queryer, ruleID uuid.UUID, month, year int) (float64, error) {
+	var earned float64
+	err := sqlx.Get(q, &earned, `
+		SELECT COALESCE(SUM(earned), 0) FROM credit_card_reward_earnings
+		WHERE rule_id = $1 AND cycle_month = $2 AND cycle_year = $3
+	`, ruleID, month, year)
+	return earned, err
+}
+
+// matchRewardRule returns the rule for category, falling back to the default rule
+func matchRewardRule(rules []models.CreditCardRewardRule, category string) *models.CreditCardRewardRule {
+	var fallback *models.CreditCardRewardRule
+	for i := range rules {
+		if rules[i].Category == nil {
+			fallback = &rules[i]
+		} else if *rules[i].Category == category {
+			return &rules[i]
+		}
+	}
+	return fallback
+}
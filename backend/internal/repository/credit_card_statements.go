@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"math"
+	"time"
+
+	"github.com/financial-tracker/backend/internal/models"
+)
+
+type cardMovement struct {
+	Type            models.CreditCardTransactionType `db:"type"`
+	Amount          float64                          `db:"amount"`
+	TransactionDate time.Time                        `db:"transaction_date"`
+}
+
+// GetStatements computes the card's last count statements, newest first,
+// starting with the one whose period contains now. Balances are derived
+// backwards from the current balance, so cards opened with a balance work
+// without a full transaction history.
+func (r *CreditCardRepository) GetStatements(card *models.CreditCard, count int, now time.Time) ([]models.CreditCardStatement, error) {
+	month, year := models.StatementMonthOf(now, card.BillingDate)
+	oldest := time.Date(year, time.Month(month-count+1), 1, 0, 0, 0, 0, time.UTC)
+	since, _, _ := models.StatementDates(int(oldest.Month()), oldest.Year(), card.BillingDate, card.PaymentDueDate)
+
+	var movements []cardMovement
+	err := r.db.Select(&movements, `
+		SELECT type, amount, transaction_date FROM credit_card_transactions
+		WHERE credit_card_id = $1 AND transaction_date >= $2
+	`, card.ID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	// balanceBefore returns the card balance at the start of day t
+	balanceBefore := func(t time.Time) float64 {
+		balance := card.CurrentBalance
+		for _, m := range movements {
+			if !m.TransactionDate.Before(t) {
+				balance -= m.Amount * m.Type.BalanceDelta()
+			}
+		}
+		return balance
+	}
+
+	// paymentsBetween sums payments within [from, to)
+	paymentsBetween := func(from, to time.Time) float64 {
+		total := 0.0
+		for _, m := range movements {
+			if m.Type == models.CreditCardPayment && !m.TransactionDate.Before(from) && m.TransactionDate.Before(to) {
+				total += m.Amount
+			}
+		}
+		return total
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	statements := []models.CreditCardStatement{}
+
+	for i := 0; i < count; i++ {
+		period := time.Date(year, time.Month(month-i), 1, 0, 0, 0, 0, time.UTC)
+		start, closing, due := models.StatementDates(int(period.Month()), period.Year(), card.BillingDate, card.PaymentDueDate)
+		afterClosing := closing.AddDate(0, 0, 1)
+		_, nextClosing, _ := models.StatementDates(int(period.Month())+1, period.Year(), card.BillingDate, card.PaymentDueDate)
+
+		statement := models.CreditCardStatement{
+			CreditCardID:   card.ID,
+			Month:          int(period.Month()),
+			Year:           period.Year(),
+			PeriodStart:    start,
+			ClosingDate:    closing,
+			DueDate:        due,
+			OpeningBalance: balanceBefore(start),
+			ClosingBalance: balanceBefore(afterClosing),
+		}
+		for _, m := range movements {
+			if m.TransactionDate.Before(start) || !m.TransactionDate.Before(afterClosing) {
+				continue
+			}
+			switch m.Type {
+			case models.CreditCardPurchase:
+				statement.Purchases += m.Amount
+			case models.CreditCardFee:
+				statement.Fees += m.Amount
+			case models.CreditCardRefund:
+				statement.Refunds += m.Amount
+			case models.CreditCardInstallmentCharge:
+				statement.Installments += m.Amount
+			case models.CreditCardPayment:
+				statement.PaymentsInPeriod += m.Amount
+			}
+		}
+
+		statement.MinimumPayment = card.MinimumPayment(statement.ClosingBalance)
+		statement.AmountPaid = paymentsBetween(afterClosing, nextClosing.AddDate(0, 0, 1))
+		statement.AmountDue = math.Max(statement.ClosingBalance-statement.AmountPaid, 0)
+		paidByDue := paymentsBetween(afterClosing, due.AddDate(0, 0, 1))
+
+		switch {
+		case !today.After(closing):
+			statement.Status = models.StatementCurrent
+		case statement.AmountDue == 0:
+			statement.Status = models.StatementPaid
+		case today.After(due) && paidByDue < statement.MinimumPayment:
+			statement.Status = models.StatementOverdue
+		case statement.AmountPaid > 0:
+			statement.Status = models.StatementPartiallyPaid
+		default:
+			statement.Status = models.StatementUnpaid
+		}
+
+		statements = append(statements, statement)
+	}
+
+	return statements, nil
+}
@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+// ErrNegativeBudget is returned when taking more money out of a budget than it holds
+var ErrNegativeBudget = errors.New("budget amount cannot become negative")
+
+// ErrCardOverpayment is returned when a payment is larger than the card balance
+var ErrCardOverpayment = errors.New("payment exceeds the card balance")
+
+// ErrInsufficientRewards is returned when redeeming more units than a card has earned
+var ErrInsufficientRewards = errors.New("not enough rewards to redeem")
+
+// ErrInvalidGoldSale is returned when a sale cannot be made from the user's
+// lots, e.g. more weight than is held
+var ErrInvalidGoldSale = errors.New("invalid gold sale")
+
+// ErrInvalidGoldAsset is returned when an edit of a lot conflicts with what
+// was already sold from it
+var ErrInvalidGoldAsset = errors.New("invalid gold asset")
+
+// ErrInvalidGoldPiece is returned when pieces do not fit in their lot
+var ErrInvalidGoldPiece = errors.New("invalid gold piece")
+
+// IsDuplicateError reports whether err is a unique constraint violation
+func IsDuplicateError(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/financial-tracker/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+const goldAlertColumns = `id, user_id, alert_type, gold_type, price_kind, threshold, is_active, is_triggered, last_triggered_at, created_at, updated_at`
+
+func (r *GoldRepository) CreateAlert(alert *models.GoldPriceAlert) error {
+	alert.ID = uuid.New()
+	alert.CreatedAt = time.Now()
+	alert.UpdatedAt = alert.CreatedAt
+
+	query := `
+		INSERT INTO gold_price_alerts (id, user_id, alert_type, gold_type, price_kind, threshold, is_active, is_triggered, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, false, $8, $9)
+	`
+	_, err := r.db.Exec(query, alert.ID, alert.UserID, alert.AlertType, alert.GoldType, alert.PriceKind, alert.Threshold, alert.IsActive, alert.CreatedAt, alert.UpdatedAt)
+	return err
+}
+
+func (r *GoldRepository) GetAlertsByUserID(userID uuid.UUID) ([]models.GoldPriceAlert, error) {
+	alerts := []models.GoldPriceAlert{}
+	query := `SELECT ` + goldAlertColumns + ` FROM gold_price_alerts WHERE user_id = $1 ORDER BY created_at DESC`
+	if err := r.db.Select(&alerts, query, userID); err != nil {
+		return nil, err
+	}
+	return alerts, nil
+}
+
+func (r *GoldRepository) GetAlertByID(id uuid.UUID) (*models.GoldPriceAlert, error) {
+	var alert models.GoldPriceAlert
+	query := `SELECT ` + goldAlertColumns + ` FROM gold_price_alerts WHERE id = $1`
+	if err := r.db.Get(&alert, query, id); err != nil {
+		return nil, err
+	}
+	return &alert, nil
+}
+
+// UpdateAlert saves the alert's settings and re-arms it, so the new
+// condition fires on the next quote that meets it
+func (r *GoldRepository) UpdateAlert(alert *models.GoldPriceAlert) error {
+	alert.UpdatedAt = time.Now()
+	alert.IsTriggered = false
+	query := `UPDATE gold_price_alerts SET alert_type = $1, gold_type = $2, price_kind = $3, threshold = $4, is_active = $5, is_triggered = false, updated_at = $6 WHERE id = $7`
+	_, err := r.db.Exec(query, alert.AlertType, alert.GoldType, alert.PriceKind, alert.Threshold, alert.IsActive, alert.UpdatedAt, alert.ID)
+	return err
+}
+
+func (r *GoldRepository) DeleteAlert(id uuid.UUID) error {
+	_, err := r.db.Exec(`DELETE FROM gold_price_alerts WHERE id = $1`, id)
+	return err
+}
+
+// GetActivePriceAlerts returns the active price_above, price_below and
+// daily_change alerts on goldType
+func (r *GoldRepository) GetActivePriceAlerts(goldType models.GoldType) ([]models.GoldPriceAlert, error) {
+	alerts := []models.GoldPriceAlert{}
+	query := `SELECT ` + goldAlertColumns + ` FROM gold_price_alerts
+		WHERE is_active AND alert_type IN ('price_above', 'price_below', 'daily_change') AND gold_type = $1`
+	if err := r.db.Select(&alerts, query, goldType); err != nil {
+		return nil, err
+	}
+	return alerts, nil
+}
+
+// GetActivePositionAlerts returns the active position_pl alerts ordered by
+// user. Any quote can move any brand's position, as a brand without a quote
+// of the latest date is valued through the fallbacks of GoldQuotes.For.
+func (r *GoldRepository) GetActivePositionAlerts() ([]models.GoldPriceAlert, error) {
+	alerts := []models.GoldPriceAlert{}
+	query := `SELECT ` + goldAlertColumns + ` FROM gold_price_alerts
+		WHERE is_active AND alert_type = 'position_pl'
+		ORDER BY user_id`
+	if err := r.db.Select(&alerts, query); err != nil {
+		return nil, err
+	}
+	return alerts, nil
+}
+
+// SetAlertTriggered records whether the alert's condition holds; firing
+// also stamps last_triggered_at
+func (r *GoldRepository) SetAlertTriggered(id uuid.UUID, triggered bool) error {
+	query := `UPDATE gold_price_alerts SET is_triggered = $1,
+		last_triggered_at = CASE WHEN $1 THEN NOW() ELSE last_triggered_at END WHERE id = $2`
+	_, err := r.db.Exec(query, triggered, id)
+	return err
+}
+
+// GetPriceBefore returns the latest quote of goldType before priceDate, nil
+// when there is none
+func (r *GoldRepository) GetPriceBefore(goldType models.GoldType, priceDate time.Time) (*models.GoldPrice, error) {
+	var price models.GoldPrice
+	query := `SELECT ` + goldPriceColumns + ` FROM gold_prices WHERE gold_type = $1 AND price_date < $2 ORDER BY price_date DESC LIMIT 1`
+	err := r.db.Get(&price, query, goldType, priceDate)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &price, nil
+}
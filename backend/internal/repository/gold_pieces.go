@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/financial-tracker/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const goldPieceColumns = `id, user_id, asset_id, denomination_gram, certificate_number, minting_year, packaging_condition, COALESCE(notes, '') AS notes, sale_lot_id, created_at, updated_at`
+
+// GetPieces returns the pieces of a lot, sold ones included
+func (r *GoldRepository) GetPieces(assetID uuid.UUID) ([]models.GoldPiece, error) {
+	pieces := []models.GoldPiece{}
+	query := `SELECT ` + goldPieceColumns + ` FROM gold_pieces WHERE asset_id = $1 ORDER BY created_at, certificate_number`
+	if err := r.db.Select(&pieces, query, assetID); err != nil {
+		return nil, err
+	}
+	return pieces, nil
+}
+
+func (r *GoldRepository) GetPieceByID(id uuid.UUID) (*models.GoldPiece, error) {
+	var piece models.GoldPiece
+	query := `SELECT ` + goldPieceColumns + ` FROM gold_pieces WHERE id = $1`
+	if err := r.db.Get(&piece, query, id); err != nil {
+		return nil, err
+	}
+	return &piece, nil
+}
+
+// AddPieces adds pieces to a lot. Together with its unsold pieces they must
+// fit in the lot's remaining weight.
+func (r *GoldRepository) AddPieces(assetID uuid.UUID, pieces []*models.GoldPiece) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	added := 0.0
+	for _, p := range pieces {
+		added += p.DenominationGram
+	}
+	if err := checkPiecesFit(tx, assetID, nil, added); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, p := range pieces {
+		p.ID = uuid.New()
+		p.AssetID = &assetID
+		p.CreatedAt = now
+		p.UpdatedAt = now
+		_, err = tx.Exec(`
+			INSERT INTO gold_pieces (id, user_id, asset_id, denomination_gram, certificate_number, minting_year, packaging_condition, notes, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		`, p.ID, p.UserID, p.AssetID, p.DenominationGram, p.CertificateNumber, p.MintingYear, p.PackagingCondition, p.Notes, p.CreatedAt, p.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to create gold piece: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// UpdatePiece saves a piece's details. An unsold piece must still fit in
+// its lot with the lot's other unsold pieces.
+func (r *GoldRepository) UpdatePiece(p *models.GoldPiece) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if p.SaleLotID == nil {
+		if err := checkPiecesFit(tx, *p.AssetID, &p.ID, p.DenominationGram); err != nil {
+			return err
+		}
+	}
+
+	p.UpdatedAt = time.Now()
+	_, err = tx.Exec(`UPDATE gold_pieces SET denomination_gram = $1, certificate_number = $2, minting_year = $3,
+		packaging_condition = $4, notes = $5, updated_at = $6 WHERE id = $7`,
+		p.DenominationGram, p.CertificateNumber, p.MintingYear, p.PackagingCondition, p.Notes, p.UpdatedAt, p.ID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *GoldRepository) DeletePiece(id uuid.UUID) error {
+	_, err := r.db.Exec(`DELETE FROM gold_pieces WHERE id = $1`, id)
+	return err
+}
+
+// checkPiecesFit locks the lot and checks that its unsold pieces, except
+// exclude, plus weight more fit in its remaining weight
+func checkPiecesFit(tx *sqlx.Tx, assetID uuid.UUID, exclude *uuid.UUID, weight float64) error {
+	var remaining float64
+	if err := tx.Get(&remaining, `SELECT remaining_weight_gram FROM gold_assets WHERE id = $1 FOR UPDATE`, assetID); err != nil {
+		return err
+	}
+
+	var inPieces float64
+	err := tx.Get(&inPieces, `SELECT COALESCE(SUM(denomination_gram), 0) FROM gold_pieces
+		WHERE asset_id = $1 AND sale_lot_id IS NULL AND ($2::uuid IS NULL OR id <> $2)`, assetID, exclude)
+	if err != nil {
+		return err
+	}
+
+	if total := models.RoundGram(inPieces + weight); total > remaining {
+		return fmt.Errorf("%w: the pieces add up to %.4fg but the lot holds %.4fg", ErrInvalidGoldPiece, total, remaining)
+	}
+	return nil
+}
+
+// lockUnsoldPieces returns the unsold pieces of the given lots, locked for a
+// sale
+func lockUnsoldPieces(tx *sqlx.Tx, lots []models.GoldAsset) ([]models.GoldPiece, error) {
+	ids := make(pq.StringArray, len(lots))
+	for i, lot := range lots {
+		ids[i] = lot.ID.String()
+	}
+	pieces := []models.GoldPiece{}
+	err := tx.Select(&pieces, `SELECT `+goldPieceColumns+` FROM gold_pieces
+		WHERE asset_id = ANY($1::uuid[]) AND sale_lot_id IS NULL FOR UPDATE`, ids)
+	return pieces, err
+}
+
+// GetInventory lists every unsold piece of the user, and the loose weight
+// of lots not in any piece, by storage location (or only at location if
+// not empty)
+func (r *GoldRepository) GetInventory(userID uuid.UUID, location string) ([]models.GoldInventoryItem, error) {
+	items := []models.GoldInventoryItem{}
+	err := r.db.Select(&items, `
+		SELECT p.id AS piece_id, a.id AS asset_id, a.name AS asset_name, a.gold_type, a.item_kind, a.purity,
+			COALESCE(a.storage_location, '') AS storage_location, p.denomination_gram AS weight_gram,
+			p.certificate_number, p.minting_year, p.packaging_condition, a.purchase_date
+		FROM gold_pieces p JOIN gold_assets a ON a.id = p.asset_id
+		WHERE a.user_id = $1 AND p.sale_lot_id IS NULL AND ($2 = '' OR COALESCE(a.storage_location, '') = $2)
+		UNION ALL
+		SELECT NULL, a.id, a.name, a.gold_type, a.item_kind, a.purity,
+			COALESCE(a.storage_location, ''), a.remaining_weight_gram - COALESCE(SUM(p.denomination_gram), 0),
+			NULL, NULL, NULL, a.purchase_date
+		FROM gold_assets a LEFT JOIN gold_pieces p ON p.asset_id = a.id AND p.sale_lot_id IS NULL
+		WHERE a.user_id = $1 AND a.remaining_weight_gram > 0 AND ($2 = '' OR COALESCE(a.storage_location, '') = $2)
+		GROUP BY a.id
+		HAVING a.remaining_weight_gram - COALESCE(SUM(p.denomination_gram), 0) > 0
+		ORDER BY storage_location, purchase_date, asset_name, certificate_number
+	`, userID, location)
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/financial-tracker/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+const goldPriceChangeColumns = `c.id, c.price_date, c.gold_type, c.action, c.old_buy_price_per_gram, c.old_buyback_price_per_gram,
+	c.new_buy_price_per_gram, c.new_buyback_price_per_gram, COALESCE(c.source, '') AS source, c.changed_by,
+	COALESCE(u.email, '') AS changed_by_email, c.changed_at`
+
+// SetPrice creates or corrects the quote of price.PriceDate and
+// price.GoldType and records the change made by changedBy (nil for the
+// fetcher). It returns the action taken; writing the same prices again is
+// not recorded and returns "".
+func (r *GoldRepository) SetPrice(price models.GoldPrice, changedBy *uuid.UUID) (models.GoldPriceAction, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	action, err := setPrice(tx, price, changedBy)
+	if err != nil {
+		return "", err
+	}
+	if err := tx.Commit(); err != nil {
+		return "", err
+	}
+	if action != "" {
+		r.priceSet(price)
+	}
+	return action, nil
+}
+
+// ImportPrices writes all prices in one database transaction, so a failed
+// row leaves the table unchanged
+func (r *GoldRepository) ImportPrices(prices []models.GoldPrice, changedBy *uuid.UUID) (*models.GoldPriceImportResult, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	result := &models.GoldPriceImportResult{Rows: len(prices)}
+	written := []models.GoldPrice{}
+	for _, price := range prices {
+		action, err := setPrice(tx, price, changedBy)
+		if err != nil {
+			return nil, err
+		}
+		switch action {
+		case models.GoldPriceCreated:
+			result.Created++
+		case models.GoldPriceUpdated:
+			result.Updated++
+		default:
+			result.Unchanged++
+			continue
+		}
+		written = append(written, price)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	for _, price := range models.NewGoldQuotes(written) {
+		r.priceSet(price)
+	}
+	return result, nil
+}
+
+func setPrice(tx *sqlx.Tx, price models.GoldPrice, changedBy *uuid.UUID) (models.GoldPriceAction, error) {
+	var old models.GoldPrice
+	err := tx.Get(&old, `SELECT `+goldPriceColumns+` FROM gold_prices WHERE price_date = $1 AND gold_type = $2 FOR UPDATE`,
+		price.PriceDate, price.GoldType)
+	if err != nil && err != sql.ErrNoRows {
+		return "", err
+	}
+	exists := err == nil
+	if exists && old.BuyPricePerGram == price.BuyPricePerGram && old.BuybackPricePerGram == price.BuybackPricePerGram && old.Source == price.Source {
+		return "", nil
+	}
+
+	now := time.Now()
+	_, err = tx.Exec(`
+		INSERT INTO gold_prices (id, price_date, gold_type, buy_price_per_gram, buyback_price_per_gram, source, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (price_date, gold_type)
+		DO UPDATE SET buy_price_per_gram = $4, buyback_price_per_gram = $5, source = $6, updated_at = $8
+	`, uuid.New(), price.PriceDate, price.GoldType, price.BuyPricePerGram, price.BuybackPricePerGram, price.Source, now, now)
+	if err != nil {
+		return "", err
+	}
+
+	change := models.GoldPriceChange{
+		PriceDate:              price.PriceDate,
+		GoldType:               price.GoldType,
+		Action:                 models.GoldPriceCreated,
+		NewBuyPricePerGram:     &price.BuyPricePerGram,
+		NewBuybackPricePerGram: &price.BuybackPricePerGram,
+		Source:                 price.Source,
+		ChangedBy:              changedBy,
+	}
+	if exists {
+		change.Action = models.GoldPriceUpdated
+		change.OldBuyPricePerGram = &old.BuyPricePerGram
+		change.OldBuybackPricePerGram = &old.BuybackPricePerGram
+	}
+	return change.Action, recordPriceChange(tx, &change)
+}
+
+// DeletePrice removes the quote of priceDate and goldType, returning
+// sql.ErrNoRows when there is none
+func (r *GoldRepository) DeletePrice(priceDate time.Time, goldType models.GoldType, changedBy *uuid.UUID) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var old models.GoldPrice
+	err = tx.Get(&old, `DELETE FROM gold_prices WHERE price_date = $1 AND gold_type = $2 RETURNING `+goldPriceColumns,
+		priceDate, goldType)
+	if err != nil {
+		return err
+	}
+
+	change := models.GoldPriceChange{
+		PriceDate:              old.PriceDate,
+		GoldType:               old.GoldType,
+		Action:                 models.GoldPriceDeleted,
+		OldBuyPricePerGram:     &old.BuyPricePerGram,
+		OldBuybackPricePerGram: &old.BuybackPricePerGram,
+		Source:                 old.Source,
+		ChangedBy:              changedBy,
+	}
+	if err := recordPriceChange(tx, &change); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func recordPriceChange(tx *sqlx.Tx, change *models.GoldPriceChange) error {
+	change.ID = uuid.New()
+	change.ChangedAt = time.Now()
+	_, err := tx.Exec(`
+		INSERT INTO gold_price_changes (id, price_date, gold_type, action, old_buy_price_per_gram, old_buyback_price_per_gram,
+			new_buy_price_per_gram, new_buyback_price_per_gram, source, changed_by, changed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`, change.ID, change.PriceDate, change.GoldType, change.Action, change.OldBuyPricePerGram, change.OldBuybackPricePerGram,
+		change.NewBuyPricePerGram, change.NewBuybackPricePerGram, change.Source, change.ChangedBy, change.ChangedAt)
+	return err
+}
+
+// GetPriceChanges returns the audit trail, newest first, optionally limited
+// to one brand and/or one price date
+func (r *GoldRepository) GetPriceChanges(goldType models.GoldType, priceDate *time.Time, limit int) ([]models.GoldPriceChange, error) {
+	changes := []models.GoldPriceChange{}
+	query := `SELECT ` + goldPriceChangeColumns + ` FROM gold_price_changes c
+		LEFT JOIN users u ON u.id = c.changed_by
+		WHERE ($1 = '' OR c.gold_type::text = $1) AND ($2::date IS NULL OR c.price_date = $2)
+		ORDER BY c.changed_at DESC LIMIT $3`
+	if err := r.db.Select(&changes, query, string(goldType), priceDate, limit); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/financial-tracker/backend/internal/models"
+)
+
+// GetPriceOHLC aggregates the quotes of goldType between from and to into
+// weekly or monthly open-high-low-close bars
+func (r *GoldRepository) GetPriceOHLC(goldType models.GoldType, kind models.GoldPriceKind, interval models.GoldPriceInterval, from, to time.Time) ([]models.GoldOHLC, error) {
+	unit := "month"
+	if interval == models.GoldPriceWeekly {
+		unit = "week"
+	}
+
+	bars := []models.GoldOHLC{}
+	query := `
+		SELECT date_trunc($1, price_date)::date AS period_start,
+			MIN(price_date) AS first_date, MAX(price_date) AS last_date,
+			(array_agg(price ORDER BY price_date))[1] AS open,
+			MAX(price) AS high, MIN(price) AS low,
+			(array_agg(price ORDER BY price_date DESC))[1] AS close,
+			COUNT(*) AS quotes
+		FROM (
+			SELECT price_date, ` + kind.Column() + ` AS price FROM gold_prices
+			WHERE gold_type = $2 AND price_date BETWEEN $3 AND $4
+		) p
+		GROUP BY 1 ORDER BY 1`
+	if err := r.db.Select(&bars, query, unit, goldType, from, to); err != nil {
+		return nil, err
+	}
+	return bars, nil
+}
+
+// GetPriceMovingAverages returns every quote of goldType between from and to
+// with its 7, 30 and 90-day moving averages. Quotes before from are read so
+// the first days of the range have full windows.
+func (r *GoldRepository) GetPriceMovingAverages(goldType models.GoldType, kind models.GoldPriceKind, from, to time.Time) ([]models.GoldMovingAverage, error) {
+	points := []models.GoldMovingAverage{}
+	query := `
+		SELECT price_date, price,
+			CASE WHEN first_date <= price_date - 6 THEN ROUND(AVG(price) OVER (ORDER BY price_date RANGE BETWEEN INTERVAL '6 days' PRECEDING AND CURRENT ROW), 2) END AS ma_7,
+			CASE WHEN first_date <= price_date - 29 THEN ROUND(AVG(price) OVER (ORDER BY price_date RANGE BETWEEN INTERVAL '29 days' PRECEDING AND CURRENT ROW), 2) END AS ma_30,
+			CASE WHEN first_date <= price_date - 89 THEN ROUND(AVG(price) OVER (ORDER BY price_date RANGE BETWEEN INTERVAL '89 days' PRECEDING AND CURRENT ROW), 2) END AS ma_90
+		FROM (
+			SELECT price_date, ` + kind.Column() + ` AS price,
+				(SELECT MIN(price_date) FROM gold_prices WHERE gold_type = $1) AS first_date
+			FROM gold_prices
+			WHERE gold_type = $1 AND price_date BETWEEN $2::date - 89 AND $3
+		) p
+		ORDER BY price_date`
+	if err := r.db.Select(&points, query, goldType, from, to); err != nil {
+		return nil, err
+	}
+
+	// Drop the warm-up quotes before from
+	for i, p := range points {
+		if !p.PriceDate.Before(from) {
+			return points[i:], nil
+		}
+	}
+	return []models.GoldMovingAverage{}, nil
+}
+
+// GetPriceStats returns the latest quote of goldType on or before asOf with
+// its changes over 1 day (the previous quote), 7, 30 and 90 days, 1 year
+// and year to date, and its 52-week high and low. since adds a custom range
+// starting at that day. It returns sql.ErrNoRows when there is no quote.
+func (r *GoldRepository) GetPriceStats(goldType models.GoldType, kind models.GoldPriceKind, asOf time.Time, since *time.Time) (*models.GoldPriceStats, error) {
+	prices := `prices AS (
+			SELECT price_date, ` + kind.Column() + ` AS price FROM gold_prices
+			WHERE gold_type = $1 AND price_date <= $2
+		),
+		latest AS (SELECT price_date, price FROM prices ORDER BY price_date DESC LIMIT 1)`
+
+	stats := &models.GoldPriceStats{GoldType: goldType, PriceKind: kind, Changes: []models.GoldPriceRangeChange{}}
+	err := r.db.Get(stats, `
+		WITH `+prices+`,
+		last_year AS (SELECT p.* FROM prices p, latest l WHERE p.price_date > l.price_date - 364)
+		SELECT l.price_date, l.price,
+			(SELECT price FROM last_year ORDER BY price DESC, price_date DESC LIMIT 1) AS high_52_week,
+			(SELECT price_date FROM last_year ORDER BY price DESC, price_date DESC LIMIT 1) AS high_52_week_date,
+			(SELECT price FROM last_year ORDER BY price, price_date DESC LIMIT 1) AS low_52_week,
+			(SELECT price_date FROM last_year ORDER BY price, price_date DESC LIMIT 1) AS low_52_week_date
+		FROM latest l`, goldType, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.db.Select(&stats.Changes, `
+		WITH `+prices+`,
+		ranges AS (
+			SELECT r.label, r.ord, CASE r.label
+				WHEN '1d' THEN l.price_date - 1
+				WHEN '7d' THEN l.price_date - 7
+				WHEN '30d' THEN l.price_date - 30
+				WHEN '90d' THEN l.price_date - 90
+				WHEN '1y' THEN (l.price_date - INTERVAL '1 year')::date
+				WHEN 'ytd' THEN make_date(EXTRACT(YEAR FROM l.price_date)::int - 1, 12, 31)
+				ELSE $3::date
+			END AS since
+			FROM latest l
+			CROSS JOIN (VALUES ('1d', 1), ('7d', 2), ('30d', 3), ('90d', 4), ('1y', 5), ('ytd', 6), ('custom', 7)) AS r(label, ord)
+			WHERE r.label <> 'custom' OR $3::date IS NOT NULL
+		)
+		SELECT r.label AS range, ref.price_date AS from_date, ref.price AS from_price,
+			l.price - ref.price AS change,
+			ROUND((l.price - ref.price) / NULLIF(ref.price, 0) * 100, 2) AS change_percent
+		FROM ranges r
+		CROSS JOIN latest l
+		LEFT JOIN LATERAL (
+			SELECT price_date, price FROM prices WHERE price_date <= r.since ORDER BY price_date DESC LIMIT 1
+		) ref ON true
+		ORDER BY r.ord`, goldType, asOf, since)
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
@@ -11,12 +11,27 @@ import (
 
 type GoldRepository struct {
 	db *sqlx.DB
+	// Called after a quote has been created or changed
+	priceHooks []func(models.GoldPrice)
 }
 
 func NewGoldRepository(db *sqlx.DB) *GoldRepository {
 	return &GoldRepository{db: db}
 }
 
+// OnPriceSet registers fn to be called with every quote that is created or
+// changed. For an import it is called with the newest written quote of each
+// brand only.
+func (r *GoldRepository) OnPriceSet(fn func(models.GoldPrice)) {
+	r.priceHooks = append(r.priceHooks, fn)
+}
+
+func (r *GoldRepository) priceSet(price models.GoldPrice) {
+	for _, fn := range r.priceHooks {
+		fn(price)
+	}
+}
+
 // Gold Assets
 
 func (r *GoldRepository) CreateAsset(asset *models.GoldAsset) error {
@@ -24,11 +39,13 @@ func (r *GoldRepository) CreateAsset(asset *models.GoldAsset) error {
 	asset.CreatedAt = time.Now()
 	asset.UpdatedAt = time.Now()
 
+	asset.RemainingWeightGram = asset.WeightGram
+
 	query := `
-		INSERT INTO gold_assets (id, user_id, name, gold_type, weight_gram, purchase_price_per_gram, purchase_date, storage_location, notes, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		INSERT INTO gold_assets (id, user_id, name, gold_type, item_kind, purity, resale_haircut_percent, weight_gram, remaining_weight_gram, purchase_price_per_gram, purchase_date, storage_location, notes, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
 	`
-	_, err := r.db.Exec(query, asset.ID, asset.UserID, asset.Name, asset.GoldType, asset.WeightGram, asset.PurchasePricePerGram, asset.PurchaseDate, asset.StorageLocation, asset.Notes, asset.CreatedAt, asset.UpdatedAt)
+	_, err := r.db.Exec(query, asset.ID, asset.UserID, asset.Name, asset.GoldType, asset.ItemKind, asset.Purity, asset.ResaleHaircutPercent, asset.WeightGram, asset.RemainingWeightGram, asset.PurchasePricePerGram, asset.PurchaseDate, asset.StorageLocation, asset.Notes, asset.CreatedAt, asset.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to create gold asset: %w", err)
 	}
@@ -36,19 +53,23 @@ func (r *GoldRepository) CreateAsset(asset *models.GoldAsset) error {
 	return nil
 }
 
-func (r *GoldRepository) GetAssetsByUserID(userID uuid.UUID) ([]models.GoldAsset, error) {
+const goldAssetColumns = `id, user_id, name, gold_type, item_kind, purity, resale_haircut_percent, weight_gram, remaining_weight_gram, purchase_price_per_gram, purchase_date, storage_location, notes, created_at, updated_at`
+
+// GetAssetsByUserID returns the user's lots, newest first. Fully sold lots
+// are left out unless includeSold is set.
+func (r *GoldRepository) GetAssetsByUserID(userID uuid.UUID, includeSold bool) ([]models.GoldAsset, error) {
 	var assets []models.GoldAsset
-	query := `SELECT id, user_id, name, gold_type, weight_gram, purchase_price_per_gram, purchase_date, storage_location, notes, created_at, updated_at 
-		FROM gold_assets WHERE user_id = $1 ORDER BY purchase_date DESC`
-	err := r.db.Select(&assets, query, userID)
+	query := `SELECT ` + goldAssetColumns + `
+		FROM gold_assets WHERE user_id = $1 AND ($2 OR remaining_weight_gram > 0) ORDER BY purchase_date DESC`
+	err := r.db.Select(&assets, query, userID, includeSold)
 	if err != nil {
 		return nil, err
 	}
 
-	// Get current price and calculate values
-	currentPrice, _ := r.GetLatestPrice()
+	// Get current prices and calculate values
+	quotes, _ := r.GetLatestQuotes()
 	for i := range assets {
-		r.calculateAssetValues(&assets[i], currentPrice)
+		r.calculateAssetValues(&assets[i], quotes)
 	}
 
 	return assets, nil
@@ -56,38 +77,99 @@ func (r *GoldRepository) GetAssetsByUserID(userID uuid.UUID) ([]models.GoldAsset
 
 func (r *GoldRepository) GetAssetByID(id uuid.UUID) (*models.GoldAsset, error) {
 	var asset models.GoldAsset
-	query := `SELECT id, user_id, name, gold_type, weight_gram, purchase_price_per_gram, purchase_date, storage_location, notes, created_at, updated_at FROM gold_assets WHERE id = $1`
+	query := `SELECT ` + goldAssetColumns + ` FROM gold_assets WHERE id = $1`
 	err := r.db.Get(&asset, query, id)
 	if err != nil {
 		return nil, err
 	}
 
 	// Calculate values
-	currentPrice, _ := r.GetLatestPrice()
-	r.calculateAssetValues(&asset, currentPrice)
+	quotes, _ := r.GetLatestQuotes()
+	r.calculateAssetValues(&asset, quotes)
 
 	return &asset, nil
 }
 
+// UpdateAsset saves the lot's details. A weight change moves the remaining
+// weight by the same amount, so gold already sold stays sold. The lot is
+// locked while checking that its new weight still covers what was sold and
+// its unsold pieces, and that it was not bought after its first sale.
 func (r *GoldRepository) UpdateAsset(asset *models.GoldAsset) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var sold float64
+	if err := tx.Get(&sold, `SELECT weight_gram - remaining_weight_gram FROM gold_assets WHERE id = $1 FOR UPDATE`, asset.ID); err != nil {
+		return err
+	}
+	if sold = models.RoundGram(sold); models.RoundGram(asset.WeightGram) < sold {
+		return fmt.Errorf("%w: weight cannot be less than the %.4fg already sold", ErrInvalidGoldAsset, sold)
+	}
+
+	var firstSale *time.Time
+	err = tx.Get(&firstSale, `SELECT MIN(s.sale_date) FROM gold_sale_lots l JOIN gold_sales s ON s.id = l.sale_id
+		WHERE l.asset_id = $1`, asset.ID)
+	if err != nil {
+		return err
+	}
+	if firstSale != nil && asset.PurchaseDate.After(*firstSale) {
+		return fmt.Errorf("%w: purchase date cannot be after the first sale on %s", ErrInvalidGoldAsset, firstSale.Format("2006-01-02"))
+	}
+
 	asset.UpdatedAt = time.Now()
-	query := `UPDATE gold_assets SET name = $1, gold_type = $2, weight_gram = $3, purchase_price_per_gram = $4, storage_location = $5, notes = $6, updated_at = $7 WHERE id = $8`
-	_, err := r.db.Exec(query, asset.Name, asset.GoldType, asset.WeightGram, asset.PurchasePricePerGram, asset.StorageLocation, asset.Notes, asset.UpdatedAt, asset.ID)
-	return err
+	query := `UPDATE gold_assets SET name = $1, gold_type = $2, item_kind = $3, purity = $4, resale_haircut_percent = $5,
+		remaining_weight_gram = remaining_weight_gram + ($6 - weight_gram), weight_gram = $6,
+		purchase_price_per_gram = $7, purchase_date = $8, storage_location = $9, notes = $10, updated_at = $11 WHERE id = $12
+		RETURNING remaining_weight_gram`
+	err = tx.Get(&asset.RemainingWeightGram, query, asset.Name, asset.GoldType, asset.ItemKind, asset.Purity, asset.ResaleHaircutPercent,
+		asset.WeightGram, asset.PurchasePricePerGram, asset.PurchaseDate, asset.StorageLocation, asset.Notes, asset.UpdatedAt, asset.ID)
+	if err != nil {
+		return err
+	}
+	if err := checkPiecesFit(tx, asset.ID, nil, 0); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
+// DeleteAsset deletes a lot with its unsold pieces. Its sold pieces and sale
+// lots are kept for the sales' history.
 func (r *GoldRepository) DeleteAsset(id uuid.UUID) error {
-	query := `DELETE FROM gold_assets WHERE id = $1`
-	_, err := r.db.Exec(query, id)
-	return err
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM gold_pieces WHERE asset_id = $1 AND sale_lot_id IS NULL`, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM gold_assets WHERE id = $1`, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
-func (r *GoldRepository) calculateAssetValues(asset *models.GoldAsset, currentPrice *models.GoldPrice) {
-	asset.PurchaseValue = asset.WeightGram * asset.PurchasePricePerGram
-	
-	if currentPrice != nil {
-		asset.CurrentPricePerGram = currentPrice.PricePerGram
-		asset.CurrentValue = asset.WeightGram * currentPrice.PricePerGram
+// calculateAssetValues values the weight still held at the brand's buyback
+// price scaled by the lot's value factor (purity and resale haircut), see
+// GoldQuotes.For for the fallbacks
+func (r *GoldRepository) calculateAssetValues(asset *models.GoldAsset, quotes models.GoldQuotes) {
+	asset.Karat = models.KaratOf(asset.Purity)
+	asset.FineWeightGram = models.RoundGram(asset.RemainingWeightGram * asset.Purity)
+	asset.PurchaseValue = asset.RemainingWeightGram * asset.PurchasePricePerGram
+
+	if price := quotes.For(asset.GoldType); price != nil {
+		asset.QuotePricePerGram = price.BuybackPricePerGram
+		asset.CurrentPricePerGram = price.BuybackPricePerGram * asset.ValueFactor()
+		asset.PriceGoldType = price.GoldType
+		priceDate := price.PriceDate
+		asset.PriceDate = &priceDate
+		asset.CurrentValue = asset.RemainingWeightGram * asset.CurrentPricePerGram
 		asset.ProfitLoss = asset.CurrentValue - asset.PurchaseValue
 		if asset.PurchaseValue > 0 {
 			asset.ProfitLossPercent = (asset.ProfitLoss / asset.PurchaseValue) * 100
@@ -97,47 +179,74 @@ func (r *GoldRepository) calculateAssetValues(asset *models.GoldAsset, currentPr
 
 // Gold Prices
 
-func (r *GoldRepository) GetLatestPrice() (*models.GoldPrice, error) {
+const goldPriceColumns = `id, price_date, gold_type, buy_price_per_gram, buyback_price_per_gram, source, created_at, updated_at`
+
+// GetLatestPrices returns the most recent quote of each brand
+func (r *GoldRepository) GetLatestPrices() ([]models.GoldPrice, error) {
+	prices := []models.GoldPrice{}
+	query := `SELECT DISTINCT ON (gold_type) ` + goldPriceColumns + ` FROM gold_prices ORDER BY gold_type, price_date DESC`
+	err := r.db.Select(&prices, query)
+	if err != nil {
+		return nil, err
+	}
+	return prices, nil
+}
+
+// GetLatestQuotes returns GetLatestPrices keyed by brand
+func (r *GoldRepository) GetLatestQuotes() (models.GoldQuotes, error) {
+	prices, err := r.GetLatestPrices()
+	if err != nil {
+		return nil, err
+	}
+	return models.NewGoldQuotes(prices), nil
+}
+
+// GetLatestPrice returns the most recent quote of one brand
+func (r *GoldRepository) GetLatestPrice(goldType models.GoldType) (*models.GoldPrice, error) {
 	var price models.GoldPrice
-	query := `SELECT id, price_date, price_per_gram, source, created_at, updated_at FROM gold_prices ORDER BY price_date DESC LIMIT 1`
-	err := r.db.Get(&price, query)
+	query := `SELECT ` + goldPriceColumns + ` FROM gold_prices WHERE gold_type = $1 ORDER BY price_date DESC LIMIT 1`
+	err := r.db.Get(&price, query, goldType)
 	if err != nil {
 		return nil, err
 	}
 	return &price, nil
 }
 
-func (r *GoldRepository) GetPriceHistory(limit int) ([]models.GoldPrice, error) {
-	var prices []models.GoldPrice
-	query := `SELECT id, price_date, price_per_gram, source, created_at, updated_at FROM gold_prices ORDER BY price_date DESC LIMIT $1`
-	err := r.db.Select(&prices, query, limit)
+// GetPriceHistory returns the latest quotes, newest first, for one brand or
+// for all when goldType is empty
+func (r *GoldRepository) GetPriceHistory(goldType models.GoldType, limit int) ([]models.GoldPrice, error) {
+	prices := []models.GoldPrice{}
+	query := `SELECT ` + goldPriceColumns + ` FROM gold_prices
+		WHERE ($1 = '' OR gold_type::text = $1) ORDER BY price_date DESC, gold_type LIMIT $2`
+	err := r.db.Select(&prices, query, string(goldType), limit)
 	if err != nil {
 		return nil, err
 	}
 	return prices, nil
 }
 
-func (r *GoldRepository) UpsertPrice(priceDate time.Time, pricePerGram float64, source string) error {
-	query := `
-		INSERT INTO gold_prices (id, price_date, price_per_gram, source, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
-		ON CONFLICT (price_date) 
-		DO UPDATE SET price_per_gram = $3, source = $4, updated_at = $6
-	`
-	now := time.Now()
-	_, err := r.db.Exec(query, uuid.New(), priceDate, pricePerGram, source, now, now)
+// UpsertPrice stores a quote fetched from a provider; the change is audited
+// without a user
+func (r *GoldRepository) UpsertPrice(priceDate time.Time, goldType models.GoldType, buyPricePerGram, buybackPricePerGram float64, source string) error {
+	_, err := r.SetPrice(models.GoldPrice{
+		PriceDate:           priceDate,
+		GoldType:            goldType,
+		BuyPricePerGram:     buyPricePerGram,
+		BuybackPricePerGram: buybackPricePerGram,
+		Source:              source,
+	}, nil)
 	return err
 }
 
-// GetSummary returns total weight and value of all gold assets
+// GetSummary returns total weight and value of the gold still held
 func (r *GoldRepository) GetSummary(userID uuid.UUID) (totalWeight, totalPurchaseValue, totalCurrentValue, totalProfitLoss float64, err error) {
-	assets, err := r.GetAssetsByUserID(userID)
+	assets, err := r.GetAssetsByUserID(userID, false)
 	if err != nil {
 		return
 	}
 
 	for _, a := range assets {
-		totalWeight += a.WeightGram
+		totalWeight += a.RemainingWeightGram
 		totalPurchaseValue += a.PurchaseValue
 		totalCurrentValue += a.CurrentValue
 		totalProfitLoss += a.ProfitLoss
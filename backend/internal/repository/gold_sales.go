@@ -0,0 +1,211 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/financial-tracker/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const goldSaleColumns = `id, user_id, account_id, transaction_id, sale_date, weight_gram, price_per_gram, fee, proceeds, cost_basis, realized_gain, COALESCE(notes, '') AS notes, created_at`
+
+// CreateSale sells gold from the user's lots. With method fifo the weight is
+// taken from the loose weight of the oldest lots held on the sale date (of
+// goldType, if given); with specific it is taken from selections, by weight
+// or as whole pieces. The lots are locked and reduced, the sale, its lots
+// and sold pieces recorded, and the proceeds booked as income into
+// accountID, all in one database transaction. A zero PricePerGram is filled
+// in by price from the lots actually sold.
+func (r *GoldRepository) CreateSale(sale *models.GoldSale, method models.GoldSaleMethod, goldType models.GoldType, selections []models.GoldLotSelection, price func([]models.GoldAsset) (float64, error)) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var lots []models.GoldAsset
+	if method == models.GoldSaleFIFO {
+		err = tx.Select(&lots, `SELECT `+goldAssetColumns+` FROM gold_assets
+			WHERE user_id = $1 AND remaining_weight_gram > 0 AND ($2 = '' OR gold_type::text = $2) AND purchase_date <= $3
+			ORDER BY purchase_date, created_at FOR UPDATE`, sale.UserID, string(goldType), sale.SaleDate)
+	} else {
+		ids := make(pq.StringArray, len(selections))
+		for i, sel := range selections {
+			ids[i] = sel.AssetID.String()
+		}
+		err = tx.Select(&lots, `SELECT `+goldAssetColumns+` FROM gold_assets
+			WHERE user_id = $1 AND id = ANY($2::uuid[]) FOR UPDATE`, sale.UserID, ids)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Whole pieces are only sold when named, so FIFO takes loose weight
+	pieces, err := lockUnsoldPieces(tx, lots)
+	if err != nil {
+		return err
+	}
+	if method == models.GoldSaleFIFO {
+		selections, err = models.AllocateFIFO(models.LooseLots(lots, pieces), sale.WeightGram)
+		if err != nil {
+			if len(pieces) > 0 {
+				err = fmt.Errorf("%v; gold in pieces is sold by piece_ids", err)
+			}
+			return fmt.Errorf("%w: %v", ErrInvalidGoldSale, err)
+		}
+	}
+
+	byID := make(map[uuid.UUID]models.GoldAsset, len(lots))
+	for _, lot := range lots {
+		byID[lot.ID] = lot
+	}
+	if err := models.AssignPieces(byID, selections, pieces); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidGoldSale, err)
+	}
+	sold := []models.GoldAsset{}
+	for _, sel := range selections {
+		if lot, ok := byID[sel.AssetID]; ok {
+			sold = append(sold, lot)
+		}
+	}
+
+	if sale.PricePerGram == 0 {
+		if sale.PricePerGram, err = price(sold); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidGoldSale, err)
+		}
+	}
+
+	sale.ID = uuid.New()
+	sale.CreatedAt = time.Now()
+	if err := sale.Allocate(byID, selections); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidGoldSale, err)
+	}
+
+	for _, lot := range sale.Lots {
+		_, err = tx.Exec(`UPDATE gold_assets SET remaining_weight_gram = remaining_weight_gram - $1, updated_at = $2 WHERE id = $3`,
+			lot.WeightGram, sale.CreatedAt, lot.AssetID)
+		if err != nil {
+			return fmt.Errorf("failed to update gold lot: %w", err)
+		}
+	}
+
+	if sale.AccountID != nil {
+		income := &models.Transaction{
+			ID:              uuid.New(),
+			UserID:          sale.UserID,
+			AccountID:       *sale.AccountID,
+			Type:            models.TransactionTypeIncome,
+			Category:        "Gold Sale",
+			Amount:          sale.Proceeds,
+			Description:     fmt.Sprintf("Sold %.4fg gold at %.2f/g", sale.WeightGram, sale.PricePerGram),
+			TransactionDate: sale.SaleDate,
+			CreatedAt:       sale.CreatedAt,
+			UpdatedAt:       sale.CreatedAt,
+		}
+		if err := insertTransaction(tx, income); err != nil {
+			return err
+		}
+		_, err = tx.Exec(`UPDATE accounts SET balance = balance + $1, updated_at = $2 WHERE id = $3`, sale.Proceeds, sale.CreatedAt, *sale.AccountID)
+		if err != nil {
+			return fmt.Errorf("failed to update account balance: %w", err)
+		}
+		sale.TransactionID = &income.ID
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO gold_sales (id, user_id, account_id, transaction_id, sale_date, weight_gram, price_per_gram, fee, proceeds, cost_basis, realized_gain, notes, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`, sale.ID, sale.UserID, sale.AccountID, sale.TransactionID, sale.SaleDate, sale.WeightGram, sale.PricePerGram, sale.Fee, sale.Proceeds, sale.CostBasis, sale.RealizedGain, sale.Notes, sale.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create gold sale: %w", err)
+	}
+
+	piecesByID := make(map[uuid.UUID]models.GoldPiece, len(pieces))
+	for _, p := range pieces {
+		piecesByID[p.ID] = p
+	}
+	for i, lot := range sale.Lots {
+		_, err = tx.Exec(`
+			INSERT INTO gold_sale_lots (id, sale_id, asset_id, gold_type, weight_gram, cost_per_gram, proceeds, realized_gain)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		`, lot.ID, lot.SaleID, lot.AssetID, lot.GoldType, lot.WeightGram, lot.CostPerGram, lot.Proceeds, lot.RealizedGain)
+		if err != nil {
+			return fmt.Errorf("failed to create gold sale lot: %w", err)
+		}
+
+		// Allocate keeps the order of selections
+		for _, id := range selections[i].PieceIDs {
+			piece := piecesByID[id]
+			piece.SaleLotID = &sale.Lots[i].ID
+			piece.UpdatedAt = sale.CreatedAt
+			_, err = tx.Exec(`UPDATE gold_pieces SET sale_lot_id = $1, updated_at = $2 WHERE id = $3`, piece.SaleLotID, piece.UpdatedAt, piece.ID)
+			if err != nil {
+				return fmt.Errorf("failed to mark gold piece sold: %w", err)
+			}
+			sale.Lots[i].Pieces = append(sale.Lots[i].Pieces, piece)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetSales returns the user's sales with their lots, newest first
+func (r *GoldRepository) GetSales(userID uuid.UUID) ([]models.GoldSale, error) {
+	sales := []models.GoldSale{}
+	query := `SELECT ` + goldSaleColumns + ` FROM gold_sales WHERE user_id = $1 ORDER BY sale_date DESC, created_at DESC`
+	if err := r.db.Select(&sales, query, userID); err != nil {
+		return nil, err
+	}
+
+	var lots []models.GoldSaleLot
+	err := r.db.Select(&lots, `
+		SELECT l.id, l.sale_id, l.asset_id, l.gold_type, l.weight_gram, l.cost_per_gram, l.proceeds, l.realized_gain
+		FROM gold_sale_lots l JOIN gold_sales s ON s.id = l.sale_id
+		WHERE s.user_id = $1
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var pieces []models.GoldPiece
+	err = r.db.Select(&pieces, `SELECT `+goldPieceColumns+` FROM gold_pieces
+		WHERE user_id = $1 AND sale_lot_id IS NOT NULL ORDER BY created_at, certificate_number`, userID)
+	if err != nil {
+		return nil, err
+	}
+	byLot := make(map[uuid.UUID][]models.GoldPiece)
+	for _, p := range pieces {
+		byLot[*p.SaleLotID] = append(byLot[*p.SaleLotID], p)
+	}
+
+	bySale := make(map[uuid.UUID][]models.GoldSaleLot)
+	for _, lot := range lots {
+		lot.Pieces = byLot[lot.ID]
+		bySale[lot.SaleID] = append(bySale[lot.SaleID], lot)
+	}
+	for i := range sales {
+		sales[i].Lots = bySale[sales[i].ID]
+		if sales[i].Lots == nil {
+			sales[i].Lots = []models.GoldSaleLot{}
+		}
+	}
+
+	return sales, nil
+}
+
+// GetRealizedSummary totals the user's sales
+func (r *GoldRepository) GetRealizedSummary(userID uuid.UUID) (*models.RealizedGoldSummary, error) {
+	var summary models.RealizedGoldSummary
+	err := r.db.Get(&summary, `
+		SELECT COUNT(*) AS sales_count, COALESCE(SUM(weight_gram), 0) AS weight_sold,
+			COALESCE(SUM(proceeds), 0) AS total_proceeds, COALESCE(SUM(cost_basis), 0) AS total_cost,
+			COALESCE(SUM(realized_gain), 0) AS realized_gain
+		FROM gold_sales WHERE user_id = $1
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &summary, nil
+}
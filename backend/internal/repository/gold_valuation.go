@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/financial-tracker/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// GetLedger loads the user's lots (sold ones included) and the sales up to
+// and including until, with the prices needed to value them from from on:
+// each brand's last quote on or before from and every quote after it
+func (r *GoldRepository) GetLedger(userID uuid.UUID, from, until time.Time) (*models.GoldLedger, error) {
+	ledger := &models.GoldLedger{}
+
+	query := `SELECT ` + goldAssetColumns + ` FROM gold_assets WHERE user_id = $1 AND purchase_date <= $2`
+	if err := r.db.Select(&ledger.Assets, query, userID, until); err != nil {
+		return nil, err
+	}
+
+	err := r.db.Select(&ledger.LotSales, `
+		SELECT l.asset_id, s.sale_date, l.weight_gram
+		FROM gold_sale_lots l JOIN gold_sales s ON s.id = l.sale_id
+		WHERE s.user_id = $1 AND l.asset_id IS NOT NULL AND s.sale_date <= $2
+	`, userID, until)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.db.Select(&ledger.Realizations, `SELECT sale_date, realized_gain FROM gold_sales WHERE user_id = $1 AND sale_date <= $2`, userID, until)
+	if err != nil {
+		return nil, err
+	}
+
+	query = `
+		SELECT * FROM (
+			SELECT DISTINCT ON (gold_type) ` + goldPriceColumns + ` FROM gold_prices
+			WHERE price_date <= $1 ORDER BY gold_type, price_date DESC
+		) AS opening
+		UNION ALL
+		SELECT ` + goldPriceColumns + ` FROM gold_prices WHERE price_date > $1 AND price_date <= $2
+		ORDER BY price_date, gold_type`
+	if err := r.db.Select(&ledger.Prices, query, from, until); err != nil {
+		return nil, err
+	}
+
+	return ledger, nil
+}
+
+// GetValuation values the user's gold as held at the end of day
+func (r *GoldRepository) GetValuation(userID uuid.UUID, day time.Time) (*models.GoldValuation, error) {
+	ledger, err := r.GetLedger(userID, day, day)
+	if err != nil {
+		return nil, err
+	}
+	valuation := ledger.ValueAt(day, ledger.QuotesAt(day))
+	return &valuation, nil
+}
+
+// GetValuationSeries values the user's gold on every day (daily) or month end
+// (monthly) from from to to
+func (r *GoldRepository) GetValuationSeries(userID uuid.UUID, from, to time.Time, interval models.ValuationInterval) ([]models.GoldValuationPoint, error) {
+	ledger, err := r.GetLedger(userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return ledger.Series(from, to, interval), nil
+}
+
+// GetFirstPurchaseDate returns the purchase date of the user's oldest lot
+func (r *GoldRepository) GetFirstPurchaseDate(userID uuid.UUID) (*time.Time, error) {
+	var first *time.Time
+	err := r.db.Get(&first, `SELECT MIN(purchase_date) FROM gold_assets WHERE user_id = $1`, userID)
+	return first, err
+}
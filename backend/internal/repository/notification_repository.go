@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/financial-tracker/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type NotificationRepository struct {
+	db *sqlx.DB
+}
+
+func NewNotificationRepository(db *sqlx.DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+// Create stores a notification. Notifications sharing a dedupe key with an
+// existing one are skipped; the returned bool reports whether a row was written.
+func (r *NotificationRepository) Create(n *models.Notification) (bool, error) {
+	n.ID = uuid.New()
+	n.CreatedAt = time.Now()
+	if n.Data == nil {
+		n.Data = map[string]interface{}{}
+	}
+
+	dataJSON, err := json.Marshal(n.Data)
+	if err != nil {
+		return false, err
+	}
+
+	query := `
+		INSERT INTO notifications (id, user_id, type, title, message, data, dedupe_key, is_read, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, false, $8)
+		ON CONFLICT (user_id, dedupe_key) WHERE dedupe_key IS NOT NULL DO NOTHING
+	`
+	result, err := r.db.Exec(query, n.ID, n.UserID, n.Type, n.Title, n.Message, dataJSON, n.DedupeKey, n.CreatedAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to create notification: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (r *NotificationRepository) GetByUserID(userID uuid.UUID, unreadOnly bool, limit int) ([]models.Notification, error) {
+	query := `SELECT id, user_id, type, title, message, data, dedupe_key, is_read, created_at FROM notifications WHERE user_id = $1`
+	if unreadOnly {
+		query += ` AND is_read = false`
+	}
+	query += ` ORDER BY created_at DESC LIMIT $2`
+
+	rows, err := r.db.Query(query, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notifications := []models.Notification{}
+	for rows.Next() {
+		var n models.Notification
+		var dataJSON []byte
+		err := rows.Scan(&n.ID, &n.UserID, &n.Type, &n.Title, &n.Message, &dataJSON, &n.DedupeKey, &n.IsRead, &n.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(dataJSON, &n.Data); err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, n)
+	}
+	return notifications, rows.Err()
+}
+
+func (r *NotificationRepository) CountUnread(userID uuid.UUID) (int, error) {
+	var count int
+	err := r.db.Get(&count, `SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND is_read = false`, userID)
+	return count, err
+}
+
+// MarkRead marks a single notification as read; it returns false if the
+// notification does not exist or belongs to another user
+func (r *NotificationRepository) MarkRead(id, userID uuid.UUID) (bool, error) {
+	result, err := r.db.Exec(`UPDATE notifications SET is_read = true WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (r *NotificationRepository) MarkAllRead(userID uuid.UUID) error {
+	_, err := r.db.Exec(`UPDATE notifications SET is_read = true WHERE user_id = $1 AND is_read = false`, userID)
+	return err
+}
@@ -0,0 +1,302 @@
+package repository
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/financial-tracker/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type PaylaterRepository struct {
+	db *sqlx.DB
+}
+
+func NewPaylaterRepository(db *sqlx.DB) *PaylaterRepository {
+	return &PaylaterRepository{db: db}
+}
+
+const paylaterInstallmentColumns = `i.id, i.purchase_id, i.installment_number, i.due_date, i.principal, i.fee, i.amount, i.paid_at, i.payment_transaction_id`
+
+// CreatePurchase books a purchase on a paylater account. The principal is
+// an expense in the purchase's category and the fees over the whole tenor an
+// expense under "Paylater Fees"; the account balance goes down by both, and
+// the installment schedule is stored, all in one database transaction.
+func (r *PaylaterRepository) CreatePurchase(p *models.PaylaterPurchase, dueDay int) error {
+	p.ID = uuid.New()
+	p.CreatedAt = time.Now()
+	p.Installments = p.Schedule(dueDay)
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	p.TotalFees = 0
+	for _, inst := range p.Installments {
+		p.TotalFees += inst.Fee
+	}
+	p.Outstanding = p.Principal + p.TotalFees
+
+	expenses := []models.Transaction{{
+		Category:    p.Category,
+		Amount:      p.Principal,
+		Description: p.Description,
+	}}
+	if p.TotalFees > 0 {
+		expenses = append(expenses, models.Transaction{
+			Category:    "Paylater Fees",
+			Amount:      p.TotalFees,
+			Description: fmt.Sprintf("%s (fees, %d months)", p.Description, p.TenorMonths),
+		})
+	}
+	for i := range expenses {
+		expense := &expenses[i]
+		expense.ID = uuid.New()
+		expense.UserID = p.UserID
+		expense.AccountID = p.AccountID
+		expense.Type = models.TransactionTypeExpense
+		expense.TransactionDate = p.PurchaseDate
+		expense.CreatedAt = p.CreatedAt
+		expense.UpdatedAt = p.CreatedAt
+		if err := insertTransaction(tx, expense); err != nil {
+			return err
+		}
+	}
+	p.TransactionID = &expenses[0].ID
+
+	_, err = tx.Exec(`UPDATE accounts SET balance = balance - $1, updated_at = $2 WHERE id = $3`, p.Outstanding, p.CreatedAt, p.AccountID)
+	if err != nil {
+		return fmt.Errorf("failed to update account balance: %w", err)
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO paylater_purchases (id, user_id, account_id, transaction_id, description, category, principal, tenor_months, fee_percent_monthly, admin_fee, purchase_date, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`, p.ID, p.UserID, p.AccountID, p.TransactionID, p.Description, p.Category, p.Principal, p.TenorMonths, p.FeePercentMonthly, p.AdminFee, p.PurchaseDate, p.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create paylater purchase: %w", err)
+	}
+
+	for _, inst := range p.Installments {
+		_, err = tx.Exec(`
+			INSERT INTO paylater_installments (id, purchase_id, installment_number, due_date, principal, fee, amount)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+		`, inst.ID, inst.PurchaseID, inst.InstallmentNumber, inst.DueDate, inst.Principal, inst.Fee, inst.Amount)
+		if err != nil {
+			return fmt.Errorf("failed to create paylater installment: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetPurchases returns an account's purchases with their schedules, newest first
+func (r *PaylaterRepository) GetPurchases(accountID uuid.UUID) ([]models.PaylaterPurchase, error) {
+	purchases := []models.PaylaterPurchase{}
+	err := r.db.Select(&purchases, `
+		SELECT id, user_id, account_id, transaction_id, description, category, principal, tenor_months, fee_percent_monthly, admin_fee, purchase_date, created_at
+		FROM paylater_purchases WHERE account_id = $1 ORDER BY purchase_date DESC, created_at DESC
+	`, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	var installments []models.PaylaterInstallment
+	err = r.db.Select(&installments, `SELECT `+paylaterInstallmentColumns+`
+		FROM paylater_installments i JOIN paylater_purchases p ON p.id = i.purchase_id
+		WHERE p.account_id = $1 ORDER BY i.installment_number`, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	byPurchase := make(map[uuid.UUID][]models.PaylaterInstallment)
+	for _, inst := range installments {
+		byPurchase[inst.PurchaseID] = append(byPurchase[inst.PurchaseID], inst)
+	}
+	for i := range purchases {
+		p := &purchases[i]
+		p.Installments = byPurchase[p.ID]
+		for _, inst := range p.Installments {
+			p.TotalFees += inst.Fee
+			if inst.PaidAt == nil {
+				p.Outstanding += inst.Amount
+			}
+		}
+	}
+
+	return purchases, nil
+}
+
+// GetUpcoming returns the user's unpaid installments due on or before until,
+// overdue ones included, earliest first
+func (r *PaylaterRepository) GetUpcoming(userID uuid.UUID, until, now time.Time) ([]models.UpcomingDue, error) {
+	upcoming := []models.UpcomingDue{}
+	err := r.db.Select(&upcoming, `SELECT `+paylaterInstallmentColumns+`, p.user_id, p.account_id, a.name AS account_name, p.description, p.tenor_months
+		FROM paylater_installments i
+		JOIN paylater_purchases p ON p.id = i.purchase_id
+		JOIN accounts a ON a.id = p.account_id
+		WHERE p.user_id = $1 AND i.paid_at IS NULL AND i.due_date <= $2
+		ORDER BY i.due_date, a.name, i.installment_number`, userID, until)
+	if err != nil {
+		return nil, err
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	for i := range upcoming {
+		upcoming[i].Overdue = upcoming[i].DueDate.Before(today)
+	}
+	return upcoming, nil
+}
+
+func (r *PaylaterRepository) GetInstallmentByID(id uuid.UUID) (*models.UpcomingDue, error) {
+	var inst models.UpcomingDue
+	err := r.db.Get(&inst, `SELECT `+paylaterInstallmentColumns+`, p.user_id, p.account_id, a.name AS account_name, p.description, p.tenor_months
+		FROM paylater_installments i
+		JOIN paylater_purchases p ON p.id = i.purchase_id
+		JOIN accounts a ON a.id = p.account_id
+		WHERE i.id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	return &inst, nil
+}
+
+// PayInstallment pays an installment from a bank account. The bank account
+// gets a transfer transaction and pays the amount over to the paylater
+// account, and the installment is marked paid, in one database transaction.
+func (r *PaylaterRepository) PayInstallment(inst *models.UpcomingDue, fromAccountID uuid.UUID, paidAt time.Time) (*models.Transaction, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	transfer := &models.Transaction{
+		ID:              uuid.New(),
+		UserID:          inst.UserID,
+		AccountID:       fromAccountID,
+		Type:            models.TransactionTypeTransfer,
+		Category:        "Paylater Payment",
+		Amount:          inst.Amount,
+		Description:     fmt.Sprintf("%s %s (%d/%d)", inst.AccountName, inst.Description, inst.InstallmentNumber, inst.TenorMonths),
+		TransactionDate: paidAt,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if err := insertTransaction(tx, transfer); err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(`UPDATE accounts SET balance = balance - $1, updated_at = $2 WHERE id = $3`, inst.Amount, now, fromAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update account balance: %w", err)
+	}
+	_, err = tx.Exec(`UPDATE accounts SET balance = balance + $1, updated_at = $2 WHERE id = $3`, inst.Amount, now, inst.AccountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update paylater balance: %w", err)
+	}
+
+	result, err := tx.Exec(`UPDATE paylater_installments SET paid_at = $1, payment_transaction_id = $2 WHERE id = $3 AND paid_at IS NULL`, paidAt, transfer.ID, inst.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark installment paid: %w", err)
+	}
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		return nil, fmt.Errorf("installment already paid")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	inst.PaidAt = &paidAt
+	inst.PaymentTransactionID = &transfer.ID
+	return transfer, nil
+}
+
+// GetBalanceSheet totals the user's accounts as assets and their paylater
+// and credit card debt as liabilities. cards must come from
+// CreditCardRepository so installment balances are included.
+func (r *PaylaterRepository) GetBalanceSheet(userID uuid.UUID, cards []models.CreditCard, now time.Time) (*models.BalanceSheet, error) {
+	var accounts []models.Account
+	err := r.db.Select(&accounts, `SELECT id, user_id, name, type, balance, currency, parent_account_id, credit_limit, due_day, created_at, updated_at FROM accounts WHERE user_id = $1`, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	upcoming, err := r.GetUpcoming(userID, now.AddDate(10, 0, 0), now)
+	if err != nil {
+		return nil, err
+	}
+	nextDue := make(map[uuid.UUID]*models.UpcomingDue)
+	for i := range upcoming {
+		u := &upcoming[i]
+		if next, ok := nextDue[u.AccountID]; !ok || u.DueDate.Before(next.DueDate) {
+			nextDue[u.AccountID] = u
+		}
+	}
+
+	sheet := &models.BalanceSheet{Liabilities: []models.LiabilityItem{}}
+	for _, acc := range accounts {
+		if !acc.IsLiability() {
+			sheet.TotalAssets += acc.Balance
+			continue
+		}
+		if acc.Balance > 0 {
+			// Overpaid paylater balance is money the provider owes back
+			sheet.TotalAssets += acc.Balance
+			continue
+		}
+		item := models.LiabilityItem{
+			Kind:        string(models.AccountTypePaylater),
+			ID:          acc.ID,
+			Name:        acc.Name,
+			Balance:     -acc.Balance,
+			CreditLimit: acc.CreditLimit,
+		}
+		if next := nextDue[acc.ID]; next != nil {
+			due := next.DueDate
+			item.NextDueDate = &due
+			for _, u := range upcoming {
+				if u.AccountID == acc.ID && u.DueDate.Equal(due) {
+					item.NextDue += u.Amount
+				}
+			}
+		}
+		sheet.PaylaterDebt += item.Balance
+		sheet.Liabilities = append(sheet.Liabilities, item)
+	}
+
+	for _, card := range cards {
+		limit := card.CreditLimit
+		item := models.LiabilityItem{
+			Kind:        "credit_card",
+			ID:          card.ID,
+			Name:        card.CardName,
+			Balance:     card.CurrentBalance + card.InstallmentBalance,
+			CreditLimit: &limit,
+		}
+		if card.CurrentBalance > 0 {
+			month, year := models.StatementMonthOf(now, card.BillingDate)
+			_, _, due := models.StatementDates(month-1, year, card.BillingDate, card.PaymentDueDate)
+			if due.Before(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)) {
+				_, _, due = models.StatementDates(month, year, card.BillingDate, card.PaymentDueDate)
+			}
+			item.NextDueDate = &due
+			item.NextDue = card.MinimumPayment(card.CurrentBalance)
+		}
+		sheet.CreditCardDebt += item.Balance
+		sheet.Liabilities = append(sheet.Liabilities, item)
+	}
+
+	sort.SliceStable(sheet.Liabilities, func(i, j int) bool {
+		return sheet.Liabilities[i].Balance > sheet.Liabilities[j].Balance
+	})
+	sheet.TotalLiabilities = sheet.PaylaterDebt + sheet.CreditCardDebt
+	sheet.NetWorth = sheet.TotalAssets - sheet.TotalLiabilities
+
+	return sheet, nil
+}
@@ -34,6 +34,20 @@ func (r *TransactionRepository) Create(tx *models.Transaction) error {
 	return nil
 }
 
+// insertTransaction books t inside a database transaction. Balances are left
+// to the caller.
+func insertTransaction(tx *sqlx.Tx, t *models.Transaction) error {
+	query := `
+		INSERT INTO transactions (id, user_id, account_id, type, category, amount, description, transaction_date, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+	_, err := tx.Exec(query, t.ID, t.UserID, t.AccountID, t.Type, t.Category, t.Amount, t.Description, t.TransactionDate, t.CreatedAt, t.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create transaction: %w", err)
+	}
+	return nil
+}
+
 func (r *TransactionRepository) GetByUserID(userID uuid.UUID, limit, offset int) ([]models.Transaction, error) {
 	var transactions []models.Transaction
 	query := `
@@ -91,3 +105,32 @@ func (r *TransactionRepository) GetSummary(userID uuid.UUID) (*models.Transactio
 	summary.Balance = summary.TotalIncome - summary.TotalExpense
 	return summary, nil
 }
+
+// GetMonthlySummary returns income and expense totals of one of the user's
+// financial months (see models.FinancialMonthRange)
+func (r *TransactionRepository) GetMonthlySummary(userID uuid.UUID, month, year int) (*models.TransactionSummary, error) {
+	startDay, err := getMonthStartDay(r.db, userID)
+	if err != nil {
+		return nil, err
+	}
+	start, end := models.FinancialMonthRange(month, year, startDay)
+
+	summary := &models.TransactionSummary{}
+	query := `
+		SELECT
+			COALESCE(SUM(CASE WHEN type = 'income' THEN amount ELSE 0 END), 0) as total_income,
+			COALESCE(SUM(CASE WHEN type = 'expense' THEN amount ELSE 0 END), 0) as total_expense
+		FROM transactions
+		WHERE user_id = $1 AND transaction_date >= $2 AND transaction_date < $3
+	`
+	err = r.db.QueryRow(query, userID, start, end).Scan(&summary.TotalIncome, &summary.TotalExpense)
+	if err != nil {
+		return nil, err
+	}
+
+	summary.Balance = summary.TotalIncome - summary.TotalExpense
+	lastDay := end.AddDate(0, 0, -1)
+	summary.PeriodStart = &start
+	summary.PeriodEnd = &lastDay
+	return summary, nil
+}
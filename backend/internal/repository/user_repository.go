@@ -22,12 +22,18 @@ func (r *UserRepository) Create(user *models.User) error {
 	user.ID = uuid.New()
 	user.CreatedAt = time.Now()
 	user.UpdatedAt = time.Now()
+	if user.MonthStartDay == 0 {
+		user.MonthStartDay = 1
+	}
+	if user.BudgetingMode == "" {
+		user.BudgetingMode = models.BudgetingStandard
+	}
 
 	query := `
-		INSERT INTO users (id, email, password_hash, full_name, is_admin, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO users (id, email, password_hash, full_name, is_admin, month_start_day, budgeting_mode, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 	`
-	_, err := r.db.Exec(query, user.ID, user.Email, user.PasswordHash, user.FullName, user.IsAdmin, user.CreatedAt, user.UpdatedAt)
+	_, err := r.db.Exec(query, user.ID, user.Email, user.PasswordHash, user.FullName, user.IsAdmin, user.MonthStartDay, user.BudgetingMode, user.CreatedAt, user.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to create user: %w", err)
 	}
@@ -37,7 +43,7 @@ func (r *UserRepository) Create(user *models.User) error {
 
 func (r *UserRepository) GetByEmail(email string) (*models.User, error) {
 	var user models.User
-	query := `SELECT id, email, password_hash, full_name, is_admin, created_at, updated_at FROM users WHERE email = $1`
+	query := `SELECT id, email, password_hash, full_name, is_admin, month_start_day, budgeting_mode, created_at, updated_at FROM users WHERE email = $1`
 	err := r.db.Get(&user, query, email)
 	if err != nil {
 		return nil, err
@@ -47,7 +53,7 @@ func (r *UserRepository) GetByEmail(email string) (*models.User, error) {
 
 func (r *UserRepository) GetByID(id uuid.UUID) (*models.User, error) {
 	var user models.User
-	query := `SELECT id, email, password_hash, full_name, is_admin, created_at, updated_at FROM users WHERE id = $1`
+	query := `SELECT id, email, password_hash, full_name, is_admin, month_start_day, budgeting_mode, created_at, updated_at FROM users WHERE id = $1`
 	err := r.db.Get(&user, query, id)
 	if err != nil {
 		return nil, err
@@ -59,7 +65,7 @@ func (r *UserRepository) GetByID(id uuid.UUID) (*models.User, error) {
 
 func (r *UserRepository) GetAll() ([]models.User, error) {
 	var users []models.User
-	query := `SELECT id, email, full_name, is_admin, created_at, updated_at FROM users ORDER BY created_at DESC`
+	query := `SELECT id, email, full_name, is_admin, month_start_day, budgeting_mode, created_at, updated_at FROM users ORDER BY created_at DESC`
 	err := r.db.Select(&users, query)
 	if err != nil {
 		return nil, err
@@ -80,6 +86,34 @@ func (r *UserRepository) Delete(id uuid.UUID) error {
 	return err
 }
 
+// UpdateMonthStartDay sets the day of the month on which the user's financial month begins
+func (r *UserRepository) UpdateMonthStartDay(id uuid.UUID, day int) error {
+	query := `UPDATE users SET month_start_day = $1, updated_at = $2 WHERE id = $3`
+	_, err := r.db.Exec(query, day, time.Now(), id)
+	return err
+}
+
+// UpdateBudgetingMode switches the user between standard and zero-based budgeting
+func (r *UserRepository) UpdateBudgetingMode(id uuid.UUID, mode models.BudgetingMode) error {
+	query := `UPDATE users SET budgeting_mode = $1, updated_at = $2 WHERE id = $3`
+	_, err := r.db.Exec(query, mode, time.Now(), id)
+	return err
+}
+
+// getMonthStartDay returns the financial month start day of a user
+func getMonthStartDay(db *sqlx.DB, userID uuid.UUID) (int, error) {
+	var day int
+	err := db.Get(&day, `SELECT month_start_day FROM users WHERE id = $1`, userID)
+	return day, err
+}
+
+// getBudgetingMode returns the budgeting mode of a user
+func getBudgetingMode(db *sqlx.DB, userID uuid.UUID) (models.BudgetingMode, error) {
+	var mode models.BudgetingMode
+	err := db.Get(&mode, `SELECT budgeting_mode FROM users WHERE id = $1`, userID)
+	return mode, err
+}
+
 func (r *UserRepository) SetAdmin(id uuid.UUID, isAdmin bool) error {
 	query := `UPDATE users SET is_admin = $1, updated_at = $2 WHERE id = $3`
 	_, err := r.db.Exec(query, isAdmin, time.Now(), id)
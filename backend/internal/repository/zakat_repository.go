@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/financial-tracker/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const zakatPaymentColumns = `id, user_id, zakat_type, period_year, period_month, amount, account_id, transaction_id, paid_at, COALESCE(notes, '') AS notes, created_at`
+
+type ZakatRepository struct {
+	db *sqlx.DB
+}
+
+func NewZakatRepository(db *sqlx.DB) *ZakatRepository {
+	return &ZakatRepository{db: db}
+}
+
+// GetCashHistory returns the cash in the user's asset (non-paylater)
+// accounts at the end of every day from from to to. Past balances are
+// rebuilt from today's by undoing the transactions booked after each day
+// that moved a balance: income, expenses, and the transfers that paid a
+// credit card or a paylater installment. Other transfers are only records
+// and never touched a balance.
+func (r *ZakatRepository) GetCashHistory(userID uuid.UUID, from, to time.Time) ([]models.ZakatWealthDay, error) {
+	var current float64
+	err := r.db.Get(&current, `SELECT COALESCE(SUM(balance), 0) FROM accounts WHERE user_id = $1 AND type <> 'paylater'`, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var flows []struct {
+		Day time.Time `db:"day"`
+		Net float64   `db:"net"`
+	}
+	err = r.db.Select(&flows, `
+		SELECT t.transaction_date::date AS day,
+			SUM(CASE WHEN t.type = 'income' THEN t.amount ELSE -t.amount END) AS net
+		FROM transactions t JOIN accounts a ON a.id = t.account_id
+		WHERE t.user_id = $1 AND a.type <> 'paylater' AND t.transaction_date::date > $2::date
+			AND (t.type IN ('income', 'expense')
+				OR EXISTS (SELECT 1 FROM credit_card_transactions c WHERE c.transaction_id = t.id)
+				OR EXISTS (SELECT 1 FROM paylater_installments i WHERE i.payment_transaction_id = t.id))
+		GROUP BY 1
+	`, userID, from)
+	if err != nil {
+		return nil, err
+	}
+	netByDay := make(map[string]float64, len(flows))
+	for _, f := range flows {
+		netByDay[f.Day.Format("2006-01-02")] = f.Net
+	}
+
+	// Undo everything after to, then walk back one day at a time
+	cash := current
+	for day, net := range netByDay {
+		if day > to.Format("2006-01-02") {
+			cash -= net
+		}
+	}
+
+	days := models.SeriesDates(from, to, models.ValuationDaily)
+	history := make([]models.ZakatWealthDay, len(days))
+	for i := len(days) - 1; i >= 0; i-- {
+		if i < len(days)-1 {
+			cash -= netByDay[days[i+1].Format("2006-01-02")]
+		}
+		history[i] = models.ZakatWealthDay{Date: days[i], Cash: cash}
+	}
+	return history, nil
+}
+
+// GetMonthlyIncome sums the user's income transactions in one of their
+// financial months (see models.FinancialMonthRange), leaving out the given
+// categories. It also returns the month's first and last day.
+func (r *ZakatRepository) GetMonthlyIncome(userID uuid.UUID, month, year int, excludeCategories []string) (float64, time.Time, time.Time, error) {
+	startDay, err := getMonthStartDay(r.db, userID)
+	if err != nil {
+		return 0, time.Time{}, time.Time{}, err
+	}
+	start, end := models.FinancialMonthRange(month, year, startDay)
+
+	var income float64
+	err = r.db.Get(&income, `
+		SELECT COALESCE(SUM(amount), 0) FROM transactions
+		WHERE user_id = $1 AND type = 'income' AND transaction_date >= $2 AND transaction_date < $3
+			AND NOT (category = ANY($4))
+	`, userID, start, end, pq.StringArray(excludeCategories))
+	if err != nil {
+		return 0, time.Time{}, time.Time{}, err
+	}
+	return income, start, end.AddDate(0, 0, -1), nil
+}
+
+// CreatePayment records a zakat payment. With an account the amount is
+// booked as a 'Zakat' expense and taken from its balance, all in one
+// database transaction.
+func (r *ZakatRepository) CreatePayment(p *models.ZakatPayment) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	p.ID = uuid.New()
+	p.CreatedAt = time.Now()
+
+	if p.AccountID != nil {
+		description := fmt.Sprintf("Zakat %s %d", p.ZakatType, p.PeriodYear)
+		if p.PeriodMonth != nil {
+			description = fmt.Sprintf("Zakat %s %02d/%d", p.ZakatType, *p.PeriodMonth, p.PeriodYear)
+		}
+		expense := &models.Transaction{
+			ID:              uuid.New(),
+			UserID:          p.UserID,
+			AccountID:       *p.AccountID,
+			Type:            models.TransactionTypeExpense,
+			Category:        "Zakat",
+			Amount:          p.Amount,
+			Description:     description,
+			TransactionDate: p.PaidAt,
+			CreatedAt:       p.CreatedAt,
+			UpdatedAt:       p.CreatedAt,
+		}
+		if err := insertTransaction(tx, expense); err != nil {
+			return err
+		}
+		_, err = tx.Exec(`UPDATE accounts SET balance = balance - $1, updated_at = $2 WHERE id = $3`, p.Amount, p.CreatedAt, *p.AccountID)
+		if err != nil {
+			return fmt.Errorf("failed to update account balance: %w", err)
+		}
+		p.TransactionID = &expense.ID
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO zakat_payments (id, user_id, zakat_type, period_year, period_month, amount, account_id, transaction_id, paid_at, notes, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`, p.ID, p.UserID, p.ZakatType, p.PeriodYear, p.PeriodMonth, p.Amount, p.AccountID, p.TransactionID, p.PaidAt, p.Notes, p.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create zakat payment: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// GetPayments returns the user's payments, newest first, optionally of one
+// type and/or year (0 for all)
+func (r *ZakatRepository) GetPayments(userID uuid.UUID, zakatType models.ZakatType, year int) ([]models.ZakatPayment, error) {
+	payments := []models.ZakatPayment{}
+	query := `SELECT ` + zakatPaymentColumns + ` FROM zakat_payments
+		WHERE user_id = $1 AND ($2 = '' OR zakat_type = $2) AND ($3 = 0 OR period_year = $3)
+		ORDER BY paid_at DESC, created_at DESC`
+	if err := r.db.Select(&payments, query, userID, string(zakatType), year); err != nil {
+		return nil, err
+	}
+	return payments, nil
+}
+
+// GetPaid sums what the user has paid for a period; month is ignored for
+// zakat mal
+func (r *ZakatRepository) GetPaid(userID uuid.UUID, zakatType models.ZakatType, year, month int) (float64, error) {
+	var paid float64
+	err := r.db.Get(&paid, `
+		SELECT COALESCE(SUM(amount), 0) FROM zakat_payments
+		WHERE user_id = $1 AND zakat_type = $2 AND period_year = $3 AND ($2 = 'mal' OR period_month = $4)
+	`, userID, zakatType, year, month)
+	return paid, err
+}
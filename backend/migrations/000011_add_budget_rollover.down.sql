@@ -0,0 +1,4 @@
+-- Rollback migration 011
+ALTER TABLE budgets DROP COLUMN IF EXISTS rollover_cap;
+ALTER TABLE budgets DROP COLUMN IF EXISTS rollover_mode;
+DROP TYPE IF EXISTS budget_rollover_mode;
@@ -0,0 +1,8 @@
+-- Migration 011: Budget rollover
+-- Unused (or overspent) budget amounts can be carried into the next month
+
+CREATE TYPE budget_rollover_mode AS ENUM ('none', 'surplus', 'surplus_and_deficit');
+
+ALTER TABLE budgets ADD COLUMN rollover_mode budget_rollover_mode NOT NULL DEFAULT 'none';
+-- Optional cap on the absolute amount carried over (NULL = no cap)
+ALTER TABLE budgets ADD COLUMN rollover_cap DECIMAL(15, 2) CHECK (rollover_cap >= 0);
@@ -0,0 +1,20 @@
+-- Migration 012: Budget threshold alerts
+-- 1. Per-budget alert thresholds (percent of available budget)
+-- 2. Notifications table shared by all alert sources
+
+ALTER TABLE budgets ADD COLUMN alert_thresholds INTEGER[] NOT NULL DEFAULT '{80,100}';
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(50) NOT NULL, -- 'budget_threshold', etc
+    title VARCHAR(255) NOT NULL,
+    message TEXT NOT NULL,
+    data JSONB NOT NULL DEFAULT '{}',
+    dedupe_key VARCHAR(255), -- same key never fires twice for a user
+    is_read BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_notifications_user ON notifications(user_id, created_at DESC);
+CREATE UNIQUE INDEX idx_notifications_dedupe ON notifications(user_id, dedupe_key) WHERE dedupe_key IS NOT NULL;
@@ -0,0 +1,2 @@
+-- Rollback migration 013
+ALTER TABLE users DROP COLUMN IF EXISTS month_start_day;
@@ -0,0 +1,5 @@
+-- Migration 013: Payday-based financial month
+-- Budgets, progress and summaries use months starting on this day of the month
+
+ALTER TABLE users ADD COLUMN month_start_day INTEGER NOT NULL DEFAULT 1
+    CHECK (month_start_day >= 1 AND month_start_day <= 31);
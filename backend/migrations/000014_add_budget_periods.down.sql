@@ -0,0 +1,10 @@
+-- Rollback migration 014
+DELETE FROM budgets WHERE period <> 'monthly';
+
+DROP INDEX IF EXISTS idx_budgets_unique;
+CREATE UNIQUE INDEX idx_budgets_unique ON budgets(user_id, category, budget_month, budget_year);
+
+ALTER TABLE budgets DROP CONSTRAINT IF EXISTS chk_budgets_period;
+ALTER TABLE budgets DROP COLUMN IF EXISTS budget_week;
+ALTER TABLE budgets DROP COLUMN IF EXISTS period;
+DROP TYPE IF EXISTS budget_period;
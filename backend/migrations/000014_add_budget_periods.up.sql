@@ -0,0 +1,18 @@
+-- Migration 014: Weekly and yearly budgets next to monthly ones
+-- Monthly budgets use budget_month 1-12; yearly budgets use budget_month 0;
+-- weekly budgets use budget_month 0 and the ISO week in budget_week
+-- (budget_year is then the ISO week-numbering year)
+
+CREATE TYPE budget_period AS ENUM ('weekly', 'monthly', 'yearly');
+
+ALTER TABLE budgets ADD COLUMN period budget_period NOT NULL DEFAULT 'monthly';
+ALTER TABLE budgets ADD COLUMN budget_week INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE budgets ADD CONSTRAINT chk_budgets_period CHECK (
+    (period = 'monthly' AND budget_month BETWEEN 1 AND 12 AND budget_week = 0) OR
+    (period = 'yearly' AND budget_month = 0 AND budget_week = 0) OR
+    (period = 'weekly' AND budget_month = 0 AND budget_week BETWEEN 1 AND 53)
+);
+
+DROP INDEX IF EXISTS idx_budgets_unique;
+CREATE UNIQUE INDEX idx_budgets_unique ON budgets(user_id, category, period, budget_year, budget_month, budget_week);
@@ -0,0 +1,7 @@
+-- Rollback migration 015
+
+DROP INDEX IF EXISTS idx_budgets_envelope_account;
+ALTER TABLE budgets DROP COLUMN IF EXISTS envelope_account_id;
+
+ALTER TABLE users DROP COLUMN IF EXISTS budgeting_mode;
+DROP TYPE IF EXISTS budgeting_mode;
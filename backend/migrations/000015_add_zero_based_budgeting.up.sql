@@ -0,0 +1,10 @@
+-- Migration 015: Zero-based (envelope) budgeting
+-- In zero_based mode each financial month's income is "to be assigned" to budgets;
+-- a budget can be backed by a pocket (sub-account) holding its money
+
+CREATE TYPE budgeting_mode AS ENUM ('standard', 'zero_based');
+
+ALTER TABLE users ADD COLUMN budgeting_mode budgeting_mode NOT NULL DEFAULT 'standard';
+
+ALTER TABLE budgets ADD COLUMN envelope_account_id UUID REFERENCES accounts(id) ON DELETE SET NULL;
+CREATE INDEX idx_budgets_envelope_account ON budgets(envelope_account_id) WHERE envelope_account_id IS NOT NULL;
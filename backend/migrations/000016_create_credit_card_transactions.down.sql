@@ -0,0 +1,3 @@
+-- Rollback migration 016
+DROP TABLE IF EXISTS credit_card_transactions;
+DROP TYPE IF EXISTS credit_card_transaction_type;
@@ -0,0 +1,22 @@
+-- Migration 016: Credit card charges and payments
+-- Purchases and fees raise a card's current_balance, refunds and payments lower it.
+-- A payment from a bank account is linked to the transfer transaction on that account.
+
+CREATE TYPE credit_card_transaction_type AS ENUM ('purchase', 'refund', 'fee', 'payment');
+
+CREATE TABLE IF NOT EXISTS credit_card_transactions (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    credit_card_id UUID NOT NULL REFERENCES credit_cards(id) ON DELETE CASCADE,
+    type credit_card_transaction_type NOT NULL,
+    category VARCHAR(100) NOT NULL,
+    amount DECIMAL(15, 2) NOT NULL CHECK (amount > 0),
+    description TEXT,
+    transaction_date TIMESTAMP NOT NULL,
+    transaction_id UUID REFERENCES transactions(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_cc_transactions_card_id ON credit_card_transactions(credit_card_id);
+CREATE INDEX idx_cc_transactions_user_date ON credit_card_transactions(user_id, transaction_date);
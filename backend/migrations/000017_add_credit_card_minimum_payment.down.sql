@@ -0,0 +1,3 @@
+-- Rollback migration 017
+ALTER TABLE credit_cards DROP COLUMN IF EXISTS min_payment_floor;
+ALTER TABLE credit_cards DROP COLUMN IF EXISTS min_payment_percent;
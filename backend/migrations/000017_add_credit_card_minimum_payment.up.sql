@@ -0,0 +1,8 @@
+-- Migration 017: Minimum payment settings for credit card statements
+-- The minimum payment is min_payment_percent of the statement balance,
+-- but at least min_payment_floor (and never more than the balance)
+
+ALTER TABLE credit_cards ADD COLUMN min_payment_percent DECIMAL(5, 2) NOT NULL DEFAULT 5
+    CHECK (min_payment_percent >= 0 AND min_payment_percent <= 100);
+ALTER TABLE credit_cards ADD COLUMN min_payment_floor DECIMAL(15, 2) NOT NULL DEFAULT 50000
+    CHECK (min_payment_floor >= 0);
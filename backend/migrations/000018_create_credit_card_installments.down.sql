@@ -0,0 +1,7 @@
+-- Rollback migration 018
+-- Postgres cannot drop an enum value; 'installment' stays in credit_card_transaction_type
+DROP INDEX IF EXISTS idx_cc_transactions_installment;
+ALTER TABLE credit_card_transactions DROP COLUMN IF EXISTS installment_number;
+ALTER TABLE credit_card_transactions DROP COLUMN IF EXISTS installment_id;
+
+DROP TABLE IF EXISTS credit_card_installments;
@@ -0,0 +1,30 @@
+-- Migration 018: Credit card installment plans (cicilan)
+-- A plan bills principal / tenor plus flat monthly interest on each statement,
+-- starting with the statement closing in start_month/start_year. Postings are
+-- card transactions linked to the plan; (installment_id, installment_number)
+-- keeps them idempotent. Number 0 is the one-off admin fee.
+
+ALTER TYPE credit_card_transaction_type ADD VALUE IF NOT EXISTS 'installment';
+
+CREATE TABLE IF NOT EXISTS credit_card_installments (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    credit_card_id UUID NOT NULL REFERENCES credit_cards(id) ON DELETE CASCADE,
+    description TEXT NOT NULL,
+    category VARCHAR(100) NOT NULL,
+    principal DECIMAL(15, 2) NOT NULL CHECK (principal > 0),
+    tenor_months INT NOT NULL CHECK (tenor_months >= 1 AND tenor_months <= 60),
+    interest_rate_monthly DECIMAL(6, 3) NOT NULL DEFAULT 0 CHECK (interest_rate_monthly >= 0),
+    admin_fee DECIMAL(15, 2) NOT NULL DEFAULT 0 CHECK (admin_fee >= 0),
+    start_month INT NOT NULL CHECK (start_month >= 1 AND start_month <= 12),
+    start_year INT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_cc_installments_card_id ON credit_card_installments(credit_card_id);
+
+ALTER TABLE credit_card_transactions ADD COLUMN installment_id UUID REFERENCES credit_card_installments(id) ON DELETE SET NULL;
+ALTER TABLE credit_card_transactions ADD COLUMN installment_number INT;
+CREATE UNIQUE INDEX idx_cc_transactions_installment ON credit_card_transactions(installment_id, installment_number)
+    WHERE installment_id IS NOT NULL;
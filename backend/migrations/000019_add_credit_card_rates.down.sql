@@ -0,0 +1,4 @@
+-- Rollback migration 019
+ALTER TABLE credit_cards DROP COLUMN IF EXISTS late_fee_cap;
+ALTER TABLE credit_cards DROP COLUMN IF EXISTS late_fee_percent;
+ALTER TABLE credit_cards DROP COLUMN IF EXISTS interest_rate_monthly;
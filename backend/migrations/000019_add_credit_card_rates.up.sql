@@ -0,0 +1,10 @@
+-- Migration 019: Per-card interest and late fee rates for cost projections
+-- Defaults follow common Indonesian card terms: 1.75% interest per month,
+-- late fee of 1% of the amount due capped at Rp 100,000
+
+ALTER TABLE credit_cards ADD COLUMN interest_rate_monthly DECIMAL(5, 3) NOT NULL DEFAULT 1.75
+    CHECK (interest_rate_monthly >= 0);
+ALTER TABLE credit_cards ADD COLUMN late_fee_percent DECIMAL(5, 2) NOT NULL DEFAULT 1
+    CHECK (late_fee_percent >= 0);
+ALTER TABLE credit_cards ADD COLUMN late_fee_cap DECIMAL(15, 2) NOT NULL DEFAULT 100000
+    CHECK (late_fee_cap >= 0);
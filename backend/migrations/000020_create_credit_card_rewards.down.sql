@@ -0,0 +1,5 @@
+-- Rollback migration 020
+DROP TABLE IF EXISTS credit_card_reward_earnings;
+DROP TABLE IF EXISTS credit_card_reward_redemptions;
+DROP TABLE IF EXISTS credit_card_reward_rules;
+DROP TYPE IF EXISTS reward_type;
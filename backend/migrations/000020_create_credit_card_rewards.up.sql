@@ -0,0 +1,57 @@
+-- Migration 020: Credit card rewards (points, miles, cashback)
+-- A rule earns earn_rate reward units for every spend_unit of spending in its
+-- category (NULL category = every category without its own rule), capped per
+-- statement cycle. unit_value is the rupiah value of one unit so different
+-- reward types can be compared (1 for cashback).
+
+CREATE TYPE reward_type AS ENUM ('points', 'miles', 'cashback');
+
+CREATE TABLE IF NOT EXISTS credit_card_reward_rules (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    credit_card_id UUID NOT NULL REFERENCES credit_cards(id) ON DELETE CASCADE,
+    category VARCHAR(100),
+    reward_type reward_type NOT NULL,
+    earn_rate DECIMAL(10, 4) NOT NULL CHECK (earn_rate > 0),
+    spend_unit DECIMAL(15, 2) NOT NULL CHECK (spend_unit > 0),
+    unit_value DECIMAL(15, 4) NOT NULL DEFAULT 1 CHECK (unit_value >= 0),
+    monthly_cap DECIMAL(15, 2) CHECK (monthly_cap > 0),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_reward_rules_card_category ON credit_card_reward_rules(credit_card_id, COALESCE(category, ''));
+
+CREATE TABLE IF NOT EXISTS credit_card_reward_redemptions (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    credit_card_id UUID NOT NULL REFERENCES credit_cards(id) ON DELETE CASCADE,
+    reward_type reward_type NOT NULL,
+    amount DECIMAL(15, 2) NOT NULL CHECK (amount > 0),
+    value DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    description TEXT,
+    redeemed_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_reward_redemptions_card_id ON credit_card_reward_redemptions(credit_card_id);
+
+-- Units earned by each charge, fixed when the charge is booked so editing or
+-- deleting a rule never rewrites history. Refunds earn negative units.
+CREATE TABLE IF NOT EXISTS credit_card_reward_earnings (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    credit_card_id UUID NOT NULL REFERENCES credit_cards(id) ON DELETE CASCADE,
+    credit_card_transaction_id UUID NOT NULL UNIQUE REFERENCES credit_card_transactions(id) ON DELETE CASCADE,
+    rule_id UUID REFERENCES credit_card_reward_rules(id) ON DELETE SET NULL,
+    category VARCHAR(100) NOT NULL,
+    reward_type reward_type NOT NULL,
+    cycle_month INTEGER NOT NULL,
+    cycle_year INTEGER NOT NULL,
+    spend DECIMAL(15, 2) NOT NULL,
+    earned DECIMAL(15, 4) NOT NULL,
+    capped BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_reward_earnings_card_cycle ON credit_card_reward_earnings(credit_card_id, cycle_year, cycle_month);
@@ -0,0 +1,6 @@
+-- Rollback migration 021
+DROP TABLE IF EXISTS paylater_installments;
+DROP TABLE IF EXISTS paylater_purchases;
+
+ALTER TABLE accounts DROP COLUMN IF EXISTS due_day;
+ALTER TABLE accounts DROP COLUMN IF EXISTS credit_limit;
@@ -0,0 +1,46 @@
+-- Migration 021: Paylater accounts as liabilities
+-- A paylater account's balance is negative while money is owed. credit_limit
+-- caps that debt and due_day is the day of the month installments fall due.
+-- Each purchase gets a schedule of monthly installments (principal + fee).
+
+ALTER TABLE accounts ADD COLUMN credit_limit DECIMAL(15, 2) CHECK (credit_limit > 0);
+ALTER TABLE accounts ADD COLUMN due_day INT CHECK (due_day >= 1 AND due_day <= 31);
+
+-- Existing paylater accounts: what they owe becomes their limit and they fall
+-- due on the day of the month they were opened
+UPDATE accounts
+SET credit_limit = CASE WHEN balance < 0 THEN -balance END,
+    due_day = EXTRACT(DAY FROM created_at)::INT
+WHERE type = 'paylater';
+
+CREATE TABLE IF NOT EXISTS paylater_purchases (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    transaction_id UUID REFERENCES transactions(id) ON DELETE SET NULL,
+    description TEXT NOT NULL,
+    category VARCHAR(100) NOT NULL,
+    principal DECIMAL(15, 2) NOT NULL CHECK (principal > 0),
+    tenor_months INT NOT NULL CHECK (tenor_months >= 1 AND tenor_months <= 24),
+    fee_percent_monthly DECIMAL(6, 3) NOT NULL DEFAULT 0 CHECK (fee_percent_monthly >= 0),
+    admin_fee DECIMAL(15, 2) NOT NULL DEFAULT 0 CHECK (admin_fee >= 0),
+    purchase_date TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_paylater_purchases_account_id ON paylater_purchases(account_id);
+
+CREATE TABLE IF NOT EXISTS paylater_installments (
+    id UUID PRIMARY KEY,
+    purchase_id UUID NOT NULL REFERENCES paylater_purchases(id) ON DELETE CASCADE,
+    installment_number INT NOT NULL,
+    due_date DATE NOT NULL,
+    principal DECIMAL(15, 2) NOT NULL,
+    fee DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    amount DECIMAL(15, 2) NOT NULL,
+    paid_at TIMESTAMP,
+    payment_transaction_id UUID REFERENCES transactions(id) ON DELETE SET NULL,
+    UNIQUE (purchase_id, installment_number)
+);
+
+CREATE INDEX idx_paylater_installments_due ON paylater_installments(due_date) WHERE paid_at IS NULL;
@@ -0,0 +1,17 @@
+-- Rollback migration 022
+-- Keeps one quote per day, preferring antam
+DELETE FROM gold_prices g
+WHERE EXISTS (
+    SELECT 1 FROM gold_prices o
+    WHERE o.price_date = g.price_date AND o.id <> g.id
+      AND (o.gold_type = 'antam' OR (g.gold_type <> 'antam' AND o.id < g.id))
+);
+
+DROP INDEX IF EXISTS idx_gold_prices_type_date;
+ALTER TABLE gold_prices DROP CONSTRAINT IF EXISTS gold_prices_date_type_key;
+ALTER TABLE gold_prices ADD CONSTRAINT gold_prices_price_date_key UNIQUE (price_date);
+CREATE INDEX idx_gold_prices_date ON gold_prices(price_date DESC);
+
+ALTER TABLE gold_prices DROP COLUMN IF EXISTS buyback_price_per_gram;
+ALTER TABLE gold_prices RENAME COLUMN buy_price_per_gram TO price_per_gram;
+ALTER TABLE gold_prices DROP COLUMN IF EXISTS gold_type;
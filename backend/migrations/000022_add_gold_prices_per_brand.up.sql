@@ -0,0 +1,18 @@
+-- Migration 022: Gold prices per brand with buy and buyback prices
+-- Each brand (antam, ubs, galeri24, pegadaian, other) is quoted separately
+-- per day. buy_price_per_gram is what a buyer pays; buyback_price_per_gram
+-- is what the dealer pays back and is what holdings are valued at.
+-- Existing quotes were a single reference price and become antam quotes
+-- with the same buyback price.
+
+ALTER TABLE gold_prices ADD COLUMN gold_type gold_type NOT NULL DEFAULT 'antam';
+ALTER TABLE gold_prices RENAME COLUMN price_per_gram TO buy_price_per_gram;
+ALTER TABLE gold_prices ADD COLUMN buyback_price_per_gram DECIMAL(15, 2);
+UPDATE gold_prices SET buyback_price_per_gram = buy_price_per_gram;
+ALTER TABLE gold_prices ALTER COLUMN buyback_price_per_gram SET NOT NULL;
+
+ALTER TABLE gold_prices DROP CONSTRAINT IF EXISTS gold_prices_price_date_key;
+ALTER TABLE gold_prices ADD CONSTRAINT gold_prices_date_type_key UNIQUE (price_date, gold_type);
+
+DROP INDEX IF EXISTS idx_gold_prices_date;
+CREATE INDEX idx_gold_prices_type_date ON gold_prices(gold_type, price_date DESC);
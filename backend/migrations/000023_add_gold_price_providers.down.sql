@@ -0,0 +1,2 @@
+-- Rollback migration 023
+DELETE FROM api_configurations WHERE api_name IN ('gold_price_primary', 'gold_price_fallback');
@@ -0,0 +1,10 @@
+-- Migration 023: Gold price providers
+-- Providers live in api_configurations with api_type 'gold_price'. Config:
+-- endpoint (may contain {date}), api_key, api_key_header, timeout_seconds
+-- and priority (lowest is tried first). Fetched prices record the provider
+-- as source ('api:<api_name>').
+
+INSERT INTO api_configurations (api_name, api_type, config, is_active) VALUES
+('gold_price_primary', 'gold_price', '{"endpoint": "", "api_key": "", "api_key_header": "Authorization", "timeout_seconds": 15, "priority": 1}', false),
+('gold_price_fallback', 'gold_price', '{"endpoint": "", "api_key": "", "api_key_header": "Authorization", "timeout_seconds": 15, "priority": 2}', false)
+ON CONFLICT (api_name) DO NOTHING;
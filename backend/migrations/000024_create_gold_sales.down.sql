@@ -0,0 +1,6 @@
+-- Rollback migration 024
+DROP TABLE IF EXISTS gold_sale_lots;
+DROP TABLE IF EXISTS gold_sales;
+
+ALTER TABLE gold_assets DROP CONSTRAINT IF EXISTS gold_assets_remaining_weight_check;
+ALTER TABLE gold_assets DROP COLUMN IF EXISTS remaining_weight_gram;
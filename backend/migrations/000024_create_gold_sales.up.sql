@@ -0,0 +1,42 @@
+-- Migration 024: Selling gold
+-- Every gold asset is a lot; remaining_weight_gram is what is still held.
+-- A sale takes weight from one or more lots (FIFO or chosen lots), books the
+-- proceeds as income into an account and records the realized gain per lot.
+
+ALTER TABLE gold_assets ADD COLUMN remaining_weight_gram DECIMAL(10, 4);
+UPDATE gold_assets SET remaining_weight_gram = weight_gram;
+ALTER TABLE gold_assets ALTER COLUMN remaining_weight_gram SET NOT NULL;
+ALTER TABLE gold_assets ADD CONSTRAINT gold_assets_remaining_weight_check
+    CHECK (remaining_weight_gram >= 0 AND remaining_weight_gram <= weight_gram);
+
+CREATE TABLE IF NOT EXISTS gold_sales (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    account_id UUID REFERENCES accounts(id) ON DELETE SET NULL,
+    transaction_id UUID REFERENCES transactions(id) ON DELETE SET NULL,
+    sale_date DATE NOT NULL,
+    weight_gram DECIMAL(10, 4) NOT NULL CHECK (weight_gram > 0),
+    price_per_gram DECIMAL(15, 2) NOT NULL CHECK (price_per_gram > 0),
+    fee DECIMAL(15, 2) NOT NULL DEFAULT 0 CHECK (fee >= 0),
+    proceeds DECIMAL(15, 2) NOT NULL,
+    cost_basis DECIMAL(15, 2) NOT NULL,
+    realized_gain DECIMAL(15, 2) NOT NULL,
+    notes TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_gold_sales_user_date ON gold_sales(user_id, sale_date DESC);
+
+-- The lots a sale was taken from; asset_id is cleared if the lot is deleted
+CREATE TABLE IF NOT EXISTS gold_sale_lots (
+    id UUID PRIMARY KEY,
+    sale_id UUID NOT NULL REFERENCES gold_sales(id) ON DELETE CASCADE,
+    asset_id UUID REFERENCES gold_assets(id) ON DELETE SET NULL,
+    gold_type gold_type NOT NULL,
+    weight_gram DECIMAL(10, 4) NOT NULL CHECK (weight_gram > 0),
+    cost_per_gram DECIMAL(15, 2) NOT NULL,
+    proceeds DECIMAL(15, 2) NOT NULL,
+    realized_gain DECIMAL(15, 2) NOT NULL
+);
+
+CREATE INDEX idx_gold_sale_lots_sale ON gold_sale_lots(sale_id);
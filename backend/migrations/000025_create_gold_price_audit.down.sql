@@ -0,0 +1,2 @@
+-- Rollback migration 025
+DROP TABLE IF EXISTS gold_price_changes;
@@ -0,0 +1,21 @@
+-- Migration 025: Gold price audit trail
+-- Every write to gold_prices (set, correct, delete, CSV import or provider
+-- fetch) records the old and new prices. changed_by is the admin who made
+-- the change, NULL for the scheduled fetcher.
+
+CREATE TABLE IF NOT EXISTS gold_price_changes (
+    id UUID PRIMARY KEY,
+    price_date DATE NOT NULL,
+    gold_type gold_type NOT NULL,
+    action VARCHAR(20) NOT NULL CHECK (action IN ('create', 'update', 'delete')),
+    old_buy_price_per_gram DECIMAL(15, 2),
+    old_buyback_price_per_gram DECIMAL(15, 2),
+    new_buy_price_per_gram DECIMAL(15, 2),
+    new_buyback_price_per_gram DECIMAL(15, 2),
+    source VARCHAR(100),
+    changed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    changed_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_gold_price_changes_changed_at ON gold_price_changes(changed_at DESC);
+CREATE INDEX idx_gold_price_changes_type_date ON gold_price_changes(gold_type, price_date);
@@ -0,0 +1,2 @@
+-- Rollback migration 026
+DROP TABLE IF EXISTS gold_price_alerts;
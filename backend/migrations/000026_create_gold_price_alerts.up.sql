@@ -0,0 +1,26 @@
+-- Migration 026: Gold price alerts
+-- price_above / price_below compare the buy or buyback price of a brand with
+-- threshold; daily_change fires when a brand moves more than threshold
+-- percent from its previous quote; position_pl fires when the unrealized
+-- P/L percent of the user's gold (of gold_type, or all of it) reaches
+-- threshold (a negative threshold watches losses).
+-- is_triggered is set while the condition holds so an alert fires once per
+-- crossing and re-arms when the condition stops holding.
+
+CREATE TABLE IF NOT EXISTS gold_price_alerts (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    alert_type VARCHAR(20) NOT NULL CHECK (alert_type IN ('price_above', 'price_below', 'daily_change', 'position_pl')),
+    gold_type gold_type,
+    price_kind VARCHAR(10) NOT NULL DEFAULT 'buy' CHECK (price_kind IN ('buy', 'buyback')),
+    threshold DECIMAL(15, 2) NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    is_triggered BOOLEAN NOT NULL DEFAULT false,
+    last_triggered_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CHECK (gold_type IS NOT NULL OR alert_type = 'position_pl')
+);
+
+CREATE INDEX idx_gold_price_alerts_user ON gold_price_alerts(user_id);
+CREATE INDEX idx_gold_price_alerts_active ON gold_price_alerts(alert_type, gold_type) WHERE is_active;
@@ -0,0 +1,2 @@
+-- Rollback migration 027
+DROP TABLE IF EXISTS zakat_payments;
@@ -0,0 +1,21 @@
+-- Migration 027: Zakat payments
+-- Zakat mal is paid per year (period_year), zakat penghasilan per financial
+-- month (period_year and period_month). A payment from an account is booked
+-- as a 'Zakat' expense transaction.
+
+CREATE TABLE IF NOT EXISTS zakat_payments (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    zakat_type VARCHAR(20) NOT NULL CHECK (zakat_type IN ('mal', 'penghasilan')),
+    period_year INTEGER NOT NULL,
+    period_month INTEGER CHECK (period_month BETWEEN 1 AND 12),
+    amount DECIMAL(15, 2) NOT NULL CHECK (amount > 0),
+    account_id UUID REFERENCES accounts(id) ON DELETE SET NULL,
+    transaction_id UUID REFERENCES transactions(id) ON DELETE SET NULL,
+    paid_at DATE NOT NULL,
+    notes TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CHECK ((zakat_type = 'penghasilan') = (period_month IS NOT NULL))
+);
+
+CREATE INDEX idx_zakat_payments_user_period ON zakat_payments(user_id, zakat_type, period_year, period_month);
@@ -0,0 +1,4 @@
+-- Rollback migration 028
+ALTER TABLE gold_assets DROP COLUMN IF EXISTS resale_haircut_percent;
+ALTER TABLE gold_assets DROP COLUMN IF EXISTS purity;
+ALTER TABLE gold_assets DROP COLUMN IF EXISTS item_kind;
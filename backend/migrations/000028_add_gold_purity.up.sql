@@ -0,0 +1,11 @@
+-- Migration 028: Gold purity and item kind
+-- Prices are quoted for 24k (999.9) bullion; a lot is valued at that price
+-- scaled by its purity, less resale_haircut_percent for jewelry whose making
+-- charge is lost on resale. Existing lots are 24k bars.
+
+ALTER TABLE gold_assets ADD COLUMN item_kind VARCHAR(20) NOT NULL DEFAULT 'bar'
+    CHECK (item_kind IN ('bar', 'coin', 'jewelry'));
+ALTER TABLE gold_assets ADD COLUMN purity DECIMAL(5, 4) NOT NULL DEFAULT 1
+    CHECK (purity > 0 AND purity <= 1);
+ALTER TABLE gold_assets ADD COLUMN resale_haircut_percent DECIMAL(5, 2) NOT NULL DEFAULT 0
+    CHECK (resale_haircut_percent >= 0 AND resale_haircut_percent < 100);
@@ -0,0 +1,2 @@
+-- Rollback migration 029
+DROP TABLE IF EXISTS gold_pieces;
@@ -0,0 +1,26 @@
+-- Migration 029: Physical gold pieces
+-- A lot (gold asset) can be made of pieces, e.g. a 10g lot of two 5g bars,
+-- each with its certificate number. Pieces are sold whole; sale_lot_id is
+-- set once a piece is sold. Weight of a lot not in any piece stays loose.
+-- Deleting a lot deletes its unsold pieces; sold pieces stay with their
+-- sale and lose the lot, like gold_sale_lots.asset_id.
+
+CREATE TABLE IF NOT EXISTS gold_pieces (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    asset_id UUID REFERENCES gold_assets(id) ON DELETE SET NULL,
+    denomination_gram DECIMAL(10, 4) NOT NULL CHECK (denomination_gram > 0),
+    certificate_number VARCHAR(100),
+    minting_year INTEGER CHECK (minting_year BETWEEN 1900 AND 2100),
+    packaging_condition VARCHAR(20) NOT NULL DEFAULT 'sealed'
+        CHECK (packaging_condition IN ('sealed', 'opened', 'damaged', 'none')),
+    notes TEXT,
+    sale_lot_id UUID REFERENCES gold_sale_lots(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_gold_pieces_asset ON gold_pieces(asset_id);
+CREATE INDEX idx_gold_pieces_sale_lot ON gold_pieces(sale_lot_id);
+CREATE UNIQUE INDEX idx_gold_pieces_user_certificate ON gold_pieces(user_id, certificate_number)
+    WHERE certificate_number IS NOT NULL;
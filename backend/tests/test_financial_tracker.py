@@ -5,7 +5,9 @@ Tests for: Accounts (with sub-accounts/pockets), Budgets (month-year with copy),
 import pytest
 import requests
 import os
+import time
 import uuid
+from datetime import date, timedelta
 
 BASE_URL = "http://localhost:8001/api"
 
@@ -13,6 +15,10 @@ BASE_URL = "http://localhost:8001/api"
 TEST_EMAIL = "test@example.com"
 TEST_PASSWORD = "password123"
 
+# Admin credentials for the gold price management tests (skipped without an admin)
+ADMIN_EMAIL = os.environ.get("ADMIN_EMAIL", TEST_EMAIL)
+ADMIN_PASSWORD = os.environ.get("ADMIN_PASSWORD", TEST_PASSWORD)
+
 class TestAuth:
     """Authentication tests"""
     
@@ -49,6 +55,31 @@ class TestGoldPrice:
         assert data["price_per_gram"] > 0
         # Verify seeded price is around 1,450,000
         assert data["price_per_gram"] == 1450000
+
+    def test_get_gold_price_all_brands(self):
+        """Test getting the latest gold price of each brand"""
+        response = requests.get(f"{BASE_URL}/gold/price", params={"all": "true"})
+        assert response.status_code == 200
+        data = response.json()
+        assert isinstance(data, list)
+        assert len(data) > 0
+        for price in data:
+            assert price["buy_price_per_gram"] > 0
+            assert price["buyback_price_per_gram"] <= price["buy_price_per_gram"]
+
+    def test_get_gold_price_by_type(self):
+        """Test getting the latest price of one brand"""
+        response = requests.get(f"{BASE_URL}/gold/price", params={"gold_type": "antam"})
+        assert response.status_code == 200
+        data = response.json()
+        assert data["gold_type"] == "antam"
+        # Verify seeded price is around 1,450,000
+        assert data["buy_price_per_gram"] == 1450000
+
+    def test_get_gold_price_invalid_type(self):
+        """Test an unknown brand is rejected"""
+        response = requests.get(f"{BASE_URL}/gold/price", params={"gold_type": "bogus"})
+        assert response.status_code == 400
         
     def test_get_gold_price_history(self):
         """Test getting gold price history"""
@@ -76,6 +107,20 @@ def auth_headers(auth_token):
     return {"Authorization": f"Bearer {auth_token}"}
 
 
+@pytest.fixture
+def bank_account(auth_headers):
+    """A throwaway bank account; its transactions are deleted with it"""
+    response = requests.post(f"{BASE_URL}/accounts", headers=auth_headers, json={
+        "name": f"TEST_Bank_{uuid.uuid4().hex[:8]}",
+        "type": "bank",
+        "currency": "IDR"
+    })
+    assert response.status_code == 201
+    account = response.json()
+    yield account
+    requests.delete(f"{BASE_URL}/accounts/{account['id']}", headers=auth_headers)
+
+
 class TestAccounts:
     """Account CRUD tests - verifying no initial_balance field and sub-accounts (pockets)"""
     
@@ -112,11 +157,16 @@ class TestAccounts:
         
         for acc_type in valid_types:
             unique_name = f"TEST_{acc_type}_{uuid.uuid4().hex[:8]}"
-            response = requests.post(f"{BASE_URL}/accounts", headers=auth_headers, json={
+            payload = {
                 "name": unique_name,
                 "type": acc_type,
                 "currency": "IDR"
-            })
+            }
+            if acc_type == "paylater":
+                # Paylater accounts are liabilities and need a limit and due day
+                payload["credit_limit"] = 5000000
+                payload["due_day"] = 25
+            response = requests.post(f"{BASE_URL}/accounts", headers=auth_headers, json=payload)
             assert response.status_code == 201, f"Failed to create {acc_type} account"
             data = response.json()
             assert data["type"] == acc_type
@@ -125,6 +175,15 @@ class TestAccounts:
         # Cleanup
         for acc_id in created_ids:
             requests.delete(f"{BASE_URL}/accounts/{acc_id}", headers=auth_headers)
+
+    def test_paylater_requires_limit(self, auth_headers):
+        """Test paylater accounts are rejected without credit_limit and due_day"""
+        response = requests.post(f"{BASE_URL}/accounts", headers=auth_headers, json={
+            "name": f"TEST_paylater_{uuid.uuid4().hex[:8]}",
+            "type": "paylater",
+            "currency": "IDR"
+        })
+        assert response.status_code == 400
             
     def test_create_sub_account_pocket(self, auth_headers):
         """Test creating sub-account (pocket) under parent account"""
@@ -230,6 +289,575 @@ class TestBudgets:
                     requests.delete(f"{BASE_URL}/budgets/{budget['id']}", headers=auth_headers)
 
 
+class TestBudgetProgress:
+    """Budget vs. actual progress tests"""
+
+    def test_progress_compares_budget_with_spending(self, auth_headers, bank_account):
+        """Test spent, remaining, burn rate and unbudgeted categories of a past month"""
+        category = f"TEST_Progress_{uuid.uuid4().hex[:8]}"
+        unbudgeted = f"TEST_Unbudgeted_{uuid.uuid4().hex[:8]}"
+        budget = requests.post(f"{BASE_URL}/budgets", headers=auth_headers, json={
+            "category": category,
+            "amount": 1000000,
+            "budget_month": 3,
+            "budget_year": 2025
+        })
+        assert budget.status_code == 201
+        budget_id = budget.json()["id"]
+
+        for cat, amount, day in [(category, 250000, "2025-03-10"), (unbudgeted, 100000, "2025-03-11"),
+                                 (category, 999999, "2025-04-01")]:
+            response = requests.post(f"{BASE_URL}/transactions", headers=auth_headers, json={
+                "account_id": bank_account["id"],
+                "type": "expense",
+                "category": cat,
+                "amount": amount,
+                "transaction_date": day
+            })
+            assert response.status_code == 201
+
+        try:
+            response = requests.get(f"{BASE_URL}/budgets/progress?month=3&year=2025", headers=auth_headers)
+            assert response.status_code == 200
+            data = response.json()
+            assert data["days_in_month"] == 31
+            # The whole month has passed
+            assert data["days_elapsed"] == 31
+
+            progress = next(b for b in data["budgets"] if b["category"] == category)
+            assert progress["spent"] == 250000
+            assert progress["remaining"] == 750000
+            assert progress["percent_used"] == 25
+            assert progress["projected_spend"] == pytest.approx(250000)
+
+            extra = next(u for u in data["unbudgeted"] if u["category"] == unbudgeted)
+            assert extra["spent"] == 100000
+        finally:
+            requests.delete(f"{BASE_URL}/budgets/{budget_id}", headers=auth_headers)
+
+    def test_progress_invalid_month(self, auth_headers):
+        """Test an out of range month is rejected"""
+        response = requests.get(f"{BASE_URL}/budgets/progress?month=13&year=2025", headers=auth_headers)
+        assert response.status_code == 400
+
+
+class TestBudgetRollover:
+    """Budget rollover tests - leftovers carry along a chain of monthly budgets"""
+
+    def _budget(self, auth_headers, category, month, **extra):
+        payload = {"category": category, "amount": 100000, "budget_month": month, "budget_year": 2024}
+        payload.update(extra)
+        response = requests.post(f"{BASE_URL}/budgets", headers=auth_headers, json=payload)
+        assert response.status_code == 201
+        return response.json()["id"]
+
+    def _expense(self, auth_headers, account, category, amount, day):
+        response = requests.post(f"{BASE_URL}/transactions", headers=auth_headers, json={
+            "account_id": account["id"],
+            "type": "expense",
+            "category": category,
+            "amount": amount,
+            "transaction_date": day
+        })
+        assert response.status_code == 201
+
+    def _progress(self, auth_headers, category, month):
+        response = requests.get(f"{BASE_URL}/budgets/progress?month={month}&year=2024", headers=auth_headers)
+        assert response.status_code == 200
+        return next(b for b in response.json()["budgets"] if b["category"] == category)
+
+    def test_rollover_chain(self, auth_headers, bank_account):
+        """Test a surplus then a deficit carry through consecutive months"""
+        category = f"TEST_Rollover_{uuid.uuid4().hex[:8]}"
+        ids = [
+            self._budget(auth_headers, category, 1, rollover_mode="surplus"),
+            self._budget(auth_headers, category, 2, rollover_mode="surplus_and_deficit"),
+            self._budget(auth_headers, category, 3),
+        ]
+        self._expense(auth_headers, bank_account, category, 60000, "2024-01-15")
+        self._expense(auth_headers, bank_account, category, 150000, "2024-02-15")
+
+        try:
+            # January leaves 40,000 which February may spend
+            february = self._progress(auth_headers, category, 2)
+            assert february["rollover"] == 40000
+            assert february["available"] == 140000
+
+            # February overspends its 140,000 by 10,000 and carries the deficit
+            march = self._progress(auth_headers, category, 3)
+            assert march["rollover"] == -10000
+            assert march["available"] == 90000
+        finally:
+            for budget_id in ids:
+                requests.delete(f"{BASE_URL}/budgets/{budget_id}", headers=auth_headers)
+
+    def test_rollover_cap_and_gap(self, auth_headers, bank_account):
+        """Test the cap limits the carry and a month without a budget resets it"""
+        capped = f"TEST_RolloverCap_{uuid.uuid4().hex[:8]}"
+        gapped = f"TEST_RolloverGap_{uuid.uuid4().hex[:8]}"
+        ids = [
+            self._budget(auth_headers, capped, 5, rollover_mode="surplus", rollover_cap=25000),
+            self._budget(auth_headers, capped, 6),
+            self._budget(auth_headers, gapped, 5, rollover_mode="surplus"),
+            self._budget(auth_headers, gapped, 7),
+        ]
+
+        try:
+            assert self._progress(auth_headers, capped, 6)["rollover"] == 25000
+            assert self._progress(auth_headers, gapped, 7)["rollover"] == 0
+        finally:
+            for budget_id in ids:
+                requests.delete(f"{BASE_URL}/budgets/{budget_id}", headers=auth_headers)
+
+    def test_invalid_rollover_mode(self, auth_headers):
+        """Test an unknown rollover mode is rejected"""
+        response = requests.post(f"{BASE_URL}/budgets", headers=auth_headers, json={
+            "category": f"TEST_Rollover_{uuid.uuid4().hex[:8]}",
+            "amount": 100000,
+            "budget_month": 1,
+            "budget_year": 2024,
+            "rollover_mode": "forever"
+        })
+        assert response.status_code == 400
+
+
+class TestBudgetAlerts:
+    """Budget threshold alert tests - each threshold notifies once per budget"""
+
+    def _budget_notifications(self, auth_headers, budget_id):
+        response = requests.get(f"{BASE_URL}/notifications?limit=200", headers=auth_headers)
+        assert response.status_code == 200
+        return [n for n in response.json()["notifications"]
+                if n["type"] == "budget_threshold" and n["data"].get("budget_id") == budget_id]
+
+    def test_thresholds_fire_once(self, auth_headers, bank_account):
+        """Test crossing a threshold notifies and crossing it again does not"""
+        category = f"TEST_Alert_{uuid.uuid4().hex[:8]}"
+        budget = requests.post(f"{BASE_URL}/budgets", headers=auth_headers, json={
+            "category": category,
+            "amount": 100000,
+            "budget_month": 9,
+            "budget_year": 2024
+        })
+        assert budget.status_code == 201
+        budget_id = budget.json()["id"]
+
+        try:
+            response = requests.put(f"{BASE_URL}/budgets/{budget_id}/alerts", headers=auth_headers,
+                                    json={"thresholds": [50, 100]})
+            assert response.status_code == 200
+            assert response.json()["alert_thresholds"] == [50, 100]
+
+            for amount in [60000, 10000]:
+                response = requests.post(f"{BASE_URL}/transactions", headers=auth_headers, json={
+                    "account_id": bank_account["id"],
+                    "type": "expense",
+                    "category": category,
+                    "amount": amount,
+                    "transaction_date": "2024-09-05"
+                })
+                assert response.status_code == 201
+
+            notifications = self._budget_notifications(auth_headers, budget_id)
+            assert [n["data"]["threshold"] for n in notifications] == [50]
+
+            # Editing the expense up past the budget fires the 100% threshold
+            expense_id = response.json()["id"]
+            response = requests.put(f"{BASE_URL}/transactions/{expense_id}", headers=auth_headers,
+                                    json={"amount": 50000})
+            assert response.status_code == 200
+
+            notifications = self._budget_notifications(auth_headers, budget_id)
+            assert sorted(n["data"]["threshold"] for n in notifications) == [50, 100]
+
+            response = requests.put(f"{BASE_URL}/notifications/{notifications[0]['id']}/read", headers=auth_headers)
+            assert response.status_code == 200
+            read = next(n for n in self._budget_notifications(auth_headers, budget_id)
+                        if n["id"] == notifications[0]["id"])
+            assert read["is_read"]
+        finally:
+            requests.delete(f"{BASE_URL}/budgets/{budget_id}", headers=auth_headers)
+
+    def test_invalid_threshold(self, auth_headers):
+        """Test thresholds must be positive percentages"""
+        budget = requests.post(f"{BASE_URL}/budgets", headers=auth_headers, json={
+            "category": f"TEST_Alert_{uuid.uuid4().hex[:8]}",
+            "amount": 100000,
+            "budget_month": 9,
+            "budget_year": 2024
+        })
+        assert budget.status_code == 201
+        budget_id = budget.json()["id"]
+        try:
+            response = requests.put(f"{BASE_URL}/budgets/{budget_id}/alerts", headers=auth_headers,
+                                    json={"thresholds": [0]})
+            assert response.status_code == 400
+        finally:
+            requests.delete(f"{BASE_URL}/budgets/{budget_id}", headers=auth_headers)
+
+
+class TestBudgetPlanning:
+    """Budget editing, bulk month upsert and copy report tests"""
+
+    def _month_budgets(self, auth_headers, month, year):
+        response = requests.get(f"{BASE_URL}/budgets?month={month}&year={year}", headers=auth_headers)
+        assert response.status_code == 200
+        return {b["category"]: b for b in response.json() or []}
+
+    def _cleanup(self, auth_headers, month, year, categories):
+        for category, budget in self._month_budgets(auth_headers, month, year).items():
+            if category in categories:
+                requests.delete(f"{BASE_URL}/budgets/{budget['id']}", headers=auth_headers)
+
+    def test_bulk_upsert_and_remove_missing(self, auth_headers):
+        """Test a month's plan is saved in one call and can drop other categories"""
+        keep = f"TEST_BulkKeep_{uuid.uuid4().hex[:8]}"
+        drop = f"TEST_BulkDrop_{uuid.uuid4().hex[:8]}"
+        try:
+            response = requests.put(f"{BASE_URL}/budgets/bulk", headers=auth_headers, json={
+                "budget_month": 2,
+                "budget_year": 2023,
+                "budgets": [
+                    {"category": keep, "amount": 100000},
+                    {"category": drop, "amount": 200000, "rollover_mode": "surplus"}
+                ]
+            })
+            assert response.status_code == 200
+            budgets = self._month_budgets(auth_headers, 2, 2023)
+            assert budgets[keep]["amount"] == 100000
+            assert budgets[drop]["rollover_mode"] == "surplus"
+
+            # Updating in place keeps the budget's id
+            keep_id = budgets[keep]["id"]
+            response = requests.put(f"{BASE_URL}/budgets/bulk", headers=auth_headers, json={
+                "budget_month": 2,
+                "budget_year": 2023,
+                "budgets": [{"category": keep, "amount": 150000}],
+                "remove_missing": True
+            })
+            assert response.status_code == 200
+            budgets = self._month_budgets(auth_headers, 2, 2023)
+            assert budgets[keep]["id"] == keep_id
+            assert budgets[keep]["amount"] == 150000
+            assert drop not in budgets
+        finally:
+            self._cleanup(auth_headers, 2, 2023, {keep, drop})
+
+    def test_bulk_upsert_rejects_bad_plans(self, auth_headers):
+        """Test an empty plan and duplicate categories are rejected"""
+        empty = requests.put(f"{BASE_URL}/budgets/bulk", headers=auth_headers, json={
+            "budget_month": 2,
+            "budget_year": 2023,
+            "budgets": [],
+            "remove_missing": True
+        })
+        assert empty.status_code == 400
+
+        category = f"TEST_BulkDup_{uuid.uuid4().hex[:8]}"
+        duplicate = requests.put(f"{BASE_URL}/budgets/bulk", headers=auth_headers, json={
+            "budget_month": 2,
+            "budget_year": 2023,
+            "budgets": [{"category": category, "amount": 1000}, {"category": category, "amount": 2000}]
+        })
+        assert duplicate.status_code == 400
+
+    def test_update_budget(self, auth_headers):
+        """Test editing a budget's amount and rollover settings"""
+        category = f"TEST_Edit_{uuid.uuid4().hex[:8]}"
+        created = requests.post(f"{BASE_URL}/budgets", headers=auth_headers, json={
+            "category": category,
+            "amount": 100000,
+            "budget_month": 3,
+            "budget_year": 2023
+        })
+        assert created.status_code == 201
+        budget_id = created.json()["id"]
+        try:
+            response = requests.put(f"{BASE_URL}/budgets/{budget_id}", headers=auth_headers, json={
+                "category": category,
+                "amount": 120000,
+                "budget_month": 3,
+                "budget_year": 2023,
+                "rollover_mode": "surplus",
+                "rollover_cap": 50000
+            })
+            assert response.status_code == 200
+            data = response.json()
+            assert data["amount"] == 120000
+            assert data["rollover_mode"] == "surplus"
+            assert data["rollover_cap"] == 50000
+        finally:
+            requests.delete(f"{BASE_URL}/budgets/{budget_id}", headers=auth_headers)
+
+    def test_copy_report_with_overwrite_and_scale(self, auth_headers):
+        """Test copy skips or overwrites existing budgets and scales amounts"""
+        copied = f"TEST_CopyNew_{uuid.uuid4().hex[:8]}"
+        existing = f"TEST_CopyExisting_{uuid.uuid4().hex[:8]}"
+        try:
+            for payload in [
+                {"budget_month": 4, "budgets": [{"category": copied, "amount": 100000},
+                                                {"category": existing, "amount": 200000}]},
+                {"budget_month": 5, "budgets": [{"category": existing, "amount": 1000}]},
+            ]:
+                payload["budget_year"] = 2023
+                assert requests.put(f"{BASE_URL}/budgets/bulk", headers=auth_headers, json=payload).status_code == 200
+
+            request = {"from_month": 4, "from_year": 2023, "to_month": 5, "to_year": 2023, "scale_percent": 10}
+            response = requests.post(f"{BASE_URL}/budgets/copy", headers=auth_headers, json=request)
+            assert response.status_code == 200
+            results = {r["category"]: r for r in response.json()["results"]}
+            assert results[copied]["status"] == "copied"
+            assert results[copied]["amount"] == 110000
+            assert results[existing]["status"] == "skipped"
+            assert self._month_budgets(auth_headers, 5, 2023)[existing]["amount"] == 1000
+
+            request["overwrite"] = True
+            response = requests.post(f"{BASE_URL}/budgets/copy", headers=auth_headers, json=request)
+            assert response.status_code == 200
+            results = {r["category"]: r for r in response.json()["results"]}
+            assert results[existing]["status"] == "overwritten"
+            assert self._month_budgets(auth_headers, 5, 2023)[existing]["amount"] == 220000
+        finally:
+            self._cleanup(auth_headers, 4, 2023, {copied, existing})
+            self._cleanup(auth_headers, 5, 2023, {copied, existing})
+
+
+class TestBudgetSuggestions:
+    """Budget suggestion tests - amounts from past spending"""
+
+    def test_suggestions_from_history(self, auth_headers, bank_account):
+        """Test average, median and trend over a three month window and applying them"""
+        category = f"TEST_Suggest_{uuid.uuid4().hex[:8]}"
+        for amount, day in [(100000, "2022-01-10"), (200000, "2022-02-10"), (600000, "2022-03-10")]:
+            response = requests.post(f"{BASE_URL}/transactions", headers=auth_headers, json={
+                "account_id": bank_account["id"],
+                "type": "expense",
+                "category": category,
+                "amount": amount,
+                "transaction_date": day
+            })
+            assert response.status_code == 201
+
+        response = requests.get(f"{BASE_URL}/budgets/suggestions?month=4&year=2022&months=3", headers=auth_headers)
+        assert response.status_code == 200
+        suggestion = next(s for s in response.json()["suggestions"] if s["category"] == category)
+        assert suggestion["monthly_spending"] == [100000, 200000, 600000]
+        assert suggestion["average"] == 300000
+        assert suggestion["median"] == 200000
+        assert suggestion["suggested_amount"] == 300000
+        assert suggestion["trend"] == "increasing"
+
+        response = requests.post(f"{BASE_URL}/budgets/suggestions/apply", headers=auth_headers, json={
+            "budget_month": 4,
+            "budget_year": 2022,
+            "months": 3,
+            "method": "median",
+            "categories": [category]
+        })
+        assert response.status_code == 200
+        results = response.json()["results"]
+        assert [(r["category"], r["amount"], r["status"]) for r in results] == [(category, 200000, "copied")]
+
+        budgets = requests.get(f"{BASE_URL}/budgets?month=4&year=2022", headers=auth_headers).json()
+        for budget in budgets:
+            if budget["category"] == category:
+                assert budget["amount"] == 200000
+                requests.delete(f"{BASE_URL}/budgets/{budget['id']}", headers=auth_headers)
+
+    def test_suggestions_invalid_method(self, auth_headers):
+        """Test an unknown method is rejected"""
+        response = requests.get(f"{BASE_URL}/budgets/suggestions?method=mode", headers=auth_headers)
+        assert response.status_code == 400
+
+
+class TestBudgetPeriods:
+    """Weekly and yearly budget tests"""
+
+    def _spend(self, auth_headers, account, category, amount, day):
+        response = requests.post(f"{BASE_URL}/transactions", headers=auth_headers, json={
+            "account_id": account["id"],
+            "type": "expense",
+            "category": category,
+            "amount": amount,
+            "transaction_date": day
+        })
+        assert response.status_code == 201
+
+    def test_weekly_budget_progress(self, auth_headers, bank_account):
+        """Test a weekly budget only counts spending of its ISO week"""
+        category = f"TEST_Weekly_{uuid.uuid4().hex[:8]}"
+        created = requests.post(f"{BASE_URL}/budgets", headers=auth_headers, json={
+            "category": category,
+            "amount": 100000,
+            "period": "weekly",
+            "budget_year": 2024,
+            "budget_week": 10
+        })
+        assert created.status_code == 201
+        assert created.json()["period"] == "weekly"
+        budget_id = created.json()["id"]
+
+        # ISO week 10 of 2024 runs from Monday 4 March to Sunday 10 March
+        self._spend(auth_headers, bank_account, category, 30000, "2024-03-06")
+        self._spend(auth_headers, bank_account, category, 50000, "2024-03-11")
+
+        try:
+            response = requests.get(f"{BASE_URL}/budgets/progress?period=weekly&year=2024&week=10", headers=auth_headers)
+            assert response.status_code == 200
+            progress = next(b for b in response.json()["budgets"] if b["category"] == category)
+            assert progress["spent"] == 30000
+        finally:
+            requests.delete(f"{BASE_URL}/budgets/{budget_id}", headers=auth_headers)
+
+    def test_yearly_budget_progress(self, auth_headers, bank_account):
+        """Test a yearly budget counts spending of the whole year"""
+        category = f"TEST_Yearly_{uuid.uuid4().hex[:8]}"
+        created = requests.post(f"{BASE_URL}/budgets", headers=auth_headers, json={
+            "category": category,
+            "amount": 1000000,
+            "period": "yearly",
+            "budget_year": 2021
+        })
+        assert created.status_code == 201
+        budget_id = created.json()["id"]
+
+        self._spend(auth_headers, bank_account, category, 100000, "2021-02-01")
+        self._spend(auth_headers, bank_account, category, 200000, "2021-11-30")
+
+        try:
+            response = requests.get(f"{BASE_URL}/budgets/progress?period=yearly&year=2021", headers=auth_headers)
+            assert response.status_code == 200
+            progress = next(b for b in response.json()["budgets"] if b["category"] == category)
+            assert progress["spent"] == 300000
+            assert progress["remaining"] == 700000
+        finally:
+            requests.delete(f"{BASE_URL}/budgets/{budget_id}", headers=auth_headers)
+
+    def test_invalid_period_fields(self, auth_headers):
+        """Test weekly budgets need a real ISO week and monthly ones a month"""
+        base = {"category": f"TEST_Period_{uuid.uuid4().hex[:8]}", "amount": 1000, "budget_year": 2024}
+        weekly = requests.post(f"{BASE_URL}/budgets", headers=auth_headers,
+                               json={**base, "period": "weekly", "budget_week": 53})
+        assert weekly.status_code == 400
+        monthly = requests.post(f"{BASE_URL}/budgets", headers=auth_headers, json={**base, "period": "monthly"})
+        assert monthly.status_code == 400
+        progress = requests.get(f"{BASE_URL}/budgets/progress?period=daily", headers=auth_headers)
+        assert progress.status_code == 400
+
+
+@pytest.fixture
+def zero_based_mode(auth_headers):
+    """Switch the test user to zero-based budgeting and restore the settings afterwards"""
+    me = requests.get(f"{BASE_URL}/auth/me", headers=auth_headers).json()
+    response = requests.put(f"{BASE_URL}/auth/me/settings", headers=auth_headers,
+                            json={"budgeting_mode": "zero_based", "month_start_day": 1})
+    assert response.status_code == 200
+    yield
+    requests.put(f"{BASE_URL}/auth/me/settings", headers=auth_headers, json={
+        "budgeting_mode": me.get("budgeting_mode") or "standard",
+        "month_start_day": me.get("month_start_day") or 1
+    })
+
+
+class TestZeroBasedBudgeting:
+    """Zero-based (envelope) budgeting tests"""
+
+    def _pocket(self, auth_headers, parent):
+        response = requests.post(f"{BASE_URL}/accounts", headers=auth_headers, json={
+            "name": f"TEST_Envelope_{uuid.uuid4().hex[:8]}",
+            "type": "bank",
+            "currency": "IDR",
+            "parent_account_id": parent["id"]
+        })
+        assert response.status_code == 201
+        return response.json()
+
+    def _envelopes(self, auth_headers):
+        response = requests.get(f"{BASE_URL}/budgets/zero-based?month=8&year=2023", headers=auth_headers)
+        assert response.status_code == 200
+        return response.json()
+
+    def _cleanup(self, auth_headers, categories):
+        budgets = requests.get(f"{BASE_URL}/budgets?month=8&year=2023", headers=auth_headers).json() or []
+        for budget in budgets:
+            if budget["category"] in categories:
+                requests.delete(f"{BASE_URL}/budgets/{budget['id']}", headers=auth_headers)
+
+    def test_requires_zero_based_mode(self, auth_headers):
+        """Test the envelope endpoints refuse users in standard mode"""
+        me = requests.get(f"{BASE_URL}/auth/me", headers=auth_headers).json()
+        if me.get("budgeting_mode") == "zero_based":
+            pytest.skip("Test user is in zero-based mode")
+        response = requests.get(f"{BASE_URL}/budgets/zero-based", headers=auth_headers)
+        assert response.status_code == 400
+
+    def test_assign_moves_income_into_envelope(self, auth_headers, bank_account, zero_based_mode):
+        """Test assigning income funds the envelope pocket and lowers to be assigned"""
+        pocket = self._pocket(auth_headers, bank_account)
+        category = f"TEST_Envelope_{uuid.uuid4().hex[:8]}"
+        income = requests.post(f"{BASE_URL}/transactions", headers=auth_headers, json={
+            "account_id": bank_account["id"],
+            "type": "income",
+            "category": "Salary",
+            "amount": 1000000,
+            "transaction_date": "2023-08-05"
+        })
+        assert income.status_code == 201
+
+        try:
+            before = self._envelopes(auth_headers)["to_be_assigned"]
+            response = requests.post(f"{BASE_URL}/budgets/assign", headers=auth_headers, json={
+                "category": category,
+                "amount": 300000,
+                "budget_month": 8,
+                "budget_year": 2023,
+                "envelope_account_id": pocket["id"],
+                "move_funds": True
+            })
+            assert response.status_code == 200
+            assert response.json()["to_be_assigned"] == before - 300000
+
+            envelope = next(e for e in self._envelopes(auth_headers)["envelopes"] if e["category"] == category)
+            assert envelope["assigned"] == 300000
+            assert envelope["envelope_balance"] == 300000
+
+            parent = requests.get(f"{BASE_URL}/accounts/{bank_account['id']}", headers=auth_headers).json()
+            assert parent["balance"] == 700000
+
+            # Taking back more than the envelope holds is refused
+            response = requests.post(f"{BASE_URL}/budgets/assign", headers=auth_headers, json={
+                "category": category,
+                "amount": -500000,
+                "budget_month": 8,
+                "budget_year": 2023
+            })
+            assert response.status_code == 400
+        finally:
+            self._cleanup(auth_headers, {category})
+
+    def test_bulk_upsert_sets_envelope(self, auth_headers, bank_account, zero_based_mode):
+        """Test a month's plan can link envelopes and keeps them when omitted"""
+        pocket = self._pocket(auth_headers, bank_account)
+        category = f"TEST_BulkEnvelope_{uuid.uuid4().hex[:8]}"
+        plan = {"budget_month": 8, "budget_year": 2023,
+                "budgets": [{"category": category, "amount": 50000, "envelope_account_id": pocket["id"]}]}
+
+        try:
+            assert requests.put(f"{BASE_URL}/budgets/bulk", headers=auth_headers, json=plan).status_code == 200
+            plan["budgets"] = [{"category": category, "amount": 60000}]
+            assert requests.put(f"{BASE_URL}/budgets/bulk", headers=auth_headers, json=plan).status_code == 200
+
+            envelope = next(e for e in self._envelopes(auth_headers)["envelopes"] if e["category"] == category)
+            assert envelope["assigned"] == 60000
+            assert envelope["envelope_account_id"] == pocket["id"]
+
+            # Only pockets can back an envelope
+            plan["budgets"] = [{"category": category, "amount": 60000, "envelope_account_id": bank_account["id"]}]
+            assert requests.put(f"{BASE_URL}/budgets/bulk", headers=auth_headers, json=plan).status_code == 400
+        finally:
+            self._cleanup(auth_headers, {category})
+
+
 class TestGoldAssets:
     """Gold asset CRUD tests"""
     
@@ -304,6 +932,340 @@ class TestGoldAssets:
         assert "current_price_per_gram" in data
         # Verify current price matches seeded value
         assert data["current_price_per_gram"] == 1450000
+        # Same meaning as /gold/price: the antam buy price, buyback alongside
+        price = requests.get(f"{BASE_URL}/gold/price", params={"gold_type": "antam"}).json()
+        assert data["current_price_per_gram"] == price["price_per_gram"]
+        assert data["current_buyback_price_per_gram"] == price["buyback_price_per_gram"]
+
+
+def gold_lot(auth_headers, gold_type, weight, price, purchase_date):
+    """Create a gold lot and return it"""
+    response = requests.post(f"{BASE_URL}/gold/assets", headers=auth_headers, json={
+        "name": f"TEST_Lot_{uuid.uuid4().hex[:8]}",
+        "gold_type": gold_type,
+        "weight_gram": weight,
+        "purchase_price_per_gram": price,
+        "purchase_date": purchase_date
+    })
+    assert response.status_code == 201
+    return response.json()
+
+
+class TestGoldAssetEditing:
+    """Gold lot editing and per-brand holdings tests"""
+
+    def test_update_asset(self, auth_headers):
+        """Test omitted fields are kept and weight changes move the remaining weight"""
+        lot = gold_lot(auth_headers, "antam", 2, 1000000, "2025-01-10")
+        try:
+            response = requests.put(f"{BASE_URL}/gold/assets/{lot['id']}", headers=auth_headers, json={
+                "name": "TEST renamed", "weight_gram": 3
+            })
+            assert response.status_code == 200
+            data = response.json()
+            assert data["name"] == "TEST renamed"
+            assert data["weight_gram"] == 3
+            assert data["remaining_weight_gram"] == 3
+            assert data["purchase_price_per_gram"] == 1000000
+        finally:
+            requests.delete(f"{BASE_URL}/gold/assets/{lot['id']}", headers=auth_headers)
+
+    def test_update_conflicting_with_sales(self, auth_headers):
+        """Test a lot cannot be edited below what was sold or bought after its sale"""
+        lot = gold_lot(auth_headers, "antam", 2, 1000000, "2025-01-10")
+        try:
+            sale = requests.post(f"{BASE_URL}/gold/sales", headers=auth_headers, json={
+                "method": "specific",
+                "lots": [{"asset_id": lot["id"], "weight_gram": 1}],
+                "price_per_gram": 1200000,
+                "sale_date": "2025-06-01"
+            })
+            assert sale.status_code == 201
+
+            url = f"{BASE_URL}/gold/assets/{lot['id']}"
+            assert requests.put(url, headers=auth_headers, json={"weight_gram": 0.5}).status_code == 400
+            assert requests.put(url, headers=auth_headers, json={"purchase_date": "2025-07-01"}).status_code == 400
+
+            response = requests.put(url, headers=auth_headers, json={"weight_gram": 3})
+            assert response.status_code == 200
+            assert response.json()["remaining_weight_gram"] == 2
+        finally:
+            requests.delete(f"{BASE_URL}/gold/assets/{lot['id']}", headers=auth_headers)
+
+    def test_holdings(self, auth_headers):
+        """Test holdings add up a brand's lots with average cost and break-even"""
+        def holding():
+            response = requests.get(f"{BASE_URL}/gold/holdings", headers=auth_headers, params={"sell_fee_percent": 2})
+            assert response.status_code == 200
+            return next((h for h in response.json() if h["gold_type"] == "other"), None)
+
+        before = holding()
+        lots = [
+            gold_lot(auth_headers, "other", 1, 1000000, "2025-01-10"),
+            gold_lot(auth_headers, "other", 3, 1200000, "2025-02-10"),
+        ]
+        try:
+            after = holding()
+            assert after is not None
+            if before is None:
+                assert after["lot_count"] == 2
+                assert after["weight_gram"] == 4
+                assert after["cost_basis"] == 4600000
+                assert after["average_price_per_gram"] == 1150000
+                assert after["break_even_price_per_gram"] == pytest.approx(1150000 / 0.98, abs=0.01)
+            else:
+                assert after["lot_count"] == before["lot_count"] + 2
+                assert after["weight_gram"] == pytest.approx(before["weight_gram"] + 4)
+                assert after["cost_basis"] == pytest.approx(before["cost_basis"] + 4600000)
+        finally:
+            for lot in lots:
+                requests.delete(f"{BASE_URL}/gold/assets/{lot['id']}", headers=auth_headers)
+
+
+@pytest.fixture(scope="module")
+def admin_headers():
+    """Headers of an admin user; skips when ADMIN_EMAIL is not an admin"""
+    response = requests.post(f"{BASE_URL}/auth/login", json={
+        "email": ADMIN_EMAIL,
+        "password": ADMIN_PASSWORD
+    })
+    if response.status_code != 200 or not response.json()["user"].get("is_admin"):
+        pytest.skip("No admin user - set ADMIN_EMAIL and ADMIN_PASSWORD")
+    return {"Authorization": f"Bearer {response.json()['token']}"}
+
+
+class TestGoldPriceAdmin:
+    """Admin gold price writes, CSV backfill and the audit trail"""
+
+    # Far in the past so the quotes never become anyone's latest price
+    DAY = "2001-01-02"
+
+    def _set(self, admin_headers, buy, buyback, day=DAY):
+        return requests.post(f"{BASE_URL}/gold/price", headers=admin_headers, json={
+            "date": day, "gold_type": "other", "buy_price_per_gram": buy, "buyback_price_per_gram": buyback
+        })
+
+    def _delete(self, admin_headers, day):
+        return requests.delete(f"{BASE_URL}/gold/price/{day}/other", headers=admin_headers)
+
+    def test_writes_require_admin(self):
+        """Test a regular user cannot write prices"""
+        email = f"test_{uuid.uuid4().hex[:8]}@example.com"
+        requests.post(f"{BASE_URL}/auth/register", json={"email": email, "password": "password123", "full_name": "TEST User"})
+        login = requests.post(f"{BASE_URL}/auth/login", json={"email": email, "password": "password123"})
+        assert login.status_code == 200
+        headers = {"Authorization": f"Bearer {login.json()['token']}"}
+
+        assert self._set(headers, 500000, 480000).status_code == 403
+        assert self._delete(headers, self.DAY).status_code == 403
+        assert requests.get(f"{BASE_URL}/gold/price/changes", headers=headers).status_code == 403
+
+    def test_set_correct_and_delete_are_audited(self, admin_headers):
+        """Test every write to a past quote lands in the audit trail"""
+        self._delete(admin_headers, self.DAY)
+        try:
+            created = self._set(admin_headers, 500000, 480000)
+            assert created.status_code == 200
+            assert created.json()["action"] == "create"
+
+            updated = self._set(admin_headers, 510000, 480000)
+            assert updated.json()["action"] == "update"
+            assert self._set(admin_headers, 510000, 480000).json()["action"] == ""
+
+            assert self._set(admin_headers, 500000, 520000).status_code == 400
+            tomorrow = (date.today() + timedelta(days=1)).isoformat()
+            assert self._set(admin_headers, 500000, 480000, day=tomorrow).status_code == 400
+        finally:
+            assert self._delete(admin_headers, self.DAY).status_code == 200
+        assert self._delete(admin_headers, self.DAY).status_code == 404
+
+        response = requests.get(f"{BASE_URL}/gold/price/changes", headers=admin_headers,
+                                params={"gold_type": "other", "date": self.DAY})
+        assert response.status_code == 200
+        changes = response.json()
+        assert [c["action"] for c in changes[:3]] == ["delete", "update", "create"]
+        assert changes[1]["old_buy_price_per_gram"] == 500000
+        assert changes[1]["new_buy_price_per_gram"] == 510000
+
+    def test_import_csv(self, admin_headers):
+        """Test a CSV backfill is all or nothing and re-importing it changes nothing"""
+        days = ["2001-01-03", "2001-01-04"]
+        csv = "date,gold_type,buy_price_per_gram,buyback_price_per_gram\n" + "".join(
+            f"{day},other,500000,480000\n" for day in days)
+        for day in days:
+            self._delete(admin_headers, day)
+        try:
+            invalid = requests.post(f"{BASE_URL}/gold/price/import", headers=admin_headers,
+                                    data=csv + "2001-01-05,bogus,1,1\n")
+            assert invalid.status_code == 400
+            assert [e["line"] for e in invalid.json()["errors"]] == [4]
+            assert self._delete(admin_headers, days[0]).status_code == 404
+
+            response = requests.post(f"{BASE_URL}/gold/price/import", headers=admin_headers, data=csv)
+            assert response.status_code == 200
+            assert response.json()["created"] == 2
+
+            again = requests.post(f"{BASE_URL}/gold/price/import", headers=admin_headers, data=csv)
+            assert again.json()["unchanged"] == 2
+        finally:
+            for day in days:
+                self._delete(admin_headers, day)
+
+
+def wait_for(condition, timeout=10):
+    """Poll condition until it returns a truthy value; alerts are evaluated in the background"""
+    deadline = time.time() + timeout
+    while True:
+        result = condition()
+        if result or time.time() > deadline:
+            return result
+        time.sleep(0.2)
+
+
+class TestGoldPriceAlerts:
+    """Gold price alerts fired in the background when a quote crosses them"""
+
+    def test_price_alert_requires_brand(self, auth_headers):
+        """Test price level alerts must name a brand"""
+        response = requests.post(f"{BASE_URL}/gold/alerts", headers=auth_headers, json={
+            "alert_type": "price_above", "threshold": 2000000
+        })
+        assert response.status_code == 400
+
+    def test_price_above_fires_and_rearms(self, admin_headers):
+        """Test an alert fires once when today's quote crosses it and re-arms when it falls back"""
+        today = date.today().isoformat()
+        before = requests.get(f"{BASE_URL}/gold/price", params={"gold_type": "other"})
+        previous = None
+        if before.status_code == 200 and before.json()["gold_type"] == "other" and before.json()["price_date"][:10] == today:
+            previous = before.json()
+
+        threshold = 90000000
+        alert = requests.post(f"{BASE_URL}/gold/alerts", headers=admin_headers, json={
+            "alert_type": "price_above", "gold_type": "other", "price_kind": "buy", "threshold": threshold
+        })
+        assert alert.status_code == 201
+        alert = alert.json()
+
+        def set_price(buy):
+            response = requests.post(f"{BASE_URL}/gold/price", headers=admin_headers, json={
+                "date": today, "gold_type": "other", "buy_price_per_gram": buy, "buyback_price_per_gram": 1000000
+            })
+            assert response.status_code == 200
+
+        def alert_state():
+            alerts = requests.get(f"{BASE_URL}/gold/alerts", headers=admin_headers).json()
+            return next(a for a in alerts if a["id"] == alert["id"])["is_triggered"]
+
+        def alert_notifications():
+            notifications = requests.get(f"{BASE_URL}/notifications", headers=admin_headers).json()["notifications"]
+            return [n for n in notifications if (n.get("data") or {}).get("alert_id") == alert["id"]]
+
+        try:
+            set_price(threshold + 1)
+            assert wait_for(alert_notifications)
+            assert wait_for(alert_state)
+
+            set_price(threshold - 1)
+            assert wait_for(lambda: not alert_state())
+            assert len(alert_notifications()) == 1
+        finally:
+            requests.delete(f"{BASE_URL}/gold/alerts/{alert['id']}", headers=admin_headers)
+            if previous:
+                requests.post(f"{BASE_URL}/gold/price", headers=admin_headers, json={
+                    "date": today, "gold_type": "other",
+                    "buy_price_per_gram": previous["buy_price_per_gram"],
+                    "buyback_price_per_gram": previous["buyback_price_per_gram"]
+                })
+            else:
+                requests.delete(f"{BASE_URL}/gold/price/{today}/other", headers=admin_headers)
+
+
+class TestGoldPriceStats:
+    """Gold price OHLC bars, moving averages and range statistics"""
+
+    # Far in the past so the quotes never become anyone's latest price
+    QUOTES = {"2002-02-04": 1000, "2002-02-05": 1200, "2002-02-06": 900, "2002-02-07": 1100, "2002-02-11": 1300}
+
+    @pytest.fixture(scope="class")
+    def quotes(self, admin_headers):
+        for day, buy in self.QUOTES.items():
+            response = requests.post(f"{BASE_URL}/gold/price", headers=admin_headers, json={
+                "date": day, "gold_type": "other", "buy_price_per_gram": buy, "buyback_price_per_gram": buy - 100
+            })
+            assert response.status_code == 200
+        yield
+        for day in self.QUOTES:
+            requests.delete(f"{BASE_URL}/gold/price/{day}/other", headers=admin_headers)
+
+    def test_weekly_ohlc(self, quotes):
+        """Test quotes are grouped into weekly bars starting on Monday"""
+        response = requests.get(f"{BASE_URL}/gold/price/ohlc", params={
+            "gold_type": "other", "interval": "weekly", "from": "2002-02-04", "to": "2002-02-11"
+        })
+        assert response.status_code == 200
+        bars = response.json()["bars"]
+        assert len(bars) == 2
+        first, second = bars
+        assert first["period_start"][:10] == "2002-02-04"
+        assert (first["open"], first["high"], first["low"], first["close"], first["quotes"]) == (1000, 1200, 900, 1100, 4)
+        assert second["open"] == second["close"] == 1300
+
+    def test_buyback_ohlc(self, quotes):
+        """Test bars can be built from the buyback price"""
+        response = requests.get(f"{BASE_URL}/gold/price/ohlc", params={
+            "gold_type": "other", "price_kind": "buyback", "from": "2002-02-01", "to": "2002-02-28"
+        })
+        assert response.status_code == 200
+        bar = response.json()["bars"][0]
+        assert (bar["open"], bar["high"], bar["low"], bar["close"]) == (900, 1200, 800, 1200)
+
+    def test_moving_averages(self, quotes):
+        """Test the 7-day average covers the quotes of the last week"""
+        response = requests.get(f"{BASE_URL}/gold/price/moving-averages", params={
+            "gold_type": "other", "from": "2002-02-04", "to": "2002-02-11"
+        })
+        assert response.status_code == 200
+        points = response.json()["points"]
+        assert [p["price_date"][:10] for p in points] == list(self.QUOTES)
+        assert points[-1]["ma_7"] == 1125
+        assert all("ma_30" in p and "ma_90" in p for p in points)
+
+    def test_stats(self, quotes):
+        """Test the latest quote on a day with its changes and 52-week range"""
+        response = requests.get(f"{BASE_URL}/gold/price/stats", params={"gold_type": "other", "date": "2002-02-12"})
+        assert response.status_code == 200
+        stats = response.json()
+        assert stats["price_date"][:10] == "2002-02-11"
+        assert stats["price"] == 1300
+        assert (stats["high_52_week"], stats["low_52_week"]) == (1300, 900)
+        assert stats["low_52_week_date"][:10] == "2002-02-06"
+
+        changes = {c["range"]: c for c in stats["changes"]}
+        assert changes["1d"]["from_price"] == 1100
+        assert changes["1d"]["change"] == 200
+        assert changes["7d"]["from_price"] == 1000
+        assert changes["7d"]["change_percent"] == 30
+
+    def test_stats_custom_range(self, quotes):
+        """Test ?from adds a custom range"""
+        response = requests.get(f"{BASE_URL}/gold/price/stats", params={
+            "gold_type": "other", "date": "2002-02-11", "from": "2002-02-06"
+        })
+        assert response.status_code == 200
+        custom = next(c for c in response.json()["changes"] if c["range"] == "custom")
+        assert custom["from_price"] == 900
+        assert custom["change"] == 400
+
+    def test_invalid_requests(self):
+        """Test bad brands, price kinds, intervals and ranges are rejected"""
+        assert requests.get(f"{BASE_URL}/gold/price/stats", params={"gold_type": "bogus"}).status_code == 400
+        assert requests.get(f"{BASE_URL}/gold/price/ohlc", params={"price_kind": "sell"}).status_code == 400
+        assert requests.get(f"{BASE_URL}/gold/price/ohlc", params={"interval": "daily"}).status_code == 400
+        assert requests.get(f"{BASE_URL}/gold/price/ohlc", params={"from": "2002-03-01", "to": "2002-02-01"}).status_code == 400
+        assert requests.get(f"{BASE_URL}/gold/price/moving-averages", params={
+            "from": "1990-01-01", "to": "2002-01-01"
+        }).status_code == 400
 
 
 class TestCreditCards:
@@ -337,5 +1299,384 @@ class TestCreditCards:
             requests.delete(f"{BASE_URL}/credit-cards/{data['id']}", headers=auth_headers)
 
 
+@pytest.fixture
+def credit_card(auth_headers):
+    """A throwaway credit card with a 10,000,000 limit; its transactions are deleted with it"""
+    response = requests.post(f"{BASE_URL}/credit-cards", headers=auth_headers, json={
+        "card_name": f"TEST_CC_{uuid.uuid4().hex[:8]}",
+        "last_four_digits": "4321",
+        "credit_limit": 10000000,
+        "current_balance": 0,
+        "billing_date": 15,
+        "payment_due_date": 25
+    })
+    assert response.status_code == 201
+    card = response.json()
+    yield card
+    requests.delete(f"{BASE_URL}/credit-cards/{card['id']}", headers=auth_headers)
+
+
+def card_transaction(auth_headers, card, tx_type, amount, day, category=None):
+    """Book a card transaction and return the response"""
+    payload = {"type": tx_type, "amount": amount, "transaction_date": day}
+    if category:
+        payload["category"] = category
+    return requests.post(f"{BASE_URL}/credit-cards/{card['id']}/transactions", headers=auth_headers, json=payload)
+
+
+class TestCreditCardTransactions:
+    """Credit card charges, refunds, fees and payments against the card balance"""
+
+    def _card(self, auth_headers, card):
+        response = requests.get(f"{BASE_URL}/credit-cards/{card['id']}", headers=auth_headers)
+        assert response.status_code == 200
+        return response.json()
+
+    def test_charges_move_the_balance(self, auth_headers, credit_card):
+        """Test purchases and fees raise the balance and refunds lower it"""
+        assert card_transaction(auth_headers, credit_card, "purchase", 500000, "2022-06-10", "Shopping").status_code == 201
+        assert card_transaction(auth_headers, credit_card, "refund", 100000, "2022-06-12", "Shopping").status_code == 201
+        fee = card_transaction(auth_headers, credit_card, "fee", 10000, "2022-06-15")
+        assert fee.status_code == 201
+        assert fee.json()["category"] == "Credit Card Fees"
+
+        card = self._card(auth_headers, credit_card)
+        assert card["current_balance"] == 410000
+        assert card["available_credit"] == 9590000
+
+        over_limit = card_transaction(auth_headers, credit_card, "purchase", 9600000, "2022-06-16", "Shopping")
+        assert over_limit.status_code == 400
+
+    def test_pay_from_bank_account(self, auth_headers, credit_card, bank_account):
+        """Test a payment lowers both balances and links a transfer"""
+        assert card_transaction(auth_headers, credit_card, "purchase", 300000, "2022-06-10", "Shopping").status_code == 201
+
+        response = requests.post(f"{BASE_URL}/credit-cards/{credit_card['id']}/payments", headers=auth_headers, json={
+            "account_id": bank_account["id"],
+            "amount": 200000,
+            "transaction_date": "2022-06-20"
+        })
+        assert response.status_code == 201
+        data = response.json()
+        assert data["credit_card"]["current_balance"] == 100000
+        assert data["transfer"]["type"] == "transfer"
+        assert data["payment"]["transaction_id"] == data["transfer"]["id"]
+
+        account = requests.get(f"{BASE_URL}/accounts/{bank_account['id']}", headers=auth_headers).json()
+        assert account["balance"] == -200000
+
+    def test_overpayment_rejected(self, auth_headers, credit_card, bank_account):
+        """Test a payment above the card balance is refused and changes nothing"""
+        assert card_transaction(auth_headers, credit_card, "purchase", 100000, "2022-06-10", "Shopping").status_code == 201
+
+        response = requests.post(f"{BASE_URL}/credit-cards/{credit_card['id']}/payments", headers=auth_headers, json={
+            "account_id": bank_account["id"],
+            "amount": 100001
+        })
+        assert response.status_code == 400
+        assert self._card(auth_headers, credit_card)["current_balance"] == 100000
+
+    def test_card_spending_counts_towards_budgets(self, auth_headers, credit_card, bank_account):
+        """Test card purchases count as spending, refunds reduce it and payments do not count"""
+        category = f"TEST_CardSpend_{uuid.uuid4().hex[:8]}"
+        budget = requests.post(f"{BASE_URL}/budgets", headers=auth_headers, json={
+            "category": category,
+            "amount": 1000000,
+            "budget_month": 7,
+            "budget_year": 2022
+        })
+        assert budget.status_code == 201
+        budget_id = budget.json()["id"]
+
+        assert card_transaction(auth_headers, credit_card, "purchase", 400000, "2022-07-05", category).status_code == 201
+        assert card_transaction(auth_headers, credit_card, "refund", 150000, "2022-07-06", category).status_code == 201
+        payment = requests.post(f"{BASE_URL}/credit-cards/{credit_card['id']}/payments", headers=auth_headers, json={
+            "account_id": bank_account["id"],
+            "amount": 250000,
+            "transaction_date": "2022-07-20"
+        })
+        assert payment.status_code == 201
+
+        try:
+            response = requests.get(f"{BASE_URL}/budgets/progress?month=7&year=2022", headers=auth_headers)
+            assert response.status_code == 200
+            progress = next(b for b in response.json()["budgets"] if b["category"] == category)
+            assert progress["spent"] == 250000
+        finally:
+            requests.delete(f"{BASE_URL}/budgets/{budget_id}", headers=auth_headers)
+
+    def test_update_card_details(self, auth_headers, credit_card):
+        """Test editing card details keeps the balance"""
+        response = requests.put(f"{BASE_URL}/credit-cards/{credit_card['id']}", headers=auth_headers, json={
+            "card_name": credit_card["card_name"],
+            "credit_limit": 20000000,
+            "billing_date": 5,
+            "payment_due_date": 20
+        })
+        assert response.status_code == 200
+        data = response.json()
+        assert data["credit_limit"] == 20000000
+        assert data["billing_date"] == 5
+        assert data["current_balance"] == 0
+
+
+class TestCreditCardInstallments:
+    """Credit card installment plan (cicilan) tests"""
+
+    def test_plan_books_first_installment(self, auth_headers, credit_card):
+        """Test a plan starting in the open statement bills its first installment and admin fee at once"""
+        response = requests.post(f"{BASE_URL}/credit-cards/{credit_card['id']}/installments", headers=auth_headers, json={
+            "description": "TEST laptop",
+            "category": "Electronics",
+            "principal": 1200000,
+            "tenor_months": 12,
+            "interest_rate_monthly": 1,
+            "admin_fee": 50000
+        })
+        assert response.status_code == 201
+        plan = response.json()
+        assert plan["monthly_amount"] == 112000
+        assert plan["total_cost"] == 1200000 + 12 * 12000 + 50000
+        assert plan["installments_posted"] == 1
+        assert plan["outstanding_principal"] == 1100000
+
+        card = requests.get(f"{BASE_URL}/credit-cards/{credit_card['id']}", headers=auth_headers).json()
+        assert card["current_balance"] == 162000
+        assert card["installment_balance"] == 1100000
+        assert card["available_credit"] == 10000000 - 162000 - 1100000
+
+        transactions = requests.get(f"{BASE_URL}/credit-cards/{credit_card['id']}/transactions", headers=auth_headers).json()
+        postings = sorted(t["amount"] for t in transactions if t["type"] == "installment")
+        assert postings == [50000, 112000]
+
+        plans = requests.get(f"{BASE_URL}/credit-cards/{credit_card['id']}/installments", headers=auth_headers).json()
+        assert [p["id"] for p in plans] == [plan["id"]]
+
+    def test_plan_above_available_credit(self, auth_headers, credit_card):
+        """Test a plan cannot exceed the available credit"""
+        response = requests.post(f"{BASE_URL}/credit-cards/{credit_card['id']}/installments", headers=auth_headers, json={
+            "description": "TEST car",
+            "category": "Vehicles",
+            "principal": 10000001,
+            "tenor_months": 12
+        })
+        assert response.status_code == 400
+
+
+class TestCreditCardAnalysis:
+    """Credit card utilization, interest and late fee analysis tests"""
+
+    def test_unpaid_purchase_analysis(self, auth_headers, credit_card):
+        """Test utilization and interest, and that carried debt is charged one late fee"""
+        # Billed about three statements ago and never paid, so it is carried as overdue since
+        day = (date.today() - timedelta(days=100)).isoformat()
+        assert card_transaction(auth_headers, credit_card, "purchase", 1000000, day, "Shopping").status_code == 201
+
+        response = requests.get(f"{BASE_URL}/credit-cards/{credit_card['id']}/analysis", headers=auth_headers)
+        assert response.status_code == 200
+        analysis = response.json()
+        assert analysis["utilization"] == 10
+        assert analysis["minimum_payment"] == 50000
+        assert analysis["next_month_interest"] == pytest.approx(16625)
+        assert analysis["overdue_statements"] >= 2
+        # 1% of the 1,000,000 that first went unpaid, not once per statement carrying it
+        assert analysis["late_fees"] == 10000
+
+        report = requests.get(f"{BASE_URL}/credit-cards/analysis", headers=auth_headers)
+        assert report.status_code == 200
+        card = next(c for c in report.json()["cards"] if c["credit_card_id"] == credit_card["id"])
+        assert card["late_fees"] == 10000
+
+
+class TestCreditCardRewards:
+    """Credit card reward rules, earnings and redemptions"""
+
+    def _rule(self, auth_headers, card, **rule):
+        response = requests.post(f"{BASE_URL}/credit-cards/{card['id']}/rewards/rules", headers=auth_headers, json=rule)
+        assert response.status_code == 201
+        return response.json()
+
+    def _rewards(self, auth_headers, card):
+        response = requests.get(f"{BASE_URL}/credit-cards/{card['id']}/rewards", headers=auth_headers)
+        assert response.status_code == 200
+        return response.json()
+
+    def _balance(self, summary, reward_type):
+        return next((b["balance"] for b in summary["balances"] if b["reward_type"] == reward_type), 0)
+
+    def _redeem(self, auth_headers, card, amount):
+        return requests.post(f"{BASE_URL}/credit-cards/{card['id']}/rewards/redemptions", headers=auth_headers, json={
+            "reward_type": "cashback", "amount": amount
+        })
+
+    def test_earnings_are_kept_when_rules_change(self, auth_headers, credit_card):
+        """Test charges earn under the rules when booked, capped per cycle, and deleting a rule keeps them"""
+        dining = self._rule(auth_headers, credit_card, category="Dining", reward_type="points",
+                            earn_rate=1, spend_unit=10000, monthly_cap=30)
+        self._rule(auth_headers, credit_card, reward_type="cashback", earn_rate=1, spend_unit=100)
+
+        assert card_transaction(auth_headers, credit_card, "purchase", 250000, "2022-06-10", "Dining").status_code == 201
+        assert card_transaction(auth_headers, credit_card, "purchase", 100000, "2022-06-11", "Dining").status_code == 201
+        assert card_transaction(auth_headers, credit_card, "purchase", 500000, "2022-06-12", "Shopping").status_code == 201
+        assert card_transaction(auth_headers, credit_card, "fee", 10000, "2022-06-13").status_code == 201
+
+        summary = self._rewards(auth_headers, credit_card)
+        earning = next(e for e in summary["earnings"] if e["category"] == "Dining")
+        assert (earning["month"], earning["year"]) == (6, 2022)
+        assert earning["earned"] == 30
+        assert earning["capped"] is True
+        assert self._balance(summary, "points") == 30
+        assert self._balance(summary, "cashback") == 5000
+
+        # A rule added later does not earn on charges booked before it
+        requests.delete(f"{BASE_URL}/credit-cards/{credit_card['id']}/rewards/rules/{dining['id']}", headers=auth_headers)
+        self._rule(auth_headers, credit_card, category="Dining", reward_type="points", earn_rate=5, spend_unit=10000)
+        summary = self._rewards(auth_headers, credit_card)
+        earning = next(e for e in summary["earnings"] if e["category"] == "Dining")
+        assert earning["rule_id"] is None
+        assert self._balance(summary, "points") == 30
+
+    def test_refund_takes_back_rewards(self, auth_headers, credit_card):
+        """Test a refund reverses what its amount earned"""
+        self._rule(auth_headers, credit_card, reward_type="cashback", earn_rate=1, spend_unit=100)
+        assert card_transaction(auth_headers, credit_card, "purchase", 100000, "2022-06-10", "Shopping").status_code == 201
+        assert card_transaction(auth_headers, credit_card, "refund", 40000, "2022-06-12", "Shopping").status_code == 201
+
+        assert self._balance(self._rewards(auth_headers, credit_card), "cashback") == 600
+
+    def test_installments_earn(self, auth_headers, credit_card):
+        """Test installment charges earn rewards and the admin fee does not"""
+        self._rule(auth_headers, credit_card, reward_type="cashback", earn_rate=1, spend_unit=100)
+        response = requests.post(f"{BASE_URL}/credit-cards/{credit_card['id']}/installments", headers=auth_headers, json={
+            "description": "TEST phone",
+            "category": "Electronics",
+            "principal": 1200000,
+            "tenor_months": 12,
+            "admin_fee": 50000
+        })
+        assert response.status_code == 201
+        assert response.json()["installments_posted"] == 1
+
+        assert self._balance(self._rewards(auth_headers, credit_card), "cashback") == 1000
+
+    def test_redemption_above_balance_rejected(self, auth_headers, credit_card):
+        """Test redemptions cannot exceed the earned balance"""
+        self._rule(auth_headers, credit_card, reward_type="cashback", earn_rate=1, spend_unit=100)
+        assert card_transaction(auth_headers, credit_card, "purchase", 100000, "2022-06-10", "Shopping").status_code == 201
+
+        assert self._redeem(auth_headers, credit_card, 1001).status_code == 400
+        assert self._redeem(auth_headers, credit_card, 1000).status_code == 201
+        assert self._redeem(auth_headers, credit_card, 1).status_code == 400
+        assert self._balance(self._rewards(auth_headers, credit_card), "cashback") == 0
+
+    def test_best_card(self, auth_headers, credit_card):
+        """Test the best card estimate for a purchase in a category"""
+        category = f"TEST_CAT_{uuid.uuid4().hex[:8]}"
+        self._rule(auth_headers, credit_card, category=category, reward_type="cashback",
+                   earn_rate=10, spend_unit=100, monthly_cap=5000)
+
+        response = requests.get(f"{BASE_URL}/credit-cards/rewards/best", headers=auth_headers,
+                                params={"category": category, "amount": 100000})
+        assert response.status_code == 200
+        option = next(o for o in response.json()["cards"] if o["credit_card_id"] == credit_card["id"])
+        assert option["return_percent"] == 10
+        assert option["estimated_reward"] == 5000
+        assert option["remaining_cap"] == 5000
+
+
+@pytest.fixture
+def paylater_account(auth_headers):
+    """A throwaway paylater account with a 1,000,000 limit due on the 25th"""
+    response = requests.post(f"{BASE_URL}/accounts", headers=auth_headers, json={
+        "name": f"TEST_Paylater_{uuid.uuid4().hex[:8]}",
+        "type": "paylater",
+        "currency": "IDR",
+        "credit_limit": 1000000,
+        "due_day": 25
+    })
+    assert response.status_code == 201
+    account = response.json()
+    yield account
+    requests.delete(f"{BASE_URL}/accounts/{account['id']}", headers=auth_headers)
+
+
+class TestPaylater:
+    """Paylater purchases, installment schedules and payments"""
+
+    def _purchase(self, auth_headers, account, **overrides):
+        payload = {
+            "description": "TEST headphones",
+            "category": "Electronics",
+            "principal": 300000,
+            "tenor_months": 3,
+            "fee_percent_monthly": 1,
+            "admin_fee": 5000,
+            "purchase_date": "2022-06-10"
+        }
+        payload.update(overrides)
+        return requests.post(f"{BASE_URL}/accounts/{account['id']}/paylater/purchases", headers=auth_headers, json=payload)
+
+    def _account(self, auth_headers, account):
+        response = requests.get(f"{BASE_URL}/accounts/{account['id']}", headers=auth_headers)
+        assert response.status_code == 200
+        return response.json()
+
+    def test_purchase_schedule(self, auth_headers, paylater_account):
+        """Test a purchase is split into monthly installments due on the account's due day"""
+        response = self._purchase(auth_headers, paylater_account)
+        assert response.status_code == 201
+        purchase = response.json()
+        assert purchase["total_fees"] == 14000
+        assert purchase["outstanding"] == 314000
+        assert [i["amount"] for i in purchase["installments"]] == [108000, 103000, 103000]
+        assert [i["due_date"][:10] for i in purchase["installments"]] == ["2022-07-25", "2022-08-25", "2022-09-25"]
+
+        account = self._account(auth_headers, paylater_account)
+        assert account["balance"] == -314000
+        assert account["available_credit"] == 686000
+
+    def test_purchase_above_available_credit(self, auth_headers, paylater_account):
+        """Test a purchase whose principal and fees exceed the limit is rejected"""
+        response = self._purchase(auth_headers, paylater_account, principal=1000000, tenor_months=1, admin_fee=0)
+        assert response.status_code == 400
+
+    def test_pay_installment(self, auth_headers, paylater_account, bank_account):
+        """Test paying an installment from a bank account reduces the debt once"""
+        purchase = self._purchase(auth_headers, paylater_account).json()
+        first = purchase["installments"][0]
+
+        response = requests.post(f"{BASE_URL}/paylater/installments/{first['id']}/pay", headers=auth_headers, json={
+            "account_id": bank_account["id"], "payment_date": "2022-07-20"
+        })
+        assert response.status_code == 201
+        assert response.json()["installment"]["paid_at"]
+        assert self._account(auth_headers, paylater_account)["balance"] == -206000
+        assert self._account(auth_headers, bank_account)["balance"] == -108000
+
+        again = requests.post(f"{BASE_URL}/paylater/installments/{first['id']}/pay", headers=auth_headers, json={
+            "account_id": bank_account["id"]
+        })
+        assert again.status_code == 409
+
+    def test_pay_from_paylater_rejected(self, auth_headers, paylater_account):
+        """Test installments cannot be paid with more paylater debt"""
+        purchase = self._purchase(auth_headers, paylater_account).json()
+        response = requests.post(f"{BASE_URL}/paylater/installments/{purchase['installments'][0]['id']}/pay", headers=auth_headers, json={
+            "account_id": paylater_account["id"]
+        })
+        assert response.status_code == 400
+
+    def test_balance_sheet_lists_paylater_debt(self, auth_headers, paylater_account):
+        """Test the balance sheet carries the paylater debt as a liability"""
+        assert self._purchase(auth_headers, paylater_account).status_code == 201
+        response = requests.get(f"{BASE_URL}/accounts/balance-sheet", headers=auth_headers)
+        assert response.status_code == 200
+        sheet = response.json()
+        item = next(l for l in sheet["liabilities"] if l["id"] == paylater_account["id"])
+        assert item["kind"] == "paylater"
+        assert item["balance"] == 314000
+        assert sheet["paylater_debt"] >= 314000
+
+
 if __name__ == "__main__":
     pytest.main([__file__, "-v", "--tb=short"])
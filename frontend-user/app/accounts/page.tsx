@@ -33,6 +33,8 @@ interface Account {
   balance: number;
   currency: string;
   parent_account_id?: string;
+  credit_limit?: number;
+  due_day?: number;
   sub_accounts?: Account[];
 }
 
@@ -47,6 +49,8 @@ export default function AccountsPage() {
     name: "",
     type: "bank",
     currency: "IDR",
+    credit_limit: "",
+    due_day: "",
   });
   const [subFormData, setSubFormData] = useState({
     name: "",
@@ -84,6 +88,11 @@ export default function AccountsPage() {
           name: formData.name,
           type: formData.type,
           currency: formData.currency,
+          // Paylater accounts are debt and need a limit and a due day
+          ...(formData.type === "paylater" && {
+            credit_limit: parseFloat(formData.credit_limit),
+            due_day: parseInt(formData.due_day),
+          }),
         },
         {
           headers: { Authorization: `Bearer ${token}` },
@@ -91,7 +100,7 @@ export default function AccountsPage() {
       );
 
       setDialogOpen(false);
-      setFormData({ name: "", type: "bank", currency: "IDR" });
+      setFormData({ name: "", type: "bank", currency: "IDR", credit_limit: "", due_day: "" });
       fetchAccounts();
     } catch (err: any) {
       alert(err.response?.data?.error || "Failed to create account");
@@ -373,6 +382,37 @@ export default function AccountsPage() {
                     </SelectContent>
                   </Select>
                 </div>
+                {formData.type === "paylater" && (
+                  <>
+                    <div className="space-y-2">
+                      <Label htmlFor="credit_limit">Credit Limit</Label>
+                      <Input
+                        id="credit_limit"
+                        type="number"
+                        min="1"
+                        placeholder="e.g., 5000000"
+                        value={formData.credit_limit}
+                        onChange={(e) => setFormData({ ...formData, credit_limit: e.target.value })}
+                        required
+                        data-testid="account-credit-limit-input"
+                      />
+                    </div>
+                    <div className="space-y-2">
+                      <Label htmlFor="due_day">Due Day</Label>
+                      <Input
+                        id="due_day"
+                        type="number"
+                        min="1"
+                        max="31"
+                        placeholder="Day of the month, e.g., 25"
+                        value={formData.due_day}
+                        onChange={(e) => setFormData({ ...formData, due_day: e.target.value })}
+                        required
+                        data-testid="account-due-day-input"
+                      />
+                    </div>
+                  </>
+                )}
                 <div className="space-y-2">
                   <Label htmlFor="currency">Currency</Label>
                   <Select